@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/comerc/unused-interface-methods/pkg/cache"
+	"github.com/comerc/unused-interface-methods/pkg/changes"
+	"github.com/comerc/unused-interface-methods/pkg/config"
+	"github.com/comerc/unused-interface-methods/pkg/index"
+	"github.com/comerc/unused-interface-methods/pkg/target"
+)
+
+// runCompare анализирует две ревизии git во временных рабочих деревьях и выводит, как
+// изменилось использование методов интерфейсов. Каждая ревизия анализируется со своей
+// конфигурацией, если не задан -config
+func runCompare(dir, revA, revB string, opts options, factsCache *cache.Cache) int {
+	before, err := indexRevision(dir, revA, opts, factsCache)
+	if err != nil {
+		fmt.Println(err)
+		return exitCode(err)
+	}
+	after, err := indexRevision(dir, revB, opts, factsCache)
+	if err != nil {
+		fmt.Println(err)
+		return exitCode(err)
+	}
+
+	fmt.Printf("Comparing %s..%s\n", revA, revB)
+	index.Compare(before, after).WriteText(os.Stdout)
+	return config.ExitOK
+}
+
+// indexRevision извлекает ревизию rev и строит индекс директории dir в ней
+func indexRevision(dir, rev string, opts options, factsCache *cache.Cache) (*index.Index, error) {
+	path, remove, err := changes.Checkout(dir, rev)
+	if err != nil {
+		return nil, fail(config.ExitConfig, "Error: %v", err)
+	}
+	defer remove()
+
+	tgt, err := target.FromArgs([]string{path})
+	if err != nil {
+		return nil, fail(config.ExitConfig, "Error: %v", err)
+	}
+	opts.allEvidence = true
+	opts.interfaces = true
+	a, err := analyze(tgt, opts, factsCache)
+	if err != nil {
+		return nil, err
+	}
+	if len(a.res.LoadErrors) > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: %s: %d load errors, the comparison may be incomplete\n", rev, len(a.res.LoadErrors))
+	}
+
+	// Пути в индексе отсчитываются от анализируемой директории, как при запуске в ней
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chdir(path); err != nil {
+		return nil, err
+	}
+	defer os.Chdir(wd)
+	return index.Build(a.interfaces, append(a.res.Findings, a.dropped...)), nil
+}
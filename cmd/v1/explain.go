@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/comerc/unused-interface-methods/pkg/cache"
+	"github.com/comerc/unused-interface-methods/pkg/config"
+	"github.com/comerc/unused-interface-methods/pkg/report"
+	"github.com/comerc/unused-interface-methods/pkg/results"
+	"github.com/comerc/unused-interface-methods/pkg/target"
+)
+
+// runExplain анализирует код со сбором всех использований и объясняет вердикт по методу id.
+// Baseline и фильтр изменений не применяются: они не меняют вердикт
+func runExplain(tgt *target.Target, id string, opts options, factsCache *cache.Cache) int {
+	opts.allEvidence = true
+	a, err := analyze(tgt, opts, factsCache)
+	if err != nil {
+		fmt.Println(err)
+		return exitCode(err)
+	}
+	return explain(a, id)
+}
+
+// explain выводит объяснение по методу id из результатов анализа, включая находки,
+// убранные правилами конфигурации
+func explain(a *analysis, id string) int {
+	found := results.Lookup(a.res.Findings, id)
+	if len(found) == 0 {
+		found = results.Lookup(a.dropped, id)
+	}
+	switch len(found) {
+	case 0:
+		fmt.Printf("Error: method %s not found among analyzed interfaces (files matched by ignore patterns are not analyzed)\n", id)
+		return config.ExitConfig
+	case 1:
+	default:
+		fmt.Printf("Error: %s matches several methods, specify the package path:\n", id)
+		for _, f := range found {
+			fmt.Printf("  %s\n", f.ID())
+		}
+		return config.ExitConfig
+	}
+
+	if err := report.Explain(os.Stdout, found[0]); err != nil {
+		fmt.Printf("Error writing report: %v\n", err)
+		return config.ExitInternal
+	}
+	return config.ExitOK
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/comerc/unused-interface-methods/pkg/cache"
+	"github.com/comerc/unused-interface-methods/pkg/config"
+	"github.com/comerc/unused-interface-methods/pkg/index"
+	"github.com/comerc/unused-interface-methods/pkg/target"
+)
+
+// runIndex анализирует код со сбором всех использований и записывает индекс интерфейсов
+// в файл file. Методы, убранные правилами конфигурации, тоже входят в индекс
+func runIndex(tgt *target.Target, file string, opts options, factsCache *cache.Cache) int {
+	opts.allEvidence = true
+	opts.interfaces = true
+	a, err := analyze(tgt, opts, factsCache)
+	if err != nil {
+		fmt.Println(err)
+		return exitCode(err)
+	}
+
+	idx := index.Build(a.interfaces, append(a.res.Findings, a.dropped...))
+	if err := idx.Write(file); err != nil {
+		fmt.Printf("Error writing index: %v\n", err)
+		return config.ExitInternal
+	}
+	methods := 0
+	for _, iface := range idx.Interfaces {
+		methods += len(iface.Methods)
+	}
+	fmt.Fprintf(os.Stderr, "Index written to %s (interfaces: %d, methods: %d)\n", file, len(idx.Interfaces), methods)
+	if len(a.res.LoadErrors) > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: %d load errors, the index may be incomplete\n", len(a.res.LoadErrors))
+	}
+	return config.ExitOK
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/comerc/unused-interface-methods/pkg/cache"
+	"github.com/comerc/unused-interface-methods/pkg/config"
+	"github.com/comerc/unused-interface-methods/pkg/lsp"
+	"github.com/comerc/unused-interface-methods/pkg/results"
+	"github.com/comerc/unused-interface-methods/pkg/target"
+)
+
+// runLSP обслуживает редактор по протоколу LSP через stdin и stdout. Корень анализа
+// передает редактор, root используется, если он его не передал. Возвращает код возврата
+func runLSP(root string, opts options, factsCache *cache.Cache) int {
+	analyze := func(dir string, overlay map[string][]byte) (*results.Result, error) {
+		tgt, err := target.Select([]string{dir}, "", nil)
+		if err != nil {
+			return nil, err
+		}
+		opts := opts
+		opts.overlay = overlay
+		a, err := analyzeNarrowed(tgt, opts, factsCache)
+		if err != nil {
+			return nil, err
+		}
+		return a.res, nil
+	}
+	if err := lsp.Serve(os.Stdin, os.Stdout, lsp.Options{Root: root, Analyze: analyze}); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return config.ExitInternal
+	}
+	return config.ExitOK
+}
@@ -1,66 +1,444 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
+	"go/types"
+	"io"
+	"os"
+	"time"
 
+	"github.com/comerc/unused-interface-methods/pkg/baseline"
+	"github.com/comerc/unused-interface-methods/pkg/cache"
+	"github.com/comerc/unused-interface-methods/pkg/changes"
 	"github.com/comerc/unused-interface-methods/pkg/config"
+	"github.com/comerc/unused-interface-methods/pkg/index"
 	"github.com/comerc/unused-interface-methods/pkg/linter"
+	"github.com/comerc/unused-interface-methods/pkg/postprocess"
+	"github.com/comerc/unused-interface-methods/pkg/report"
+	"github.com/comerc/unused-interface-methods/pkg/results"
+	"github.com/comerc/unused-interface-methods/pkg/rules"
+	"github.com/comerc/unused-interface-methods/pkg/target"
+	"github.com/comerc/unused-interface-methods/pkg/workspace"
 )
 
 func main() {
+	// Подкоманды init, config, cache и query работают с конфигурацией, кэшем и индексом, а не с кодом
+	if code, ok := config.RunCommand(os.Args[1:], os.Stdout, os.Stderr); ok {
+		config.OsExit(code)
+		return
+	}
+	if code, ok := cache.RunCommand(os.Args[1:], os.Stdout, os.Stderr); ok {
+		config.OsExit(code)
+		return
+	}
+	if code, ok := index.RunCommand(os.Args[1:], os.Stdout, os.Stderr); ok {
+		config.OsExit(code)
+		return
+	}
+
+	// watch, lsp, explain, index и compare принимают те же флаги, что и разовый запуск
+	args := os.Args[1:]
+	var mode string
+	if len(args) > 0 && (args[0] == "watch" || args[0] == "lsp" || args[0] == "explain" || args[0] == "index" || args[0] == "compare") {
+		mode, args = args[0], args[1:]
+	}
+
 	var (
-		verbose = flag.Bool("v", false, "Verbose output")
-		help    = flag.Bool("h", false, "Show help")
+		opts     options
+		help     = flag.Bool("h", false, "Show help")
+		format   = flag.String("format", "text", "Output format: text, json, sarif, checkstyle, junit, github")
+		cacheDir = flag.String("cache-dir", "", "Package facts cache directory (default: user cache directory, off disables)")
+		poll     = flag.Duration("poll", 0, "Poll the tree at the interval instead of file system notifications (watch)")
+		output   = flag.String("o", index.DefaultFile, "Index file to write (index)")
+
+		filesFrom = flag.String("files-from", "", "Check interfaces only in Go files listed in the file (- for stdin), one per line or go list -json")
+
+		baselineWrite  = flag.String("baseline-write", "", "Write current unused methods to the baseline file and exit")
+		showSuppressed = flag.Bool("show-suppressed", false, "List unused methods suppressed by directives")
 	)
-	flag.Parse()
+	flag.BoolVar(&opts.verbose, "v", false, "Verbose output")
+	flag.StringVar(&opts.cfgFile, "config", "", "Config file path (disables config file discovery)")
+	flag.StringVar(&opts.tags, "tags", "", "Comma-separated build tags, added to every build-matrix entry")
+	flag.StringVar(&opts.modules, "modules", workspace.ModeWork, "Modules analyzed together: work (go.work), auto (every go.mod under the path), off")
+	flag.StringVar(&opts.baselineFile, "baseline", "", "Report only unused methods missing from the baseline file")
+	flag.StringVar(&opts.newFromRev, "new-from-rev", "", "Report only unused methods introduced since the git revision")
+	flag.StringVar(&opts.newFromPatch, "new-from-patch", "", "Report only unused methods introduced by the unified diff file")
+	flag.CommandLine.Parse(args)
 
 	if *help {
 		fmt.Println("Unused Interface Methods - finds unused interface methods")
 		fmt.Println()
 		fmt.Println("Usage:")
-		fmt.Println("  unused-interface-methods [flags] [path]")
+		fmt.Println("  unused-interface-methods [flags] [path | packages | files]")
+		fmt.Println("  unused-interface-methods watch [flags] [path | packages | files]")
+		fmt.Println("  unused-interface-methods lsp [flags] [path]")
+		fmt.Println("  unused-interface-methods explain [flags] pkg.Interface.Method [path | packages | files]")
+		fmt.Println("  unused-interface-methods index [flags] [-o FILE] [path | packages | files]")
+		fmt.Println("  unused-interface-methods query [-index FILE] [-format text|json] interfaces|methods [condition...]")
+		fmt.Println("  unused-interface-methods query [-index FILE] [-format text|json] implementers pkg.Interface")
+		fmt.Println("  unused-interface-methods compare [flags] rev-a rev-b [path]")
+		fmt.Println("  unused-interface-methods init [-force] [path]")
+		fmt.Println("  unused-interface-methods config validate [path...]")
+		fmt.Println("  unused-interface-methods config schema")
+		fmt.Println("  unused-interface-methods cache clean [-cache-dir DIR]")
 		fmt.Println()
 		fmt.Println("Flags:")
-		fmt.Println("  -v    Verbose output")
-		fmt.Println("  -h    Show this help")
+		fmt.Println("  -v                    Verbose output")
+		fmt.Println("  -h                    Show this help")
+		fmt.Println("  -format=FORMAT        Output format: text (default), json, sarif, checkstyle, junit, github")
+		fmt.Println("  -config=FILE          Use the config file instead of looking for one")
+		fmt.Println("  -files-from=FILE      Check interfaces only in listed Go files (- for stdin, go list -json accepted)")
+		fmt.Println("  -tags=TAGS            Comma-separated build tags, added to every build-matrix entry")
+		fmt.Println("  -modules=MODE         Modules analyzed together: work (default, go.work), auto (every go.mod), off")
+		fmt.Println("  -cache-dir=DIR        Package facts cache (default: user cache directory, off disables)")
+		fmt.Println("  -poll=INTERVAL        watch: poll the tree instead of file system notifications (e.g. 1s)")
+		fmt.Println("  -o=FILE               index: file to write (default " + index.DefaultFile + ")")
+		fmt.Println("  -baseline=FILE        Report only unused methods missing from the baseline")
+		fmt.Println("  -baseline-write=FILE  Write current unused methods to the baseline and exit")
+		fmt.Println("  -new-from-rev=REV     Report only unused methods introduced since the git revision")
+		fmt.Println("  -new-from-patch=FILE  Report only unused methods introduced by the patch")
+		fmt.Println("  -show-suppressed      List unused methods suppressed by directives")
 		fmt.Println()
 		fmt.Println("Config file:")
-		fmt.Println("  Automatically looks for .unused-interface-methods.yml")
+		fmt.Println("  Automatically looks for .unused-interface-methods.yml in the analyzed directory,")
+		fmt.Println("  its parents and subdirectories; \"init\" writes an annotated default config")
 		fmt.Println("  Example ignore patterns: \"**/*_test.go\", \"test/**\", \"**/mock/**\"")
 		fmt.Println()
+		fmt.Println("Watch mode:")
+		fmt.Println("  Re-analyzes packages affected by changed .go, go.mod, go.work and config files")
+		fmt.Println("  and prints newly unused and newly used methods")
+		fmt.Println()
+		fmt.Println("Language server:")
+		fmt.Println("  \"lsp\" serves the Language Server Protocol over stdin/stdout: diagnostics on unused")
+		fmt.Println("  methods in unsaved buffers, a quick fix removing the method and a hover with call sites")
+		fmt.Println()
+		fmt.Println("Explain:")
+		fmt.Println("  \"explain\" prints every piece of evidence behind the verdict on one method: call sites,")
+		fmt.Println("  method values, escapes, reflection, config rules and suppressions. The package path")
+		fmt.Println("  may be shortened to its last elements or omitted")
+		fmt.Println()
+		fmt.Println("Index and query:")
+		fmt.Println("  \"index\" writes interfaces, methods, signatures, embeddings, implementers and call sites")
+		fmt.Println("  to a JSON file; \"query\" answers questions against it without loading packages:")
+		fmt.Println("    query interfaces 'methods>8'")
+		fmt.Println("    query methods calls=1 package=**/store")
+		fmt.Println("    query implementers store.Store")
+		fmt.Println()
+		fmt.Println("Compare:")
+		fmt.Println("  \"compare\" checks out both git revisions into temporary worktrees, analyzes them and")
+		fmt.Println("  reports interfaces added or removed, methods that became unused or used and methods")
+		fmt.Println("  added since rev-a that are still unused")
+		fmt.Println()
 		fmt.Println("Note: Generic interfaces are detected but not analyzed (warnings will be shown)")
 		config.OsExit(0)
 	}
 
-	// Загрузка конфигурации
-	cfg, err := config.LoadConfig("")
+	if opts.newFromRev != "" && opts.newFromPatch != "" {
+		fmt.Printf("Error: -new-from-rev and -new-from-patch are mutually exclusive\n")
+		config.OsExit(config.ExitConfig)
+	}
+	if mode != "" && (*format != "text" || *baselineWrite != "") {
+		fmt.Printf("Error: %s supports only the text format and no -baseline-write\n", mode)
+		config.OsExit(config.ExitConfig)
+	}
+
+	reporter, err := report.New(*format, report.Options{Verbose: opts.verbose, ShowSuppressed: *showSuppressed})
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		config.OsExit(config.ExitConfig)
+	}
+
+	// explain принимает имя метода перед целью анализа
+	targetArgs := flag.Args()
+	var method string
+	if mode == "explain" {
+		if len(targetArgs) == 0 {
+			fmt.Printf("Error: explain requires a method: pkg.Interface.Method\n")
+			config.OsExit(config.ExitConfig)
+			return
+		}
+		method, targetArgs = targetArgs[0], targetArgs[1:]
+	}
+	// compare анализирует директорию в каждой из двух ревизий
+	var (
+		revs       []string
+		compareDir = "."
+	)
+	if mode == "compare" {
+		if len(targetArgs) < 2 || len(targetArgs) > 3 || *filesFrom != "" {
+			fmt.Printf("Error: compare requires two revisions and at most one directory: rev-a rev-b [path]\n")
+			config.OsExit(config.ExitConfig)
+			return
+		}
+		revs, targetArgs = targetArgs[:2], targetArgs[2:]
+		if len(targetArgs) == 1 {
+			if info, err := os.Stat(targetArgs[0]); err != nil || !info.IsDir() {
+				fmt.Printf("Error: compare accepts only a directory, not %s\n", targetArgs[0])
+				config.OsExit(config.ExitConfig)
+				return
+			}
+			compareDir = targetArgs[0]
+		}
+	}
+
+	// Интерфейсы проверяются в выбранном коде, а использования ищутся во всем модуле,
+	// поэтому для паттернов пакетов и списков файлов загружается весь модуль
+	tgt, err := target.Select(targetArgs, *filesFrom, os.Stdin)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		config.OsExit(config.ExitConfig)
+	}
+
+	// Машиночитаемый отчет и протокол LSP занимают stdout, подробный лог уходит в stderr
+	opts.logOutput = os.Stdout
+	if *format != "text" || mode == "lsp" {
+		opts.logOutput = os.Stderr
+	}
+
+	if opts.verbose {
+		fmt.Fprintf(opts.logOutput, "Analyzing directory: %s\n", tgt.Root)
+	}
+
+	// Неизмененные пакеты берутся из кэша; watch и lsp без кэша на диске держат факты в памяти
+	factsCache, err := cache.Open(*cacheDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: cache disabled: %v\n", err)
+	}
+	if (mode == "watch" || mode == "lsp") && factsCache == nil {
+		factsCache = cache.Memory()
+	}
+	switch mode {
+	case "watch":
+		config.OsExit(runWatch(tgt, opts, factsCache, *poll, reporter))
+		return
+	case "lsp":
+		config.OsExit(runLSP(tgt.Root, opts, factsCache))
+		return
+	case "explain":
+		config.OsExit(runExplain(tgt, method, opts, factsCache))
+		return
+	case "index":
+		config.OsExit(runIndex(tgt, *output, opts, factsCache))
+		return
+	case "compare":
+		config.OsExit(runCompare(compareDir, revs[0], revs[1], opts, factsCache))
+		return
+	}
+
+	a, err := analyze(tgt, opts, factsCache)
+	if err != nil {
+		fmt.Println(err)
+		config.OsExit(exitCode(err))
+	}
+	res := a.res
+
+	if *baselineWrite != "" {
+		b := baseline.New(res)
+		if err := b.Write(*baselineWrite); err != nil {
+			fmt.Printf("Error writing baseline: %v\n", err)
+			config.OsExit(config.ExitInternal)
+		}
+		fmt.Fprintf(os.Stderr, "Baseline written to %s: %d entries\n", *baselineWrite, len(b.Entries))
+		config.OsExit(config.ExitOK)
+	}
+
+	if err := narrow(a, tgt, opts, factsCache); err != nil {
+		fmt.Println(err)
+		config.OsExit(exitCode(err))
+	}
+
+	if err := reporter.Report(os.Stdout, res); err != nil {
+		fmt.Printf("Error writing report: %v\n", err)
+		config.OsExit(config.ExitInternal)
+	}
+
+	// Код возврата определяет порог fail-on: проблемы ниже него не проваливают запуск.
+	// Неполная загрузка важнее находок: результат по остальным пакетам может быть неточным
+	failOn := a.cfg.FailOnLevel()
+	switch {
+	case res.LoadFails(failOn):
+		config.OsExit(config.ExitLoad)
+	case res.Fails(failOn):
+		config.OsExit(config.ExitFindings)
+	}
+}
+
+// options - флаги, от которых зависит анализ; общие для разового запуска и watch
+type options struct {
+	verbose      bool
+	cfgFile      string
+	tags         string
+	modules      string
+	baselineFile string
+	newFromRev   string
+	newFromPatch string
+	logOutput    io.Writer
+	overlay      map[string][]byte        // несохраненные файлы из редактора (lsp)
+	allEvidence  bool                     // собирать все использования методов (explain, index)
+	interfaces   bool                     // собирать объявления интерфейсов с реализациями (index)
+	graphs       map[string]*linter.Graph // графы пакетов по конфигурациям сборки между запусками (watch)
+}
+
+// analysis - результат анализа и конфигурация, с которой он получен
+type analysis struct {
+	res        *results.Result
+	cfg        *config.Config
+	root       string            // корень рабочего пространства
+	dropped    []results.Finding // находки, убранные правилами ignore и серьезностью off
+	interfaces []linter.InterfaceDecl
+}
+
+// exitError - ошибка запуска с кодом возврата; текст выводится как есть
+type exitError struct {
+	code int
+	msg  string
+}
+
+func (e *exitError) Error() string { return e.msg }
+
+// fail возвращает ошибку запуска с кодом возврата code
+func fail(code int, format string, args ...any) error {
+	return &exitError{code: code, msg: fmt.Sprintf(format, args...)}
+}
+
+// exitCode возвращает код возврата для ошибки запуска
+func exitCode(err error) int {
+	var e *exitError
+	if errors.As(err, &e) {
+		return e.code
+	}
+	return config.ExitInternal
+}
+
+// analyze загружает конфигурацию и пакеты и находит неиспользуемые методы
+// во всех конфигурациях сборки
+func analyze(tgt *target.Target, opts options, factsCache *cache.Cache) (*analysis, error) {
+	// Модули из go.work или найденные go.mod загружаются вместе: вызовы между ними - использования
+	ws, err := workspace.Find(tgt.Root, opts.modules)
+	if err != nil {
+		return nil, fail(config.ExitConfig, "Error: %v", err)
+	}
+	defer ws.Close() // сгенерированный go.work нужен только go list
+
+	// Загрузка конфигурации: файл из -config или файлы из директории анализа (корня рабочего
+	// пространства), ее родителей и поддиректорий. Без файлов паттерны путей отсчитываются
+	// от корня модуля, поэтому результат не зависит от директории запуска
+	var cfg *config.Config
+	if opts.cfgFile != "" {
+		cfg, err = config.LoadFile(opts.cfgFile)
+	} else if cfg, err = config.Load(ws.Root); err == nil {
+		err = cfg.AddModules(ws.Modules)
+	}
 	if err != nil {
-		fmt.Printf("Error loading config: %v\n", err)
-		config.OsExit(1)
+		return nil, fail(config.ExitConfig, "Error loading config: %v", err)
 	}
 
-	args := flag.Args()
-	dir := "."
-	if len(args) > 0 {
-		dir = args[0]
+	// Правилам implements нужны типы всех пакетов, а у пакетов из кэша их нет,
+	// поэтому с такими правилами кэш не используется
+	if cfg.UsesImplements() {
+		factsCache = nil
 	}
 
-	if *verbose {
-		fmt.Printf("Analyzing directory: %s\n", dir)
+	// Пакеты загружаются для каждой конфигурации сборки: метод, использованный
+	// хотя бы в одной из них, считается используемым
+	var (
+		runs       []*results.Result
+		directives []results.Suppression
+		typesPkgs  []*types.Package
+		interfaces []linter.InterfaceDecl
+	)
+	builds := cfg.Builds(config.ParseTags(opts.tags))
+	for _, build := range builds {
+		if opts.verbose && len(builds) > 1 {
+			fmt.Fprintf(opts.logOutput, "Build: %s\n", build)
+		}
+
+		l := linter.New(cfg, opts.verbose)
+		l.SetLogOutput(opts.logOutput)
+		l.SetBuild(build.GOOS, build.GOARCH, build.Tags)
+		l.SetModules(ws.GoWork, ws.Modules)
+		l.SetScope(tgt.Contains)
+		l.SetCache(factsCache)
+		if opts.graphs != nil && opts.overlay == nil {
+			g := opts.graphs[build.String()]
+			if g == nil {
+				g = linter.NewGraph()
+				opts.graphs[build.String()] = g
+			}
+			l.SetGraph(g)
+		}
+		l.SetOverlay(opts.overlay)
+		l.SetAllEvidence(opts.allEvidence)
+		if err := l.LoadPackages(tgt.Root); err != nil {
+			return nil, fail(config.ExitLoad, "Error loading packages for %s: %v", build, err)
+		}
+
+		l.ExtractInterfaceMethods()
+		runs = append(runs, l.FindUnusedMethods())
+		directives = append(directives, l.Directives()...)
+		typesPkgs = append(typesPkgs, l.TypesPackages()...)
+		if opts.interfaces {
+			interfaces = append(interfaces, l.Interfaces()...)
+		}
+	}
+	res := results.Merge(runs...)
+	dropped, err := postprocess.Apply(res, cfg, directives, rules.NewLookup(typesPkgs), time.Now(), os.Stderr)
+	if err != nil {
+		return nil, fail(config.ExitConfig, "Error checking ignore rules: %v", err)
 	}
+	return &analysis{res: res, cfg: cfg, root: ws.Root, dropped: dropped, interfaces: interfaces}, nil
+}
 
-	linter := linter.New(cfg, *verbose)
+// narrow оставляет находки, которых нет в baseline и которые относятся к изменениям.
+// Фильтр по изменениям применяется после baseline, иначе отброшенные им находки попали бы в FIXED.
+// Для фильтра по изменениям базовая версия измененных файлов анализируется повторно
+func narrow(a *analysis, tgt *target.Target, opts options, factsCache *cache.Cache) error {
+	res := a.res
+	if opts.baselineFile != "" {
+		b, err := baseline.Load(opts.baselineFile)
+		if err != nil {
+			return fail(config.ExitConfig, "Error loading baseline: %v", err)
+		}
+		b.Apply(res)
+	}
 
-	err = linter.LoadPackages(dir)
+	var (
+		changeSet *changes.Set
+		err       error
+	)
+	switch {
+	case opts.newFromRev != "":
+		changeSet, err = changes.FromRev(opts.newFromRev)
+	case opts.newFromPatch != "":
+		changeSet, err = changes.FromPatch(opts.newFromPatch)
+	}
 	if err != nil {
-		fmt.Printf("Error loading packages: %v\n", err)
-		config.OsExit(1)
+		return fail(config.ExitInternal, "Error reading changes: %v", err)
+	}
+	if changeSet == nil {
+		return nil
 	}
 
-	linter.ExtractInterfaceMethods()
-	unused := !linter.FindUnusedMethods()
-	if unused {
-		config.OsExit(1)
+	// Метод новый, если он не используется сейчас, но использовался в базовой версии
+	overlay, err := changeSet.Base(opts.overlay)
+	if err != nil {
+		return fail(config.ExitInternal, "Error restoring base version: %v", err)
+	}
+	for file, data := range opts.overlay {
+		if _, ok := overlay[file]; !ok {
+			overlay[file] = data
+		}
+	}
+	baseOpts := opts
+	baseOpts.overlay = overlay
+	baseOpts.interfaces = false
+	baseOpts.graphs = nil // граф хранит текущую версию, а не базовую
+	base, err := analyze(tgt, baseOpts, factsCache)
+	if err != nil {
+		return err
 	}
+	changeSet.Apply(res, append(base.res.Findings, base.dropped...))
+	return nil
 }
@@ -0,0 +1,700 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// project - модуль, в котором test/ и internal/legacy/ должны пропускаться
+var project = map[string]string{
+	"go.mod": "module example.com/anchor\n\ngo 1.21\n",
+	"internal/api/api.go": `package api
+
+type Service interface {
+	Used()
+	Unused()
+}
+
+var svc Service
+
+func Run() {
+	svc.Used()
+}
+`,
+	"internal/legacy/legacy.go": `package legacy
+
+type Old interface {
+	Gone()
+}
+`,
+	"test/helpers/helpers.go": `package helpers
+
+type Fixture interface {
+	Setup()
+}
+`,
+}
+
+// buildCLI собирает линтер во временную директорию
+func buildCLI(t *testing.T) string {
+	t.Helper()
+	bin := filepath.Join(t.TempDir(), "unused-interface-methods")
+	if out, err := exec.Command("go", "build", "-o", bin, ".").CombinedOutput(); err != nil {
+		t.Fatalf("go build: %v\n%s", err, out)
+	}
+	return bin
+}
+
+// writeProject создает файлы проекта в root
+func writeProject(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// unusedMethods запускает линтер из директории wd и возвращает найденные неиспользуемые методы
+func unusedMethods(t *testing.T, bin, wd string, args ...string) []string {
+	t.Helper()
+	return unusedMethodsWithInput(t, bin, wd, "", args...)
+}
+
+// unusedMethodsWithInput запускает линтер, передавая stdin, и возвращает найденные неиспользуемые методы
+func unusedMethodsWithInput(t *testing.T, bin, wd, stdin string, args ...string) []string {
+	t.Helper()
+	cmd := exec.Command(bin, append([]string{"-format=json"}, args...)...)
+	cmd.Dir = wd
+	cmd.Stdin = strings.NewReader(stdin)
+	out, err := cmd.Output()
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		t.Fatalf("run %s: %v", bin, err)
+	}
+
+	var doc struct {
+		Findings []struct {
+			Package   string `json:"package"`
+			Interface string `json:"interface"`
+			Method    string `json:"method"`
+			Verdict   string `json:"verdict"`
+		} `json:"findings"`
+	}
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatalf("parse report: %v\n%s", err, out)
+	}
+
+	var methods []string
+	for _, f := range doc.Findings {
+		if f.Verdict == "unused" {
+			methods = append(methods, f.Package+"."+f.Interface+"."+f.Method)
+		}
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+func TestPatternsDoNotDependOnWorkingDirectory(t *testing.T) {
+	bin := buildCLI(t)
+
+	t.Run("module root without config", func(t *testing.T) {
+		parent := t.TempDir()
+		root := filepath.Join(parent, "anchor")
+		writeProject(t, root, project)
+
+		// test/** из конфигурации по умолчанию отсчитывается от корня модуля
+		want := []string{
+			"example.com/anchor/internal/api.Service.Unused",
+			"example.com/anchor/internal/legacy.Old.Gone",
+		}
+		assert.Equal(t, want, unusedMethods(t, bin, root, "."))
+		assert.Equal(t, want, unusedMethods(t, bin, root, root))
+		assert.Equal(t, want, unusedMethods(t, bin, filepath.Join(root, "internal"), ".."))
+		assert.Equal(t, want, unusedMethods(t, bin, filepath.Join(root, "internal", "api"), root))
+		assert.Equal(t, want, unusedMethods(t, bin, parent, "anchor"))
+	})
+
+	t.Run("config directory", func(t *testing.T) {
+		root := t.TempDir()
+		writeProject(t, root, project)
+		writeProject(t, root, map[string]string{
+			".unused-interface-methods.yml": "ignore:\n  - \"test/**\"\n  - \"internal/legacy/**\"\n",
+		})
+
+		want := []string{"example.com/anchor/internal/api.Service.Unused"}
+		assert.Equal(t, want, unusedMethods(t, bin, root, "."))
+		assert.Equal(t, want, unusedMethods(t, bin, root, root))
+		assert.Equal(t, want, unusedMethods(t, bin, root, "./internal/.."))
+	})
+	t.Run("nested config", func(t *testing.T) {
+		root := t.TempDir()
+		writeProject(t, root, project)
+		writeProject(t, root, map[string]string{
+			"internal/legacy/.unused-interface-methods.yml": "ignore:\n  - \"*.go\"\n",
+		})
+
+		// Файл в internal/legacy действует только на свое поддерево
+		want := []string{"example.com/anchor/internal/api.Service.Unused"}
+		assert.Equal(t, want, unusedMethods(t, bin, root, "."))
+		assert.Equal(t, want, unusedMethods(t, bin, filepath.Join(root, "internal"), ".."))
+	})
+}
+
+// platforms - модуль, где методы вызываются только в файлах с ограничениями сборки
+var platforms = map[string]string{
+	"go.mod": "module example.com/platforms\n\ngo 1.21\n",
+	"sink.go": `package sink
+
+type Sink interface {
+	Write()
+	Notify() // только linux
+	Flush()  // только с тегом integration
+	Reset()
+}
+
+var s Sink
+
+func Write() { s.Write() }
+`,
+	"sink_linux.go": `package sink
+
+func Notify() { s.Notify() }
+`,
+	"sink_integration.go": `//go:build integration
+
+package sink
+
+func Flush() { s.Flush() }
+`,
+	"service_windows.go": `package sink
+
+type Service interface {
+	Start()
+	Stop()
+}
+
+var svc Service
+
+func Start() { svc.Start() }
+`,
+}
+
+func TestBuildMatrix(t *testing.T) {
+	bin := buildCLI(t)
+	root := t.TempDir()
+	writeProject(t, root, platforms)
+
+	t.Run("tags", func(t *testing.T) {
+		t.Setenv("GOOS", "linux")
+		assert.Equal(t, []string{
+			"example.com/platforms.Sink.Flush",
+			"example.com/platforms.Sink.Reset",
+		}, unusedMethods(t, bin, root, "."))
+		assert.Equal(t, []string{
+			"example.com/platforms.Sink.Reset",
+		}, unusedMethods(t, bin, root, "-tags=integration", "."))
+	})
+
+	t.Run("matrix", func(t *testing.T) {
+		writeProject(t, root, map[string]string{
+			".unused-interface-methods.yml": "build-matrix:\n  - goos: linux\n  - goos: windows\n    goarch: arm64\n",
+		})
+
+		// Метод из файла для windows проверяется только в этой конфигурации
+		assert.Equal(t, []string{
+			"example.com/platforms.Service.Stop",
+			"example.com/platforms.Sink.Flush",
+			"example.com/platforms.Sink.Reset",
+		}, unusedMethods(t, bin, root, "."))
+		assert.Equal(t, []string{
+			"example.com/platforms.Service.Stop",
+			"example.com/platforms.Sink.Reset",
+		}, unusedMethods(t, bin, root, "-tags=integration", "."))
+	})
+}
+
+// monorepo - два модуля: интерфейс объявлен в api, а используется в app
+var monorepo = map[string]string{
+	"api/go.mod": "module example.com/api\n\ngo 1.21\n",
+	"api/store.go": `package api
+
+type Store interface {
+	Get()
+	Put()
+}
+`,
+	"app/go.mod": "module example.com/app\n\ngo 1.21\n\nrequire example.com/api v0.0.0\n",
+	"app/main.go": `package main
+
+import "example.com/api"
+
+var s api.Store
+
+func main() { s.Get() }
+`,
+	"app/test/fixture.go": `package fixture
+
+type Fixture interface {
+	Setup()
+}
+`,
+}
+
+func TestModules(t *testing.T) {
+	bin := buildCLI(t)
+
+	t.Run("go.work", func(t *testing.T) {
+		root := t.TempDir()
+		writeProject(t, root, monorepo)
+		writeProject(t, root, map[string]string{"go.work": "go 1.21\n\nuse (\n\t./api\n\t./app\n)\n"})
+
+		// Вызов из app считается использованием, test/** отсчитывается от корня каждого модуля
+		want := []string{"example.com/api.Store.Put"}
+		assert.Equal(t, want, unusedMethods(t, bin, root, "."))
+		assert.Equal(t, want, unusedMethods(t, bin, root, "./api"))
+		assert.Equal(t, []string{
+			"example.com/api.Store.Get",
+			"example.com/api.Store.Put",
+		}, unusedMethods(t, bin, filepath.Join(root, "api"), "-modules=off", "."))
+	})
+
+	t.Run("auto", func(t *testing.T) {
+		root := t.TempDir()
+		writeProject(t, root, monorepo)
+
+		assert.Equal(t, []string{"example.com/api.Store.Put"}, unusedMethods(t, bin, root, "-modules=auto", "."))
+	})
+}
+
+// selection - модуль, где интерфейс из store используется только в cmd/api
+var selection = map[string]string{
+	"go.mod": "module example.com/sel\n\ngo 1.21\n",
+	"store/store.go": `package store
+
+type Store interface {
+	Get()
+	Put()
+}
+`,
+	"cmd/api/main.go": `package main
+
+import "example.com/sel/store"
+
+var s store.Store
+
+func main() { s.Get() }
+`,
+	"cmd/worker/main.go": `package main
+
+type Job interface {
+	Run()
+}
+
+func main() {}
+`,
+}
+
+func TestSelection(t *testing.T) {
+	bin := buildCLI(t)
+	root := t.TempDir()
+	writeProject(t, root, selection)
+
+	// Интерфейсы проверяются только в выбранных пакетах, а использования ищутся во всем модуле
+	store := []string{"example.com/sel/store.Store.Put"}
+	assert.Equal(t, store, unusedMethods(t, bin, root, "./store"))
+	assert.Equal(t, store, unusedMethods(t, bin, root, "./store/...", "./cmd/api"))
+	assert.Equal(t, store, unusedMethods(t, bin, filepath.Join(root, "cmd"), "example.com/sel/store"))
+	assert.Equal(t, []string{
+		"example.com/sel/cmd/worker.Job.Run",
+		"example.com/sel/store.Store.Put",
+	}, unusedMethods(t, bin, root, "./..."))
+
+	// Список файлов, например из git diff --name-only
+	writeProject(t, root, map[string]string{"changed.txt": "store/store.go\nREADME.md\n"})
+	assert.Equal(t, store, unusedMethods(t, bin, root, "-files-from=changed.txt"))
+	assert.Equal(t, store, unusedMethodsWithInput(t, bin, root, "store/store.go\n", "-files-from=-"))
+
+	list := exec.Command("go", "list", "-json", "./cmd/worker")
+	list.Dir = root
+	out, err := list.Output()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"example.com/sel/cmd/worker.Job.Run"}, unusedMethodsWithInput(t, bin, root, string(out), "-files-from=-"))
+}
+
+func TestCacheDir(t *testing.T) {
+	bin := buildCLI(t)
+	root := t.TempDir()
+	writeProject(t, root, project)
+	cacheDir := filepath.Join(t.TempDir(), "cache")
+
+	// Повторный запуск берет пакеты из кэша и дает тот же результат
+	want := []string{
+		"example.com/anchor/internal/api.Service.Unused",
+		"example.com/anchor/internal/legacy.Old.Gone",
+	}
+	assert.Equal(t, want, unusedMethods(t, bin, root, "-cache-dir="+cacheDir, "."))
+	assert.DirExists(t, cacheDir)
+	assert.Equal(t, want, unusedMethods(t, bin, root, "-cache-dir="+cacheDir, "."))
+
+	// Использование в измененном файле учитывается вместе с фактами из кэша
+	writeProject(t, root, map[string]string{
+		"internal/api/run.go": "package api\n\nfunc Stop() { svc.Unused() }\n",
+	})
+	assert.Equal(t, want[1:], unusedMethods(t, bin, root, "-cache-dir="+cacheDir, "."))
+
+	out, err := exec.Command(bin, "cache", "clean", "-cache-dir", cacheDir).CombinedOutput()
+	assert.NoError(t, err, string(out))
+	assert.NoDirExists(t, cacheDir)
+}
+
+func TestWatch(t *testing.T) {
+	bin := buildCLI(t)
+	root := t.TempDir()
+	writeProject(t, root, project)
+
+	cmd := exec.Command(bin, "watch", "-cache-dir=off", ".")
+	cmd.Dir = root
+	stdout, err := cmd.StdoutPipe()
+	assert.NoError(t, err)
+	assert.NoError(t, cmd.Start())
+	defer cmd.Process.Kill()
+
+	lines := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(stdout)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+	// waitFor читает вывод до строки, начинающейся с prefix
+	waitFor := func(prefix string) {
+		t.Helper()
+		timeout := time.After(30 * time.Second)
+		for {
+			select {
+			case line, ok := <-lines:
+				if !ok {
+					t.Fatalf("watch завершился, не выведя %q", prefix)
+				}
+				if strings.HasPrefix(line, prefix) {
+					return
+				}
+			case <-timeout:
+				t.Fatalf("нет строки %q", prefix)
+			}
+		}
+	}
+
+	waitFor("UNUSED: example.com/anchor/internal/api.Service.Unused")
+	waitFor("Watching ")
+
+	run := filepath.Join(root, "internal", "api", "run.go")
+	writeProject(t, root, map[string]string{
+		"internal/api/run.go": "package api\n\nfunc Stop() { svc.Unused() }\n",
+	})
+	waitFor("USED: example.com/anchor/internal/api.Service.Unused")
+	waitFor("Total: 1 unused")
+
+	assert.NoError(t, os.Remove(run))
+	waitFor("UNUSED: example.com/anchor/internal/api.Service.Unused")
+
+	assert.NoError(t, cmd.Process.Signal(os.Interrupt))
+	assert.NoError(t, cmd.Wait())
+}
+
+func TestLSP(t *testing.T) {
+	bin := buildCLI(t)
+	root := t.TempDir()
+	writeProject(t, root, project)
+
+	cmd := exec.Command(bin, "lsp", "-cache-dir=off")
+	cmd.Dir = root
+	stdin, err := cmd.StdinPipe()
+	assert.NoError(t, err)
+	stdout, err := cmd.StdoutPipe()
+	assert.NoError(t, err)
+	assert.NoError(t, cmd.Start())
+	defer cmd.Process.Kill()
+
+	in := bufio.NewReader(stdout)
+	send := func(msg map[string]any) {
+		t.Helper()
+		msg["jsonrpc"] = "2.0"
+		body, err := json.Marshal(msg)
+		assert.NoError(t, err)
+		_, err = fmt.Fprintf(stdin, "Content-Length: %d\r\n\r\n%s", len(body), body)
+		assert.NoError(t, err)
+	}
+	// receive читает сообщения сервера до сообщения, для которого match возвращает true
+	receive := func(match func(msg map[string]any) bool) map[string]any {
+		t.Helper()
+		for {
+			var length int
+			for {
+				line, err := in.ReadString('\n')
+				if err != nil {
+					t.Fatalf("read: %v", err)
+				}
+				line = strings.TrimSpace(line)
+				if line == "" {
+					break
+				}
+				fmt.Sscanf(line, "Content-Length: %d", &length)
+			}
+			body := make([]byte, length)
+			_, err := io.ReadFull(in, body)
+			assert.NoError(t, err)
+			var msg map[string]any
+			assert.NoError(t, json.Unmarshal(body, &msg))
+			if match(msg) {
+				return msg
+			}
+		}
+	}
+	apiFile := filepath.Join(root, "internal", "api", "api.go")
+	apiURI := "file://" + filepath.ToSlash(apiFile)
+	// diagnostics ждет диагностик api.go и возвращает их сообщения
+	diagnostics := func() []string {
+		t.Helper()
+		msg := receive(func(msg map[string]any) bool {
+			params, _ := msg["params"].(map[string]any)
+			return msg["method"] == "textDocument/publishDiagnostics" && params["uri"] == apiURI
+		})
+		var messages []string
+		for _, d := range msg["params"].(map[string]any)["diagnostics"].([]any) {
+			messages = append(messages, d.(map[string]any)["message"].(string))
+		}
+		return messages
+	}
+
+	send(map[string]any{"id": 1, "method": "initialize", "params": map[string]any{"rootUri": "file://" + filepath.ToSlash(root)}})
+	receive(func(msg map[string]any) bool { return msg["id"] == float64(1) })
+	send(map[string]any{"method": "initialized", "params": map[string]any{}})
+	assert.Equal(t, []string{"Interface method Service.Unused() is not used"}, diagnostics())
+
+	// Вызов в несохраненном буфере учитывается без записи на диск
+	send(map[string]any{"method": "textDocument/didOpen", "params": map[string]any{"textDocument": map[string]any{
+		"uri": apiURI, "languageId": "go", "version": 1,
+		"text": strings.Replace(project["internal/api/api.go"], "svc.Used()", "svc.Used()\n\tsvc.Unused()", 1),
+	}}})
+	assert.Empty(t, diagnostics())
+
+	send(map[string]any{"id": 2, "method": "shutdown"})
+	receive(func(msg map[string]any) bool { return msg["id"] == float64(2) })
+	send(map[string]any{"method": "exit"})
+	assert.NoError(t, cmd.Wait())
+}
+
+// explained - модуль, где Plugin.Name вызывается дважды, Start - через reflect,
+// а Legacy убран правилом ignore
+var explained = map[string]string{
+	"go.mod": "module example.com/explained\n\ngo 1.21\n",
+	".unused-interface-methods.yml": `rules:
+  - method: Legacy
+    action: ignore
+    reason: kept for old plugins
+`,
+	"plugin/plugin.go": `package plugin
+
+type Plugin interface {
+	Name() string
+	Start()
+	Stop()
+	Legacy()
+}
+`,
+	"app/app.go": `package app
+
+import (
+	"reflect"
+
+	"example.com/explained/plugin"
+)
+
+func Describe(p plugin.Plugin) string { return p.Name() }
+
+func Title(p plugin.Plugin) string { return p.Name() }
+
+func Run(p any) {
+	reflect.ValueOf(p).MethodByName("Start").Call(nil)
+}
+`,
+}
+
+func TestExplain(t *testing.T) {
+	bin := buildCLI(t)
+	root := t.TempDir()
+	writeProject(t, root, explained)
+
+	explain := func(method string) (string, int) {
+		t.Helper()
+		cmd := exec.Command(bin, "explain", "-cache-dir=off", method, ".")
+		cmd.Dir = root
+		out, err := cmd.CombinedOutput()
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return string(out), exitErr.ExitCode()
+		}
+		assert.NoError(t, err)
+		return string(out), 0
+	}
+
+	// Все места вызова, а не только первое
+	out, code := explain("plugin.Plugin.Name")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "Method: example.com/explained/plugin.Plugin.Name() string (plugin/plugin.go:4)\n"+
+		"Verdict: used (engine linter)\n"+
+		"Evidence:\n"+
+		"  - call at app/app.go:9\n"+
+		"  - call at app/app.go:11\n"+
+		"Reported: no, the method is used\n", out)
+
+	// Вызов через reflect выводится, но метод используемым не делает
+	out, _ = explain("Plugin.Start")
+	assert.Contains(t, out, "Verdict: unused (engine linter)\n"+
+		"Evidence:\n"+
+		"  - possible reflection call (MethodByName), not counted as a use at app/app.go:14\n")
+
+	out, _ = explain("example.com/explained/plugin.Plugin.Stop")
+	assert.Contains(t, out, "Reported: yes, exported-unused as error\n")
+
+	// Метод, убранный правилом, объясняется вместе с правилом
+	out, _ = explain("Plugin.Legacy")
+	assert.Contains(t, out, "Rule: method=Legacy (action ignore) (.unused-interface-methods.yml:2) - kept for old plugins\n")
+	assert.Contains(t, out, "Reported: no, removed by an ignore rule\n")
+
+	out, code = explain("Plugin.Missing")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, out, "method Plugin.Missing not found")
+}
+
+func TestIndex(t *testing.T) {
+	bin := buildCLI(t)
+	root := t.TempDir()
+	writeProject(t, root, explained)
+
+	run := func(args ...string) (string, int) {
+		t.Helper()
+		cmd := exec.Command(bin, args...)
+		cmd.Dir = root
+		out, err := cmd.CombinedOutput()
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return string(out), exitErr.ExitCode()
+		}
+		assert.NoError(t, err)
+		return string(out), 0
+	}
+
+	out, code := run("index", "-cache-dir=off", "-o", "index.json", ".")
+	assert.Equal(t, 0, code, out)
+	assert.Contains(t, out, "Index written to index.json (interfaces: 1, methods: 4)")
+
+	// Запросы читают только индекс: исходники уже не нужны
+	assert.NoError(t, os.RemoveAll(filepath.Join(root, "app")))
+
+	out, code = run("query", "-index", "index.json", "methods", "calls>=2")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "example.com/explained/plugin.Plugin.Name() string (plugin/plugin.go:4): used, 2 call sites\n", out)
+
+	out, _ = run("query", "-index", "index.json", "interfaces", "unused>0", "package=**/plugin")
+	assert.Equal(t, "example.com/explained/plugin.Plugin (plugin/plugin.go:3): 4 methods, 0 implementers\n", out)
+
+	_, code = run("query", "-index", "index.json", "methods", "calls=many")
+	assert.Equal(t, 2, code)
+}
+
+func TestCompare(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+	bin := buildCLI(t)
+	root := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = root
+		out, err := cmd.CombinedOutput()
+		assert.NoError(t, err, string(out))
+	}
+
+	// v1: Name вызывается, Start - только через reflect, Stop нет; v2: Name больше не
+	// вызывается, Start и Stop вызываются, добавлены метод Pause и интерфейс Hook без использований
+	writeProject(t, root, explained)
+	git("init", "-q")
+	git("add", "-A")
+	git("commit", "-qm", "v1")
+	git("tag", "v1")
+	writeProject(t, root, map[string]string{
+		"plugin/plugin.go": `package plugin
+
+type Plugin interface {
+	Name() string
+	Start()
+	Stop()
+	Legacy()
+	Pause()
+}
+
+type Hook interface {
+	Fire()
+}
+`,
+		"app/app.go": `package app
+
+import "example.com/explained/plugin"
+
+func Shutdown(p plugin.Plugin) { p.Stop() }
+
+func Start(p plugin.Plugin) { p.Start() }
+`,
+	})
+	git("commit", "-qam", "v2")
+
+	cmd := exec.Command(bin, "compare", "-cache-dir=off", "v1", "HEAD")
+	cmd.Dir = root
+	out, err := cmd.CombinedOutput()
+	assert.NoError(t, err, string(out))
+	assert.Equal(t, "Comparing v1..HEAD\n"+
+		"Interfaces added:\n"+
+		"  + example.com/explained/plugin.Hook (plugin/plugin.go:11): 1 method\n"+
+		"Became unused:\n"+
+		"  example.com/explained/plugin.Plugin.Name() string (plugin/plugin.go:4)\n"+
+		"Became used:\n"+
+		"  example.com/explained/plugin.Plugin.Start() (plugin/plugin.go:5)\n"+
+		"  example.com/explained/plugin.Plugin.Stop() (plugin/plugin.go:6)\n"+
+		"Added and never used:\n"+
+		"  example.com/explained/plugin.Hook.Fire() (plugin/plugin.go:12)\n"+
+		"  example.com/explained/plugin.Plugin.Pause() (plugin/plugin.go:8)\n", string(out))
+
+	// Временные рабочие деревья удалены
+	list := exec.Command("git", "worktree", "list")
+	list.Dir = root
+	worktrees, err := list.Output()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(worktrees), "\n"))
+
+	cmd = exec.Command(bin, "compare", "-cache-dir=off", "v1", "missing")
+	cmd.Dir = root
+	out, _ = cmd.CombinedOutput()
+	assert.Equal(t, 2, cmd.ProcessState.ExitCode())
+	assert.Contains(t, string(out), "invalid reference: missing")
+}
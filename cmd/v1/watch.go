@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/comerc/unused-interface-methods/pkg/cache"
+	"github.com/comerc/unused-interface-methods/pkg/config"
+	"github.com/comerc/unused-interface-methods/pkg/linter"
+	"github.com/comerc/unused-interface-methods/pkg/report"
+	"github.com/comerc/unused-interface-methods/pkg/results"
+	"github.com/comerc/unused-interface-methods/pkg/target"
+	"github.com/comerc/unused-interface-methods/pkg/watch"
+)
+
+// runWatch выводит полный отчет, а затем после каждой пачки изменений повторяет анализ
+// и выводит разницу с предыдущим результатом. Граф пакетов хранится между анализами:
+// go list загружает заново только пакеты измененных файлов, а факты неизмененных пакетов
+// берутся из кэша, поэтому заново анализируются только измененные пакеты и пакеты,
+// которые их импортируют. Ошибка анализа не останавливает наблюдение: разница считается
+// от последнего удачного запуска
+func runWatch(tgt *target.Target, opts options, factsCache *cache.Cache, poll time.Duration, reporter report.Reporter) int {
+	opts.graphs = make(map[string]*linter.Graph)
+	var prev *results.Result
+	root := tgt.Root
+	if a, err := analyzeNarrowed(tgt, opts, factsCache); err != nil {
+		fmt.Println(err)
+	} else {
+		prev, root = a.res, a.root
+		if err := reporter.Report(os.Stdout, prev); err != nil {
+			fmt.Printf("Error writing report: %v\n", err)
+			return config.ExitInternal
+		}
+		printTotals(prev)
+	}
+
+	var files []string
+	if opts.cfgFile != "" {
+		files = append(files, opts.cfgFile)
+	}
+	if opts.baselineFile != "" {
+		files = append(files, opts.baselineFile)
+	}
+	w, err := watch.New(root, watch.Options{Poll: poll, Files: files})
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return config.ExitInternal
+	}
+	defer w.Close()
+
+	mode := "file system notifications"
+	if w.Polling() {
+		mode = "polling"
+	}
+	fmt.Printf("Watching %s (%s), press Ctrl+C to stop\n", root, mode)
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(interrupt)
+	for {
+		select {
+		case <-interrupt:
+			return config.ExitOK
+		case err := <-w.Errors():
+			fmt.Fprintf(os.Stderr, "Warning: watch: %v\n", err)
+		case changed, ok := <-w.Changes():
+			if !ok {
+				return config.ExitOK
+			}
+			fmt.Printf("\n[%s] Changed: %s\n", time.Now().Format("15:04:05"), describeChanges(changed))
+			for _, g := range opts.graphs {
+				g.Invalidate(changed)
+			}
+			a, err := analyzeNarrowed(tgt, opts, factsCache)
+			if err != nil {
+				fmt.Println(err)
+				continue
+			}
+			if err := (report.Text{}).ReportDelta(os.Stdout, results.Diff(prev, a.res)); err != nil {
+				fmt.Printf("Error writing report: %v\n", err)
+				return config.ExitInternal
+			}
+			printTotals(a.res)
+			prev = a.res
+		}
+	}
+}
+
+// analyzeNarrowed выполняет анализ с фильтрами baseline и изменений: повторный анализ
+// в watch и lsp. Без графов в opts (lsp) граф пакетов каждый раз загружается заново
+// (go list по всему дереву), из кэша берутся только факты пакетов с неизменными ключами
+func analyzeNarrowed(tgt *target.Target, opts options, factsCache *cache.Cache) (*analysis, error) {
+	a, err := analyze(tgt, opts, factsCache)
+	if err != nil {
+		return nil, err
+	}
+	if err := narrow(a, tgt, opts, factsCache); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// printTotals выводит число выводимых находок и ошибок загрузки после каждого анализа
+func printTotals(res *results.Result) {
+	s := res.Summary()
+	line := fmt.Sprintf("Total: %d unused", s.Unused)
+	if s.LoadErrors > 0 {
+		line += fmt.Sprintf(", %d load errors", s.LoadErrors)
+	}
+	fmt.Println(line)
+}
+
+// describeChanges перечисляет измененные файлы относительно текущей директории;
+// длинный список сокращается
+func describeChanges(files []string) string {
+	const limit = 3
+	names := make([]string, 0, limit)
+	for i, file := range files {
+		if i == limit {
+			names = append(names, fmt.Sprintf("and %d more", len(files)-limit))
+			break
+		}
+		names = append(names, config.GetRelativePath(file))
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/comerc/unused-interface-methods/pkg/config"
+	"github.com/comerc/unused-interface-methods/pkg/report"
+	"github.com/comerc/unused-interface-methods/pkg/results"
+)
+
+// explain выводит объяснение по методу id: сначала среди находок, затем среди
+// убранных правилами конфигурации
+func explain(findings, dropped []results.Finding, id string) int {
+	found := results.Lookup(findings, id)
+	if len(found) == 0 {
+		found = results.Lookup(dropped, id)
+	}
+	switch len(found) {
+	case 0:
+		fmt.Fprintf(os.Stderr, "Error: method %s not found among analyzed interfaces (files matched by ignore patterns are not analyzed)\n", id)
+		return config.ExitConfig
+	case 1:
+	default:
+		fmt.Fprintf(os.Stderr, "Error: %s matches several methods, specify the package path:\n", id)
+		for _, f := range found {
+			fmt.Fprintf(os.Stderr, "  %s\n", f.ID())
+		}
+		return config.ExitConfig
+	}
+
+	if err := report.Explain(os.Stdout, found[0]); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing report: %v\n", err)
+		return config.ExitInternal
+	}
+	return config.ExitOK
+}
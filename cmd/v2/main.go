@@ -7,50 +7,177 @@ package main
 import (
 	"flag"
 	"fmt"
+	"go/types"
 	"os"
+	"strings"
+	"time"
 
+	"github.com/comerc/unused-interface-methods/pkg/baseline"
+	"github.com/comerc/unused-interface-methods/pkg/changes"
 	"github.com/comerc/unused-interface-methods/pkg/config"
+	"github.com/comerc/unused-interface-methods/pkg/postprocess"
+	"github.com/comerc/unused-interface-methods/pkg/report"
+	"github.com/comerc/unused-interface-methods/pkg/results"
+	"github.com/comerc/unused-interface-methods/pkg/rules"
 	"github.com/comerc/unused-interface-methods/pkg/stage0"
 	"github.com/comerc/unused-interface-methods/pkg/stage1"
 	"github.com/comerc/unused-interface-methods/pkg/stage2"
+	"github.com/comerc/unused-interface-methods/pkg/target"
+	"github.com/comerc/unused-interface-methods/pkg/workspace"
 )
 
 func main() {
+	// Подкоманды init и config работают с конфигурацией, а не с кодом
+	if code, ok := config.RunCommand(os.Args[1:], os.Stdout, os.Stderr); ok {
+		config.OsExit(code)
+		return
+	}
+	// v2 не использует кэш фактов, поэтому подкоманда cache есть только у v1
+	if len(os.Args) > 1 && os.Args[1] == "cache" {
+		fmt.Fprintf(os.Stderr, "Error: the v2 engine does not use the cache; \"cache clean\" is available in v1\n")
+		config.OsExit(config.ExitConfig)
+		return
+	}
+
+	// explain принимает те же флаги, что и разовый запуск
+	args := os.Args[1:]
+	var mode string
+	if len(args) > 0 && args[0] == "explain" {
+		mode, args = args[0], args[1:]
+	}
+
 	var (
 		verbose = flag.Bool("v", false, "Verbose output")
 		help    = flag.Bool("h", false, "Show help")
+		format  = flag.String("format", "text", "Output format: text, json, sarif, checkstyle, junit, github")
+		cfgFile = flag.String("config", "", "Config file path (disables config file discovery)")
+
+		modules   = flag.String("modules", workspace.ModeWork, "Modules analyzed together: v2 supports only a single module (work without go.work, or off)")
+		tags      = flag.String("tags", "", "Not supported: v2 reads all Go files regardless of build constraints")
+		filesFrom = flag.String("files-from", "", "Check interfaces only in Go files listed in the file (- for stdin), one per line or go list -json")
+
+		baselineFile   = flag.String("baseline", "", "Report only unused methods missing from the baseline file")
+		baselineWrite  = flag.String("baseline-write", "", "Write current unused methods to the baseline file and exit")
+		newFromRev     = flag.String("new-from-rev", "", "Report only unused methods introduced since the git revision")
+		newFromPatch   = flag.String("new-from-patch", "", "Report only unused methods introduced by the unified diff file")
+		showSuppressed = flag.Bool("show-suppressed", false, "List unused methods suppressed by directives")
 	)
-	flag.Parse()
+	flag.CommandLine.Parse(args)
 
 	if *help {
 		fmt.Println("Unused Interface Methods - finds unused interface methods")
 		fmt.Println()
 		fmt.Println("Usage:")
-		fmt.Println("  unused-interface-methods [flags] [path]")
+		fmt.Println("  unused-interface-methods [flags] [path | packages | files]")
+		fmt.Println("  unused-interface-methods explain [flags] pkg.Interface.Method [path | packages | files]")
+		fmt.Println("  unused-interface-methods init [-force] [path]")
+		fmt.Println("  unused-interface-methods config validate [path...]")
+		fmt.Println("  unused-interface-methods config schema")
 		fmt.Println()
 		fmt.Println("Flags:")
-		fmt.Println("  -v    Verbose output")
-		fmt.Println("  -h    Show this help")
+		fmt.Println("  -v                    Verbose output")
+		fmt.Println("  -h                    Show this help")
+		fmt.Println("  -format=FORMAT        Output format: text (default), json, sarif, checkstyle, junit, github")
+		fmt.Println("  -config=FILE          Use the config file instead of looking for one")
+		fmt.Println("  -modules=MODE         Only a single module: work (default) fails on a multi-module go.work, off ignores go.work")
+		fmt.Println("  -tags=TAGS            Not supported: use v1 for build tags and build-matrix")
+		fmt.Println("  -files-from=FILE      Check interfaces only in listed Go files (- for stdin, go list -json accepted)")
+		fmt.Println("  -baseline=FILE        Report only unused methods missing from the baseline")
+		fmt.Println("  -baseline-write=FILE  Write current unused methods to the baseline and exit")
+		fmt.Println("  -new-from-rev=REV     Report only unused methods introduced since the git revision")
+		fmt.Println("  -new-from-patch=FILE  Report only unused methods introduced by the patch")
+		fmt.Println("  -show-suppressed      List unused methods suppressed by directives")
 		fmt.Println()
 		fmt.Println("Config file:")
-		fmt.Println("  Automatically looks for .unused-interface-methods.yml")
+		fmt.Println("  Automatically looks for .unused-interface-methods.yml in the analyzed directory,")
+		fmt.Println("  its parents and subdirectories; \"init\" writes an annotated default config")
 		fmt.Println("  Example ignore patterns: \"**/*_test.go\", \"test/**\", \"**/mock/**\"")
 		fmt.Println()
+		fmt.Println("Explain:")
+		fmt.Println("  \"explain\" prints every piece of evidence behind the verdict on one method,")
+		fmt.Println("  including the staticcheck output that kept the method")
+		fmt.Println()
 		fmt.Println("Note: Generic interfaces are detected but not analyzed (warnings will be shown)")
 		config.OsExit(0)
 	}
 
-	// Загрузка конфигурации
-	cfg, err := config.LoadConfig("")
+	// v2 разбирает все Go-файлы без учета ограничений сборки, поэтому теги и
+	// build-matrix не изменили бы результат: вместо молчаливого игнорирования - ошибка
+	if *tags != "" {
+		fmt.Fprintf(os.Stderr, "Error: -tags is not supported by the v2 engine, it reads all Go files regardless of build constraints; use v1\n")
+		config.OsExit(config.ExitConfig)
+	}
+
+	if *newFromRev != "" && *newFromPatch != "" {
+		fmt.Fprintf(os.Stderr, "Error: -new-from-rev and -new-from-patch are mutually exclusive\n")
+		config.OsExit(config.ExitConfig)
+	}
+
+	if mode != "" && (*format != "text" || *baselineWrite != "") {
+		fmt.Fprintf(os.Stderr, "Error: %s supports only the text format and no -baseline-write\n", mode)
+		config.OsExit(config.ExitConfig)
+	}
+
+	reporter, err := report.New(*format, report.Options{Verbose: *verbose, ShowSuppressed: *showSuppressed})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		config.OsExit(config.ExitConfig)
+	}
+
+	// Интерфейсы проверяются в выбранном коде, а использования ищутся во всем модуле,
+	// поэтому для паттернов пакетов и списков файлов загружается весь модуль
+	targetArgs := flag.Args()
+	var method string
+	if mode == "explain" {
+		if len(targetArgs) == 0 {
+			fmt.Fprintf(os.Stderr, "Error: explain requires a method: pkg.Interface.Method\n")
+			config.OsExit(config.ExitConfig)
+			return
+		}
+		method, targetArgs = targetArgs[0], targetArgs[1:]
+	}
+	tgt, err := target.Select(targetArgs, *filesFrom, os.Stdin)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		config.OsExit(config.ExitConfig)
+	}
+	dir := tgt.Root
+
+	// Загрузка конфигурации: файл из -config или файлы из директории анализа, ее родителей и поддиректорий.
+	// Без файлов паттерны путей отсчитываются от корня модуля,
+	// поэтому результат не зависит от директории запуска
+	var cfg *config.Config
+	if *cfgFile != "" {
+		cfg, err = config.LoadFile(*cfgFile)
+	} else {
+		cfg, err = config.Load(dir)
+	}
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
-		config.OsExit(1)
+		config.OsExit(config.ExitConfig)
+	}
+
+	// v2 строит пути пакетов от ближайшего go.mod и не загружает другие модули:
+	// их вызовы не учитывались бы, поэтому несколько модулей - ошибка, а не ложные находки
+	ws, err := workspace.Find(dir, *modules)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		config.OsExit(config.ExitConfig)
+	}
+	ws.Close()
+	if len(ws.Modules) > 1 {
+		var found []string
+		for _, m := range ws.Modules {
+			found = append(found, config.GetRelativePath(m))
+		}
+		fmt.Fprintf(os.Stderr, "Error: the v2 engine analyzes a single module, but %d were found (%s); use v1 or -modules=off\n",
+			len(found), strings.Join(found, ", "))
+		config.OsExit(config.ExitConfig)
 	}
 
-	args := flag.Args()
-	dir := "."
-	if len(args) > 0 {
-		dir = args[0]
+	if cfg.HasBuildMatrix() {
+		fmt.Fprintf(os.Stderr, "Error: build-matrix is not supported by the v2 engine, it reads all Go files regardless of build constraints; use v1\n")
+		config.OsExit(config.ExitConfig)
 	}
 
 	if *verbose {
@@ -61,20 +188,134 @@ func main() {
 	pkgs, err := stage0.LoadProject(cfg, *verbose, dir)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error loading project: %v\n", err)
-		config.OsExit(1)
+		config.OsExit(config.ExitLoad)
 	}
 
 	// Stage 1: Находим все используемые методы интерфейсов
-	usedMethodsByPkg, err := stage1.FindUsedMethods(pkgs, cfg, *verbose)
+	usedMethods, err := stage1.FindUsedMethods(pkgs, cfg, *verbose)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error finding used methods: %v\n", err)
-		config.OsExit(1)
+		config.OsExit(config.ExitInternal)
 	}
 
-	// Stage 2: Проверяем неиспользуемые методы через staticcheck
-	err = stage2.FindUnusedMethods(pkgs, usedMethodsByPkg, cfg, *verbose)
+	// Stage 2: Проверяем остальные методы через staticcheck
+	checkedMethods, err := stage2.FindUnusedMethods(pkgs, usedMethods, cfg, *verbose)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error finding unused methods: %v\n", err)
-		config.OsExit(1)
+		config.OsExit(config.ExitInternal)
+	}
+
+	res := &results.Result{Findings: append(usedMethods, checkedMethods...), LoadErrors: loadErrors(pkgs)}
+	tgt.Filter(res) // остальной код нужен только для поиска использований
+
+	// Директивы из невыбранных файлов не проверяются на устаревание, как и их находки
+	var directives []results.Suppression
+	for _, d := range stage2.CollectSuppressions(pkgs, cfg) {
+		if tgt.Contains(d.Position.File) {
+			directives = append(directives, d)
+		}
+	}
+	dropped, err := postprocess.Apply(res, cfg, directives, rules.NewLookup(typesPackages(pkgs)), time.Now(), os.Stderr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error checking ignore rules: %v\n", err)
+		config.OsExit(config.ExitConfig)
+	}
+
+	// Baseline и фильтр изменений не меняют вердикт, поэтому explain их не применяет
+	if mode == "explain" {
+		config.OsExit(explain(res.Findings, dropped, method))
+		return
+	}
+
+	if *baselineWrite != "" {
+		b := baseline.New(res)
+		if err := b.Write(*baselineWrite); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing baseline: %v\n", err)
+			config.OsExit(config.ExitInternal)
+		}
+		fmt.Fprintf(os.Stderr, "Baseline written to %s: %d entries\n", *baselineWrite, len(b.Entries))
+		config.OsExit(config.ExitOK)
+	}
+
+	if *baselineFile != "" {
+		b, err := baseline.Load(*baselineFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading baseline: %v\n", err)
+			config.OsExit(config.ExitConfig)
+		}
+		b.Apply(res)
+	}
+
+	// Фильтр по изменениям применяется после baseline, иначе отброшенные им находки попали бы в FIXED
+	var changeSet *changes.Set
+	switch {
+	case *newFromRev != "":
+		changeSet, err = changes.FromRev(*newFromRev)
+	case *newFromPatch != "":
+		changeSet, err = changes.FromPatch(*newFromPatch)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading changes: %v\n", err)
+		config.OsExit(config.ExitInternal)
+	}
+	if changeSet != nil {
+		// Метод новый, если он не используется сейчас, но использовался в базовой версии:
+		// базовая версия проходит stage 1 и stage 2 заново
+		overlay, err := changeSet.Base(nil)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error restoring base version: %v\n", err)
+			config.OsExit(config.ExitInternal)
+		}
+		basePkgs, err := stage0.LoadProjectWithOverlay(cfg, *verbose, dir, overlay)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading base version: %v\n", err)
+			config.OsExit(config.ExitLoad)
+		}
+		usedBefore, err := stage1.FindUsedMethods(basePkgs, cfg, *verbose)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error finding used methods: %v\n", err)
+			config.OsExit(config.ExitInternal)
+		}
+		checkedBefore, err := stage2.FindUnusedMethodsWithOverlay(basePkgs, usedBefore, cfg, *verbose, overlay)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error finding unused methods: %v\n", err)
+			config.OsExit(config.ExitInternal)
+		}
+		changeSet.Apply(res, append(usedBefore, checkedBefore...))
+	}
+
+	if err := reporter.Report(os.Stdout, res); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing report: %v\n", err)
+		config.OsExit(config.ExitInternal)
+	}
+
+	// Код возврата определяет порог fail-on: проблемы ниже него не проваливают запуск.
+	// Неполная загрузка важнее находок: результат по остальным пакетам может быть неточным
+	failOn := cfg.FailOnLevel()
+	switch {
+	case res.LoadFails(failOn):
+		config.OsExit(config.ExitLoad)
+	case res.Fails(failOn):
+		config.OsExit(config.ExitFindings)
+	}
+}
+
+// typesPackages возвращает информацию о типах пакетов, прошедших проверку типов
+func typesPackages(pkgs map[string]*stage0.Package) []*types.Package {
+	var typesPkgs []*types.Package
+	for _, pkg := range pkgs {
+		if pkg.Types != nil {
+			typesPkgs = append(typesPkgs, pkg.Types)
+		}
+	}
+	return typesPkgs
+}
+
+// loadErrors собирает ошибки разбора файлов всех пакетов
+func loadErrors(pkgs map[string]*stage0.Package) []results.LoadError {
+	var errs []results.LoadError
+	for _, pkg := range pkgs {
+		errs = append(errs, pkg.Errors...)
 	}
+	return errs
 }
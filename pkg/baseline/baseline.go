@@ -0,0 +1,116 @@
+package baseline
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/comerc/unused-interface-methods/pkg/results"
+)
+
+// Version - версия формата baseline-файла
+const Version = 1
+
+// Baseline содержит отпечатки уже известных неиспользуемых методов.
+// Номера строк не хранятся, поэтому правки соседнего кода не ломают baseline
+type Baseline struct {
+	Version int     `json:"version"`
+	Entries []Entry `json:"entries"`
+}
+
+// Entry - одна запись baseline
+type Entry struct {
+	Package   string `json:"package"`
+	Interface string `json:"interface"`
+	Method    string `json:"method"`
+	Signature string `json:"signature"`
+}
+
+// Fingerprint возвращает отпечаток записи
+func (e Entry) Fingerprint() results.Fingerprint {
+	return results.Fingerprint{
+		PkgPath:   e.Package,
+		Interface: e.Interface,
+		Method:    e.Method,
+		Signature: e.Signature,
+	}
+}
+
+// New строит baseline из неиспользуемых методов результата
+func New(res *results.Result) *Baseline {
+	b := &Baseline{Version: Version, Entries: []Entry{}}
+	seen := make(map[results.Fingerprint]bool)
+	for _, f := range res.Findings {
+		fp := f.Fingerprint()
+		if !f.IsReported() || seen[fp] {
+			continue
+		}
+		seen[fp] = true
+		b.Entries = append(b.Entries, Entry{
+			Package:   fp.PkgPath,
+			Interface: fp.Interface,
+			Method:    fp.Method,
+			Signature: fp.Signature,
+		})
+	}
+	// Сортировка по отпечатку делает файл стабильным для diff в ревью
+	sort.Slice(b.Entries, func(i, j int) bool {
+		return b.Entries[i].Fingerprint().String() < b.Entries[j].Fingerprint().String()
+	})
+	return b
+}
+
+// Load читает baseline из файла
+func Load(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var b Baseline
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline %s: %w", path, err)
+	}
+	if b.Version != Version {
+		return nil, fmt.Errorf("unsupported baseline version %d in %s (expected %d)", b.Version, path, Version)
+	}
+	return &b, nil
+}
+
+// Write записывает baseline в файл
+func (b *Baseline) Write(path string) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// Apply скрывает неиспользуемые методы, записанные в baseline,
+// и отмечает записи, которые больше не воспроизводятся
+func (b *Baseline) Apply(res *results.Result) {
+	known := make(map[results.Fingerprint]bool, len(b.Entries))
+	for _, e := range b.Entries {
+		known[e.Fingerprint()] = true
+	}
+
+	found := make(map[results.Fingerprint]bool)
+	findings := res.Findings[:0]
+	for _, f := range res.Findings {
+		fp := f.Fingerprint()
+		if f.IsReported() && known[fp] {
+			found[fp] = true
+			res.Baselined = append(res.Baselined, f)
+			continue
+		}
+		findings = append(findings, f)
+	}
+	res.Findings = findings
+
+	for _, e := range b.Entries {
+		if fp := e.Fingerprint(); !found[fp] {
+			res.FixedBaseline = append(res.FixedBaseline, fp)
+		}
+	}
+}
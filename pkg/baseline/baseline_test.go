@@ -0,0 +1,102 @@
+package baseline
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/comerc/unused-interface-methods/pkg/config"
+	"github.com/comerc/unused-interface-methods/pkg/linter"
+	"github.com/comerc/unused-interface-methods/pkg/results"
+)
+
+// analyze запускает линтер на test/data
+func analyze(t *testing.T) *results.Result {
+	l := linter.New(config.DefaultConfig(), false)
+	l.SetLogOutput(io.Discard)
+	assert.NoError(t, l.LoadPackages("../../test/data"))
+	l.ExtractInterfaceMethods()
+	return l.FindUnusedMethods()
+}
+
+func TestWriteLoadApply(t *testing.T) {
+	res := analyze(t)
+	unused := res.Summary().Unused
+	assert.Greater(t, unused, 0)
+
+	b := New(res)
+	assert.Len(t, b.Entries, unused)
+
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	assert.NoError(t, b.Write(path))
+	loaded, err := Load(path)
+	assert.NoError(t, err)
+	assert.Equal(t, b, loaded)
+
+	// Повторный запуск: все известные находки скрыты, исправленных нет
+	res = analyze(t)
+	loaded.Apply(res)
+	assert.Equal(t, 0, res.Summary().Unused)
+	assert.Len(t, res.Baselined, unused)
+	assert.Empty(t, res.FixedBaseline)
+
+	// Сдвиг строк не влияет на сопоставление
+	res = analyze(t)
+	for i := range res.Findings {
+		res.Findings[i].Range.Start.Line += 100
+	}
+	loaded.Apply(res)
+	assert.Equal(t, 0, res.Summary().Unused)
+}
+
+func TestApplyNewAndFixed(t *testing.T) {
+	res := analyze(t)
+	b := New(res)
+
+	// Первая запись "исправлена", вторая удалена из baseline и стала новой
+	fixed := b.Entries[0]
+	added := b.Entries[1]
+	b.Entries = b.Entries[2:]
+
+	res = analyze(t)
+	var kept []results.Finding
+	for _, f := range res.Findings {
+		if f.Fingerprint() != fixed.Fingerprint() {
+			kept = append(kept, f)
+		}
+	}
+	res.Findings = kept
+	b.Entries = append(b.Entries, fixed)
+
+	b.Apply(res)
+	assert.Equal(t, []results.Fingerprint{fixed.Fingerprint()}, res.FixedBaseline)
+
+	var unused []results.Fingerprint
+	for _, f := range res.Findings {
+		if f.IsReported() {
+			unused = append(unused, f.Fingerprint())
+		}
+	}
+	assert.Equal(t, []results.Fingerprint{added.Fingerprint()}, unused)
+	assert.Equal(t, 1, res.Summary().FixedBaseline)
+}
+
+func TestLoadErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := Load(filepath.Join(dir, "missing.json"))
+	assert.True(t, os.IsNotExist(err))
+
+	invalid := filepath.Join(dir, "invalid.json")
+	assert.NoError(t, os.WriteFile(invalid, []byte("{"), 0o644))
+	_, err = Load(invalid)
+	assert.Error(t, err)
+
+	future := filepath.Join(dir, "future.json")
+	assert.NoError(t, os.WriteFile(future, []byte(`{"version": 2, "entries": []}`), 0o644))
+	_, err = Load(future)
+	assert.ErrorContains(t, err, "unsupported baseline version 2")
+}
@@ -0,0 +1,290 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+
+	"github.com/comerc/unused-interface-methods/pkg/config"
+)
+
+// Off - значение -cache-dir, отключающее кэш
+const Off = "off"
+
+// Cache хранит результаты анализа пакетов в файлах по ключу содержимого.
+// Запись атомарна, поэтому кэш могут использовать несколько запусков одновременно
+type Cache struct {
+	dir string
+	mem map[string][]byte // значения кэша без директории
+}
+
+// DefaultDir возвращает директорию кэша по умолчанию внутри os.UserCacheDir()
+func DefaultDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "unused-interface-methods"), nil
+}
+
+// Open открывает кэш в директории dir, создавая ее. Пустая dir - директория по умолчанию,
+// Off - кэш отключен (nil без ошибки)
+func Open(dir string) (*Cache, error) {
+	if dir == Off {
+		return nil, nil
+	}
+	if dir == "" {
+		var err error
+		if dir, err = DefaultDir(); err != nil {
+			return nil, err
+		}
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &Cache{dir: dir}, nil
+}
+
+// Memory возвращает кэш в памяти: он живет, пока работает процесс, например в режиме watch
+func Memory() *Cache {
+	return &Cache{mem: make(map[string][]byte)}
+}
+
+// Dir возвращает директорию кэша; для кэша в памяти - "memory"
+func (c *Cache) Dir() string {
+	if c.mem != nil {
+		return "memory"
+	}
+	return c.dir
+}
+
+// Get читает значение по ключу в v. false - значения нет или оно повреждено
+func (c *Cache) Get(key string, v any) bool {
+	data, ok := c.mem[key]
+	if c.mem == nil {
+		var err error
+		data, err = os.ReadFile(c.path(key))
+		ok = err == nil
+	}
+	if !ok {
+		return false
+	}
+	return json.Unmarshal(data, v) == nil
+}
+
+// Put сохраняет значение по ключу
+func (c *Cache) Put(key string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if c.mem != nil {
+		c.mem[key] = data
+		return nil
+	}
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	// Временный файл в той же директории переименовывается атомарно:
+	// параллельный запуск не прочитает недописанное значение
+	tmp, err := os.CreateTemp(filepath.Dir(path), key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// path возвращает файл значения; первые символы ключа разбивают кэш на поддиректории
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.dir, key[:2], key+".json")
+}
+
+// Clean удаляет кэш в директории dir; пустая dir - директория по умолчанию.
+// Удаляются только поддиректории значений: -cache-dir может указывать на общую директорию
+func Clean(dir string) error {
+	if dir == "" {
+		var err error
+		if dir, err = DefaultDir(); err != nil {
+			return err
+		}
+	}
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() || !isShard(entry.Name()) {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(dir, entry.Name())); err != nil {
+			return err
+		}
+	}
+	os.Remove(dir) // удаляется, только если в ней не осталось чужих файлов
+	return nil
+}
+
+// isShard сообщает, что имя - поддиректория значений: два шестнадцатеричных символа
+func isShard(name string) bool {
+	if len(name) != 2 {
+		return false
+	}
+	_, err := hex.DecodeString(name)
+	return err == nil
+}
+
+// Hash собирает ключ кэша из частей. Части разделяются, чтобы "ab"+"c" и "a"+"bc"
+// давали разные ключи
+type Hash struct {
+	h hash.Hash
+}
+
+// NewHash возвращает ключ, уже учитывающий версию инструмента и формат данных
+func NewHash(format string) *Hash {
+	h := &Hash{h: sha256.New()}
+	h.Add("format", format)
+	h.Add("tool", ToolVersion())
+	return h
+}
+
+// Add добавляет к ключу строки
+func (h *Hash) Add(parts ...string) {
+	for _, part := range parts {
+		fmt.Fprintf(h.h, "%d:%s;", len(part), part)
+	}
+}
+
+// AddFile добавляет к ключу путь и содержимое файла
+func (h *Hash) AddFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	h.Add(path)
+	fmt.Fprintf(h.h, "%d:", info.Size())
+	_, err = io.Copy(h.h, f)
+	return err
+}
+
+// AddData добавляет к ключу путь и содержимое файла, которое еще не записано на диск:
+// ключ совпадает с ключом AddFile для того же содержимого
+func (h *Hash) AddData(path string, data []byte) {
+	h.Add(path)
+	fmt.Fprintf(h.h, "%d:", len(data))
+	h.h.Write(data)
+}
+
+// AddStat добавляет к ключу путь, размер и время изменения файла: дешевле содержимого
+// для файлов, которые не меняются на месте
+func (h *Hash) AddStat(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	h.Add(path, fmt.Sprint(info.Size(), info.ModTime().UnixNano()))
+	return nil
+}
+
+// Sum возвращает ключ в шестнадцатеричном виде
+func (h *Hash) Sum() string {
+	return hex.EncodeToString(h.h.Sum(nil))
+}
+
+// ToolVersion описывает сборку инструмента: версия модуля, ревизия и версия Go.
+// Для сборки из рабочего дерева без версии добавляются размер и время изменения
+// исполняемого файла, иначе кэш пережил бы изменение кода анализа
+func ToolVersion() string {
+	return toolVersion()
+}
+
+// toolVersion вычисляет версию один раз за запуск: ключ строится для каждого пакета
+var toolVersion = sync.OnceValue(func() string {
+	parts := []string{runtime.Version()}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		parts = append(parts, info.Main.Version)
+		for _, s := range info.Settings {
+			if s.Key == "vcs.revision" || s.Key == "vcs.modified" {
+				parts = append(parts, s.Value)
+			}
+		}
+		if info.Main.Version != "" && info.Main.Version != "(devel)" {
+			return strings.Join(parts, " ")
+		}
+	}
+	if exe, err := os.Executable(); err == nil {
+		if info, err := os.Stat(exe); err == nil {
+			parts = append(parts, fmt.Sprint(info.Size(), info.ModTime().UnixNano()))
+		}
+	}
+	return strings.Join(parts, " ")
+})
+
+// RunCommand выполняет подкоманду работы с кэшем:
+//
+//	cache clean [-cache-dir DIR]  удалить кэш
+//
+// handled = false - аргументы не относятся к подкоманде
+func RunCommand(args []string, stdout, stderr io.Writer) (code int, handled bool) {
+	if len(args) == 0 || args[0] != "cache" {
+		return 0, false
+	}
+	if len(args) < 2 || args[1] != "clean" {
+		fmt.Fprintln(stderr, "Usage: unused-interface-methods cache clean [-cache-dir DIR]")
+		return config.ExitConfig, true
+	}
+
+	flags := flag.NewFlagSet("cache clean", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	dir := flags.String("cache-dir", "", "Cache directory (default: user cache directory)")
+	if err := flags.Parse(args[2:]); err != nil {
+		return config.ExitConfig, true
+	}
+	if *dir == Off {
+		return config.ExitOK, true
+	}
+	if *dir == "" {
+		var err error
+		if *dir, err = DefaultDir(); err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return config.ExitInternal, true
+		}
+	}
+	if err := Clean(*dir); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return config.ExitInternal, true
+	}
+	fmt.Fprintf(stdout, "Removed %s\n", *dir)
+	return config.ExitOK, true
+}
@@ -0,0 +1,108 @@
+package cache
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetPut(t *testing.T) {
+	c, err := Open(filepath.Join(t.TempDir(), "cache"))
+	assert.NoError(t, err)
+
+	type value struct{ Names []string }
+	key := NewHash("test").Sum()
+
+	var got value
+	assert.False(t, c.Get(key, &got), "пустой кэш не должен возвращать значения")
+
+	assert.NoError(t, c.Put(key, value{Names: []string{"a", "b"}}))
+	assert.True(t, c.Get(key, &got))
+	assert.Equal(t, value{Names: []string{"a", "b"}}, got)
+
+	// Поврежденное значение считается отсутствующим
+	assert.NoError(t, os.WriteFile(c.path(key), []byte("{"), 0644))
+	assert.False(t, c.Get(key, &got))
+}
+
+func TestMemory(t *testing.T) {
+	c := Memory()
+	key := NewHash("test").Sum()
+	var got []string
+	assert.False(t, c.Get(key, &got))
+	assert.NoError(t, c.Put(key, []string{"a"}))
+	assert.True(t, c.Get(key, &got))
+	assert.Equal(t, []string{"a"}, got)
+	assert.Equal(t, "memory", c.Dir())
+}
+
+func TestOpenOff(t *testing.T) {
+	c, err := Open(Off)
+	assert.NoError(t, err)
+	assert.Nil(t, c)
+}
+
+func TestHash(t *testing.T) {
+	sum := func(parts ...string) string {
+		h := NewHash("test")
+		h.Add(parts...)
+		return h.Sum()
+	}
+	assert.Equal(t, sum("a", "bc"), sum("a", "bc"))
+	assert.NotEqual(t, sum("ab", "c"), sum("a", "bc"), "границы частей должны влиять на ключ")
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "a.go")
+	assert.NoError(t, os.WriteFile(file, []byte("package a\n"), 0644))
+	fileSum := func() string {
+		h := NewHash("test")
+		assert.NoError(t, h.AddFile(file))
+		return h.Sum()
+	}
+	before := fileSum()
+	assert.NoError(t, os.WriteFile(file, []byte("package b\n"), 0644))
+	assert.NotEqual(t, before, fileSum(), "ключ должен зависеть от содержимого файла")
+
+	data := NewHash("test")
+	data.AddData(file, []byte("package b\n"))
+	assert.Equal(t, fileSum(), data.Sum(), "содержимое из памяти дает тот же ключ, что и файл")
+}
+
+func TestClean(t *testing.T) {
+	dir := t.TempDir()
+	c, err := Open(dir)
+	assert.NoError(t, err)
+	key := NewHash("test").Sum()
+	assert.NoError(t, c.Put(key, "value"))
+
+	// Чужие файлы в директории кэша не удаляются
+	foreign := filepath.Join(dir, "notes.txt")
+	assert.NoError(t, os.WriteFile(foreign, []byte("keep"), 0644))
+
+	var stdout, stderr bytes.Buffer
+	code, ok := RunCommand([]string{"cache", "clean", "-cache-dir", dir}, &stdout, &stderr)
+	assert.True(t, ok)
+	assert.Equal(t, 0, code, stderr.String())
+	assert.Contains(t, stdout.String(), "Removed "+dir)
+
+	var got string
+	assert.False(t, c.Get(key, &got))
+	assert.FileExists(t, foreign)
+
+	// Отсутствующая директория - не ошибка
+	assert.NoError(t, Clean(filepath.Join(dir, "missing")))
+}
+
+func TestRunCommand(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	_, ok := RunCommand([]string{"./cache"}, &stdout, &stderr)
+	assert.False(t, ok, "пути анализа не относятся к подкоманде")
+
+	code, ok := RunCommand([]string{"cache"}, &stdout, &stderr)
+	assert.True(t, ok)
+	assert.NotEqual(t, 0, code)
+	assert.Contains(t, stderr.String(), "Usage:")
+}
@@ -0,0 +1,400 @@
+package changes
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/comerc/unused-interface-methods/pkg/results"
+)
+
+// Set содержит изменения относительно базовой версии: добавленные строки новых версий
+// файлов и фрагменты diff, по которым восстанавливается базовая версия
+type Set struct {
+	added map[string]map[int]bool // абсолютный путь -> номера добавленных строк
+	files map[string]*fileDiff    // абсолютный путь новой версии (старой для удаленных) -> изменения
+}
+
+// fileDiff - изменения одного файла
+type fileDiff struct {
+	created bool // файла нет в базовой версии
+	deleted bool // файла нет в новой версии
+	hunks   []hunk
+}
+
+// hunk - фрагмент diff: строки новой версии с newStart, newCount штук, заменили removed.
+// При newCount = 0 строки удалены после строки newStart
+type hunk struct {
+	newStart, newCount int
+	removed            []string
+}
+
+// FromRev строит изменения рабочего дерева относительно ревизии git. Diff строится
+// от корня репозитория, поэтому изменения вне текущей директории тоже учитываются.
+// Неотслеживаемые файлы, кроме игнорируемых git, считаются созданными целиком
+func FromRev(rev string) (*Set, error) {
+	top, err := git(".", "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+	// Переименование - удаление и добавление: базовая версия восстанавливается по старому пути
+	cmd := exec.Command("git", "diff", "--no-color", "--no-ext-diff", "--no-renames", "--unified=0", rev, "--")
+	cmd.Dir = top
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git diff %s: %w: %s", rev, err, strings.TrimSpace(stderr.String()))
+	}
+	s, err := Parse(bytes.NewReader(out), top)
+	if err != nil {
+		return nil, err
+	}
+
+	untracked, err := git(top, "ls-files", "--others", "--exclude-standard", "-z")
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range strings.Split(untracked, "\x00") {
+		if name == "" {
+			continue
+		}
+		if err := s.addCreated(filepath.Join(top, filepath.FromSlash(name))); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// addCreated добавляет файл, которого нет в базовой версии: все его строки добавлены
+func (s *Set) addCreated(file string) error {
+	if _, ok := s.files[file]; ok {
+		return nil
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	lines := bytes.Count(data, []byte("\n"))
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		lines++
+	}
+	added := make(map[int]bool, lines)
+	for line := 1; line <= lines; line++ {
+		added[line] = true
+	}
+	s.added[file] = added
+	s.files[file] = &fileDiff{created: true}
+	return nil
+}
+
+// Checkout извлекает ревизию rev репозитория, содержащего dir, во временное рабочее
+// дерево git и возвращает путь, соответствующий dir в этом дереве. remove удаляет дерево
+func Checkout(dir, rev string) (path string, remove func(), err error) {
+	top, err := git(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", nil, err
+	}
+	prefix, err := git(dir, "rev-parse", "--show-prefix")
+	if err != nil {
+		return "", nil, err
+	}
+	tmp, err := os.MkdirTemp("", "unused-interface-methods-")
+	if err != nil {
+		return "", nil, err
+	}
+	if _, err := git(top, "worktree", "add", "--detach", "--quiet", tmp, rev); err != nil {
+		os.RemoveAll(tmp)
+		return "", nil, err
+	}
+	remove = func() {
+		git(top, "worktree", "remove", "--force", tmp)
+		os.RemoveAll(tmp)
+		git(top, "worktree", "prune")
+	}
+
+	path = filepath.Join(tmp, filepath.FromSlash(prefix))
+	if _, err := os.Stat(path); err != nil {
+		remove()
+		return "", nil, fmt.Errorf("%s does not exist at %s", prefix, rev)
+	}
+	return path, remove, nil
+}
+
+// git выполняет команду git в директории dir и возвращает ее вывод без пробелов по краям
+func git(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// FromPatch читает изменения из файла в формате unified diff. Пути в патче считаются
+// относительно корня репозитория git, как их записывает git diff; вне репозитория -
+// относительно текущей директории
+func FromPatch(path string) (*Set, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	baseDir, err := git(".", "rev-parse", "--show-toplevel")
+	if err != nil {
+		if baseDir, err = os.Getwd(); err != nil {
+			return nil, err
+		}
+	}
+	return Parse(file, baseDir)
+}
+
+// Parse разбирает unified diff; относительные пути разрешаются от baseDir
+func Parse(r io.Reader, baseDir string) (*Set, error) {
+	s := &Set{
+		added: make(map[string]map[int]bool),
+		files: make(map[string]*fileDiff),
+	}
+
+	var (
+		oldFile, newFile     string
+		newLine              int
+		oldRemain, newRemain int
+		inHunk               bool
+		current              *hunk
+	)
+
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for sc.Scan() {
+		line := sc.Text()
+
+		if inHunk && (oldRemain > 0 || newRemain > 0) {
+			switch {
+			case strings.HasPrefix(line, "+"):
+				if newFile != "" {
+					if s.added[newFile] == nil {
+						s.added[newFile] = make(map[int]bool)
+					}
+					s.added[newFile][newLine] = true
+				}
+				newLine++
+				newRemain--
+			case strings.HasPrefix(line, "-"):
+				current.removed = append(current.removed, line[1:])
+				oldRemain--
+			case strings.HasPrefix(line, `\`):
+				// "\ No newline at end of file"
+			default:
+				newLine++
+				oldRemain--
+				newRemain--
+			}
+			continue
+		}
+		inHunk = false
+
+		switch {
+		case strings.HasPrefix(line, "--- "):
+			oldFile = diffPath(line[4:], "a/", baseDir)
+		case strings.HasPrefix(line, "+++ "):
+			newFile = diffPath(line[4:], "b/", baseDir)
+		case strings.HasPrefix(line, "@@ "):
+			var err error
+			_, oldRemain, newLine, newRemain, err = parseHunkHeader(line)
+			if err != nil {
+				return nil, err
+			}
+			inHunk = true
+
+			file := newFile
+			if file == "" {
+				file = oldFile
+			}
+			d := s.files[file]
+			if d == nil {
+				d = &fileDiff{created: oldFile == "", deleted: newFile == ""}
+				s.files[file] = d
+			}
+			d.hunks = append(d.hunks, hunk{newStart: newLine, newCount: newRemain})
+			current = &d.hunks[len(d.hunks)-1]
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Base восстанавливает базовые версии измененных Go-файлов для overlay загрузчика
+// пакетов. Новые версии берутся из current или с диска. От файла, которого не было
+// в базовой версии, остается только объявление пакета с комментариями перед ним
+func (s *Set) Base(current map[string][]byte) (map[string][]byte, error) {
+	base := make(map[string][]byte)
+	for file, d := range s.files {
+		if filepath.Ext(file) != ".go" {
+			continue
+		}
+		if d.deleted {
+			var removed []string
+			for _, h := range d.hunks {
+				removed = append(removed, h.removed...)
+			}
+			base[file] = []byte(strings.Join(removed, "\n") + "\n")
+			continue
+		}
+
+		data, ok := current[file]
+		if !ok {
+			var err error
+			if data, err = os.ReadFile(file); err != nil {
+				return nil, err
+			}
+		}
+		if d.created {
+			base[file] = packageClause(file, data)
+			continue
+		}
+		restored, err := d.revert(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		base[file] = restored
+	}
+	return base, nil
+}
+
+// revert откатывает фрагменты diff в новой версии файла; фрагменты идут по порядку строк
+func (d *fileDiff) revert(data []byte) ([]byte, error) {
+	lines := strings.SplitAfter(string(data), "\n")
+	var restored []string
+	next := 0 // первая строка новой версии, еще не перенесенная в базовую (с нуля)
+	for _, h := range d.hunks {
+		start := h.newStart - 1
+		if h.newCount == 0 {
+			start = h.newStart
+		}
+		if start < next || start+h.newCount > len(lines) {
+			return nil, fmt.Errorf("diff does not match the file")
+		}
+		restored = append(restored, lines[next:start]...)
+		for _, line := range h.removed {
+			restored = append(restored, line+"\n")
+		}
+		next = start + h.newCount
+	}
+	restored = append(restored, lines[next:]...)
+	return []byte(strings.Join(restored, "")), nil
+}
+
+// packageClause возвращает начало файла до имени пакета включительно: комментарии
+// и ограничения сборки сохраняются, объявления - нет
+func packageClause(filename string, data []byte) []byte {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, data, parser.PackageClauseOnly|parser.ParseComments)
+	if err != nil || file.Name == nil {
+		return data
+	}
+	end := fset.Position(file.Name.End()).Offset
+	return append(data[:end:end], '\n')
+}
+
+// Apply оставляет только новые неиспользуемые методы: объявленные в измененных строках
+// или использованные в базовой версии. base - находки анализа базовой версии;
+// без них новыми считаются только измененные объявления. Используемые методы не затрагиваются
+func (s *Set) Apply(res *results.Result, base []results.Finding) {
+	usedBefore := make(map[string]bool)
+	for _, f := range base {
+		if f.Verdict == results.VerdictUsed {
+			usedBefore[f.ID()] = true
+		}
+	}
+
+	findings := res.Findings[:0]
+	for _, f := range res.Findings {
+		if f.IsReported() && !s.changed(f.Range.Start) && !usedBefore[f.ID()] {
+			continue
+		}
+		findings = append(findings, f)
+	}
+	res.Findings = findings
+
+	warnings := res.GenericWarnings[:0]
+	for _, w := range res.GenericWarnings {
+		if s.changed(w.Position) {
+			warnings = append(warnings, w)
+		}
+	}
+	res.GenericWarnings = warnings
+}
+
+// changed сообщает, попадает ли позиция в добавленные строки
+func (s *Set) changed(pos results.Position) bool {
+	if pos.File == "" {
+		return false
+	}
+	file, err := filepath.Abs(pos.File)
+	if err != nil {
+		return false
+	}
+	return s.added[file][pos.Line]
+}
+
+// diffPath извлекает путь из заголовка ---/+++; /dev/null дает пустую строку
+func diffPath(header, prefix, baseDir string) string {
+	// Утилита diff добавляет к имени файла дату через табуляцию
+	if i := strings.IndexByte(header, '\t'); i >= 0 {
+		header = header[:i]
+	}
+	if header == "/dev/null" {
+		return ""
+	}
+	if unquoted, err := strconv.Unquote(header); err == nil {
+		header = unquoted
+	}
+	header = strings.TrimPrefix(header, prefix)
+	if filepath.IsAbs(header) {
+		return filepath.Clean(header)
+	}
+	return filepath.Join(baseDir, filepath.FromSlash(header))
+}
+
+// parseHunkHeader разбирает заголовок "@@ -a,b +c,d @@"
+func parseHunkHeader(line string) (oldStart, oldCount, newStart, newCount int, err error) {
+	fields := strings.Fields(line)
+	if len(fields) < 3 || !strings.HasPrefix(fields[1], "-") || !strings.HasPrefix(fields[2], "+") {
+		return 0, 0, 0, 0, fmt.Errorf("invalid hunk header: %q", line)
+	}
+	if oldStart, oldCount, err = parseHunkRange(fields[1][1:]); err != nil {
+		return 0, 0, 0, 0, fmt.Errorf("invalid hunk header: %q", line)
+	}
+	if newStart, newCount, err = parseHunkRange(fields[2][1:]); err != nil {
+		return 0, 0, 0, 0, fmt.Errorf("invalid hunk header: %q", line)
+	}
+	return oldStart, oldCount, newStart, newCount, nil
+}
+
+// parseHunkRange разбирает "start,count"; count по умолчанию равен 1
+func parseHunkRange(s string) (start, count int, err error) {
+	count = 1
+	if i := strings.IndexByte(s, ','); i >= 0 {
+		if count, err = strconv.Atoi(s[i+1:]); err != nil {
+			return 0, 0, err
+		}
+		s = s[:i]
+	}
+	start, err = strconv.Atoi(s)
+	return start, count, err
+}
@@ -0,0 +1,237 @@
+package changes
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/comerc/unused-interface-methods/pkg/config"
+	"github.com/comerc/unused-interface-methods/pkg/linter"
+	"github.com/comerc/unused-interface-methods/pkg/results"
+)
+
+// analyze запускает линтер на test/data
+func analyze(t *testing.T) *results.Result {
+	return analyzeOverlay(t, nil)
+}
+
+// analyzeOverlay запускает линтер на test/data с содержимым файлов из overlay
+func analyzeOverlay(t *testing.T, overlay map[string][]byte) *results.Result {
+	l := linter.New(config.DefaultConfig(), false)
+	l.SetLogOutput(io.Discard)
+	l.SetOverlay(overlay)
+	assert.NoError(t, l.LoadPackages("../../test/data"))
+	l.ExtractInterfaceMethods()
+	return l.FindUnusedMethods()
+}
+
+// unusedIDs возвращает имена выводимых неиспользуемых методов
+func unusedIDs(res *results.Result) []string {
+	var ids []string
+	for _, f := range res.Findings {
+		if f.IsReported() {
+			ids = append(ids, f.Interface+"."+f.Method)
+		}
+	}
+	return ids
+}
+
+func repoRoot(t *testing.T) string {
+	root, err := filepath.Abs("../..")
+	assert.NoError(t, err)
+	return root
+}
+
+func TestApplyChangedDeclaration(t *testing.T) {
+	// Объявление AnotherReader.CustomRead (строка 140) добавлено в diff
+	patch := `diff --git a/test/data/interfaces.go b/test/data/interfaces.go
+index 1111111..2222222 100644
+--- a/test/data/interfaces.go
++++ b/test/data/interfaces.go
+@@ -139,0 +140 @@ type AnotherReader interface {
++	CustomRead(data []byte) error // не используется, но имеет такое же имя как Reader.CustomRead
+`
+	set, err := Parse(strings.NewReader(patch), repoRoot(t))
+	assert.NoError(t, err)
+
+	res := analyze(t)
+	used := res.Summary().Used
+	set.Apply(res, nil)
+	assert.Equal(t, []string{"AnotherReader.CustomRead"}, unusedIDs(res))
+	assert.Equal(t, used, res.Summary().Used, "used methods must be kept")
+	assert.Empty(t, res.GenericWarnings)
+}
+
+func TestApplyRemovedLastCall(t *testing.T) {
+	// Строка интерфейса не менялась, но удален последний вызов Method1
+	patch := `diff --git a/test/data/interfaces.go b/test/data/interfaces.go
+--- a/test/data/interfaces.go
++++ b/test/data/interfaces.go
+@@ -300,2 +299,0 @@ func main() {
+-	var m MultiMethodInterface = &TestStruct{}
+-	m.Method1()
+`
+	set, err := Parse(strings.NewReader(patch), repoRoot(t))
+	assert.NoError(t, err)
+
+	// Метод новый: в базовой версии с удаленными строками он использовался
+	base, err := set.Base(nil)
+	assert.NoError(t, err)
+	before := analyzeOverlay(t, base)
+
+	res := analyze(t)
+	set.Apply(res, before.Findings)
+	assert.Equal(t, []string{"MultiMethodInterface.Method1"}, unusedIDs(res))
+}
+
+func TestApplyEditedCall(t *testing.T) {
+	// Измененный вызов Reader.CustomRead не делает новым AnotherReader.CustomRead с тем же именем
+	patch := `diff --git a/test/data/interfaces.go b/test/data/interfaces.go
+--- a/test/data/interfaces.go
++++ b/test/data/interfaces.go
+@@ -343 +343 @@ func (cs *ComplexService) TypeAssertions() {
+-		r.CustomRead()
++		r.CustomRead() // CustomRead используется через type assertion
+`
+	set, err := Parse(strings.NewReader(patch), repoRoot(t))
+	assert.NoError(t, err)
+
+	base, err := set.Base(nil)
+	assert.NoError(t, err)
+	before := analyzeOverlay(t, base)
+
+	res := analyze(t)
+	assert.Contains(t, unusedIDs(res), "AnotherReader.CustomRead")
+	set.Apply(res, before.Findings)
+	assert.Empty(t, unusedIDs(res))
+}
+
+func TestBase(t *testing.T) {
+	dir := t.TempDir()
+	current := map[string][]byte{
+		filepath.Join(dir, "edit.go"): []byte("package p\n\nfunc A() {}\n\nfunc C() {}\n"),
+		filepath.Join(dir, "new.go"):  []byte("//go:build linux\n\npackage p\n\nfunc N() {}\n"),
+	}
+	patch := `--- a/edit.go
++++ b/edit.go
+@@ -3,0 +4 @@
++
+@@ -4 +5 @@
+-func B() {}
++func C() {}
+--- /dev/null
++++ b/new.go
+@@ -0,0 +1,5 @@
++//go:build linux
++
++package p
++
++func N() {}
+--- a/old.go
++++ /dev/null
+@@ -1,2 +0,0 @@
+-package p
+-func D() {}
+--- a/notes.txt
++++ b/notes.txt
+@@ -1 +1 @@
+-a
++b
+`
+	set, err := Parse(strings.NewReader(patch), dir)
+	assert.NoError(t, err)
+
+	base, err := set.Base(current)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]byte{
+		filepath.Join(dir, "edit.go"): []byte("package p\n\nfunc A() {}\nfunc B() {}\n"),
+		filepath.Join(dir, "new.go"):  []byte("//go:build linux\n\npackage p\n"),
+		filepath.Join(dir, "old.go"):  []byte("package p\nfunc D() {}\n"),
+	}, base)
+
+	// Файл не соответствует diff
+	current[filepath.Join(dir, "edit.go")] = []byte("package p\n")
+	_, err = set.Base(current)
+	assert.ErrorContains(t, err, "diff does not match the file")
+}
+
+func TestFromPatch(t *testing.T) {
+	// Патч в формате утилиты diff: без префиксов a/ b/ и с датой после табуляции
+	patch := "--- test/data/generics.go\t2025-01-01 00:00:00\n" +
+		"+++ test/data/generics.go\t2025-01-02 00:00:00\n" +
+		"@@ -62,0 +63,2 @@\n" +
+		"+type Repository[T any] interface {\n" +
+		"+\tSave(item T) error\n"
+	path := filepath.Join(t.TempDir(), "changes.patch")
+	assert.NoError(t, os.WriteFile(path, []byte(patch), 0o644))
+
+	res := analyze(t)
+
+	// Пути в патче разрешаются от корня репозитория, а не от текущей директории pkg/changes
+	set, err := FromPatch(path)
+	assert.NoError(t, err)
+
+	set.Apply(res, nil)
+	assert.Empty(t, unusedIDs(res))
+	if assert.Len(t, res.GenericWarnings, 1) {
+		assert.Equal(t, "Repository", res.GenericWarnings[0].Interface)
+	}
+}
+
+func TestFromRev(t *testing.T) {
+	dir := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		_, err := git(dir, args...)
+		assert.NoError(t, err)
+	}
+	write := func(name, content string) {
+		t.Helper()
+		assert.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0o755))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+	run("init", "--quiet")
+	write(".gitignore", "build/\n")
+	write("p/old.go", "package p\n\ntype A interface {\n\tM()\n}\n")
+	run("add", ".")
+	run("-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "-m", "base")
+
+	write("p/old.go", "package p\n\ntype A interface {\n\tM()\n\tN()\n}\n")
+	write("p/sub/new.go", "package sub\n\ntype B interface {\n\tM()\n}")
+	write("build/gen.go", "package build\n")
+
+	// Изменения строятся от корня репозитория из любой его директории
+	t.Chdir(filepath.Join(dir, "p"))
+	set, err := FromRev("HEAD")
+	assert.NoError(t, err)
+
+	root, err := filepath.EvalSymlinks(dir)
+	assert.NoError(t, err)
+	top, err := git(dir, "rev-parse", "--show-toplevel")
+	assert.NoError(t, err)
+	assert.Equal(t, root, top)
+
+	assert.Equal(t, map[int]bool{5: true}, set.added[filepath.Join(top, "p", "old.go")])
+
+	// Неотслеживаемый файл создан целиком, игнорируемый git - не учитывается
+	created := filepath.Join(top, "p", "sub", "new.go")
+	assert.Equal(t, map[int]bool{1: true, 2: true, 3: true, 4: true, 5: true}, set.added[created])
+	assert.NotContains(t, set.files, filepath.Join(top, "build", "gen.go"))
+
+	base, err := set.Base(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "package sub\n", string(base[created]))
+	assert.Equal(t, "package p\n\ntype A interface {\n\tM()\n}\n", string(base[filepath.Join(top, "p", "old.go")]))
+}
+
+func TestParseErrors(t *testing.T) {
+	_, err := Parse(strings.NewReader("--- a/x.go\n+++ b/x.go\n@@ -a +b @@\n"), "/")
+	assert.ErrorContains(t, err, "invalid hunk header")
+
+	_, err = FromRev("no-such-revision-for-tests")
+	assert.ErrorContains(t, err, "git diff no-such-revision-for-tests")
+}
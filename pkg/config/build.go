@@ -0,0 +1,94 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Build - конфигурация сборки: платформа и теги. Пустые GOOS и GOARCH - текущая платформа
+type Build struct {
+	GOOS   string   `yaml:"goos"`
+	GOARCH string   `yaml:"goarch"`
+	Tags   []string `yaml:"tags"`
+}
+
+// UnmarshalYAML разбирает конфигурацию сборки и отклоняет неизвестные ключи
+func (b *Build) UnmarshalYAML(value *yaml.Node) error {
+	if err := checkFields(value, reflect.TypeOf(*b)); err != nil {
+		return err
+	}
+	type plain Build // без метода UnmarshalYAML, чтобы избежать рекурсии
+	if err := value.Decode((*plain)(b)); err != nil {
+		return err
+	}
+	for _, tag := range b.Tags {
+		if tag == "" || strings.ContainsAny(tag, ", ") {
+			return fmt.Errorf("line %d: invalid build tag %q", value.Line, tag)
+		}
+	}
+	return nil
+}
+
+// String описывает конфигурацию сборки для логов, например "linux/arm64 (integration)"
+func (b Build) String() string {
+	goos, goarch := b.GOOS, b.GOARCH
+	if goos == "" {
+		goos = "host"
+	}
+	if goarch == "" {
+		goarch = "host"
+	}
+	s := goos + "/" + goarch
+	if len(b.Tags) > 0 {
+		s += " (" + strings.Join(b.Tags, ",") + ")"
+	}
+	return s
+}
+
+// Builds возвращает конфигурации сборки для анализа: build-matrix корневой конфигурации
+// или одну сборку для текущей платформы. Теги из tags добавляются к каждой конфигурации
+func (c *Config) Builds(tags []string) []Build {
+	matrix := c.buildMatrix()
+	if len(matrix) == 0 {
+		matrix = []Build{{}}
+	}
+
+	builds := make([]Build, 0, len(matrix))
+	for _, b := range matrix {
+		b.Tags = slices.Clone(b.Tags)
+		for _, tag := range tags {
+			if !slices.Contains(b.Tags, tag) {
+				b.Tags = append(b.Tags, tag)
+			}
+		}
+		builds = append(builds, b)
+	}
+	return builds
+}
+
+// HasBuildMatrix сообщает, задан ли build-matrix в корневой конфигурации
+func (c *Config) HasBuildMatrix() bool {
+	return len(c.buildMatrix()) > 0
+}
+
+// buildMatrix возвращает build-matrix корневой конфигурации
+func (c *Config) buildMatrix() []Build {
+	layers := c.layers()
+	for i := len(layers) - 1; i >= 0; i-- {
+		if len(layers[i].BuildMatrix) > 0 {
+			return layers[i].BuildMatrix
+		}
+	}
+	return nil
+}
+
+// ParseTags разбирает значение флага -tags: теги через запятую, как у go build
+func ParseTags(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' '
+	})
+}
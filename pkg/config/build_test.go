@@ -0,0 +1,57 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBuilds(t *testing.T) {
+	cfg, err := loadContent(t, `build-matrix:
+  - goos: linux
+  - goos: windows
+    goarch: arm64
+    tags: [integration]
+`)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	want := []Build{
+		{GOOS: "linux", Tags: []string{"e2e", "integration"}},
+		{GOOS: "windows", GOARCH: "arm64", Tags: []string{"integration", "e2e"}},
+	}
+	if got := cfg.Builds(ParseTags("e2e,integration")); !reflect.DeepEqual(got, want) {
+		t.Errorf("Builds() = %v, want %v", got, want)
+	}
+	if got := cfg.BuildMatrix[0].Tags; len(got) != 0 {
+		t.Errorf("Builds() modified build-matrix tags: %v", got)
+	}
+	if !cfg.HasBuildMatrix() {
+		t.Errorf("HasBuildMatrix() = false, want true")
+	}
+
+	// Без build-matrix анализируется текущая платформа
+	if got := DefaultConfig().Builds(nil); !reflect.DeepEqual(got, []Build{{}}) {
+		t.Errorf("Builds() = %v, want current platform", got)
+	}
+	if DefaultConfig().HasBuildMatrix() {
+		t.Errorf("HasBuildMatrix() = true without build-matrix")
+	}
+	if got := (Build{GOOS: "windows", GOARCH: "arm64", Tags: []string{"integration"}}).String(); got != "windows/arm64 (integration)" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestBuildErrors(t *testing.T) {
+	tests := map[string]string{
+		"build-matrix:\n  - os: linux\n":                 `line 2: unknown field "os" (did you mean "goos"?)`,
+		"build-matrix:\n  - tags: [\"a,b\"]\n":           `line 2: invalid build tag "a,b"`,
+		"build-matrix:\n  - goos: linux\n    tag: [a]\n": `line 3: unknown field "tag" (did you mean "tags"?)`,
+	}
+	for content, wantErr := range tests {
+		if _, err := loadContent(t, content); err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("LoadConfig(%q) error = %v, want %q", content, err, wantErr)
+		}
+	}
+}
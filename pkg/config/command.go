@@ -0,0 +1,168 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// defaultConfigName - имя файла, который создает команда init
+const defaultConfigName = ".unused-interface-methods.yml"
+
+// RunCommand выполняет подкоманды, которые работают с конфигурацией, а не с кодом:
+//
+//	init [-force] [path]       создать файл конфигурации с комментариями
+//	config validate [path...]  проверить файлы конфигурации
+//	config schema              вывести JSON Schema файла конфигурации
+//
+// handled = false - аргументы не относятся к подкомандам
+func RunCommand(args []string, stdout, stderr io.Writer) (code int, handled bool) {
+	if len(args) == 0 {
+		return 0, false
+	}
+	switch args[0] {
+	case "init":
+		return runInit(args[1:], stdout, stderr), true
+	case "config":
+		if len(args) < 2 {
+			fmt.Fprintln(stderr, "Usage: unused-interface-methods config validate [path...] | config schema")
+			return ExitConfig, true
+		}
+		switch args[1] {
+		case "validate":
+			return runValidate(args[2:], stdout, stderr), true
+		case "schema":
+			return runSchema(stdout, stderr), true
+		}
+		fmt.Fprintf(stderr, "Error: unknown config command %q (supported: validate, schema)\n", args[1])
+		return ExitConfig, true
+	}
+	return 0, false
+}
+
+// runInit создает файл конфигурации по умолчанию с комментариями
+func runInit(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("init", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	force := flags.Bool("force", false, "Overwrite an existing config file")
+	if err := flags.Parse(args); err != nil {
+		return ExitConfig
+	}
+
+	path := defaultConfigName
+	if flags.NArg() > 0 {
+		path = flags.Arg(0)
+	}
+	if _, err := os.Stat(path); err == nil && !*force {
+		fmt.Fprintf(stderr, "Error: %s already exists (use -force to overwrite)\n", path)
+		return ExitConfig
+	}
+	if err := os.WriteFile(path, Annotated(), 0644); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return ExitInternal
+	}
+	fmt.Fprintf(stdout, "Created %s\n", path)
+	return ExitOK
+}
+
+// runValidate проверяет указанные файлы или все файлы конфигурации текущей директории
+func runValidate(paths []string, stdout, stderr io.Writer) int {
+	if len(paths) == 0 {
+		cfg, err := Load(".")
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return ExitConfig
+		}
+		files := cfg.Files()
+		if len(files) == 0 {
+			fmt.Fprintln(stdout, "No config files found, defaults apply")
+		}
+		for _, file := range files {
+			fmt.Fprintf(stdout, "%s: OK\n", GetRelativePath(file))
+		}
+		return ExitOK
+	}
+
+	code := ExitOK
+	for _, path := range paths {
+		if _, err := LoadFile(path); err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			code = ExitConfig
+			continue
+		}
+		fmt.Fprintf(stdout, "%s: OK\n", path)
+	}
+	return code
+}
+
+// runSchema выводит JSON Schema файла конфигурации
+func runSchema(stdout, stderr io.Writer) int {
+	schema, err := Schema()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return ExitInternal
+	}
+	fmt.Fprintf(stdout, "%s\n", schema)
+	return ExitOK
+}
+
+// Annotated возвращает конфигурацию по умолчанию с комментариями и примерами остальных ключей
+func Annotated() []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# yaml-language-server: $schema=%s\n", SchemaURL)
+	b.WriteString(`#
+# Unused Interface Methods configuration.
+# Path patterns follow .gitignore rules relative to the directory of this file.
+
+# Paths excluded from analysis; "!pattern" brings a path back.
+ignore:
+`)
+	for _, pattern := range DefaultConfig().Ignore {
+		fmt.Fprintf(&b, "  - %q\n", pattern)
+	}
+	b.WriteString(`
+# Analyze only Go files matching these patterns (all files when empty).
+# include:
+#   - "internal/**"
+
+# Suppress unused methods by package, interface and method, optionally until a date.
+# exclude:
+#   - package: "example.com/app/legacy/**"
+#     interface: "Storage"
+#     method: "Compact"
+#     reason: "removed in v2"
+#     owner: "@storage-team"
+#     until: "2030-01-01"
+
+# Rules by path, package, interface, method, implements or generic.
+# Actions: ignore, warn, error, api. The first matching rule wins.
+# rules:
+#   - implements: io.Closer
+#     action: api
+
+# Severity per finding kind: error, warning, info or off.
+# severity:
+#   unused-method: error
+#   generic-skipped: info
+#   load-error: warning
+
+# Lowest severity that fails the run: error (default), warning, info or off.
+# fail-on: error
+
+# Build configurations to analyze; a method used in any of them is used.
+# build-matrix:
+#   - goos: linux
+#   - goos: windows
+#   - goos: linux
+#     tags: [integration]
+
+# Do not inherit config files from parent directories.
+# root: true
+
+# Import a shared config file, relative to this file.
+# extends: ../shared/unused-interface-methods.yml
+`)
+	return []byte(b.String())
+}
@@ -1,20 +1,61 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 
-	"github.com/bmatcuk/doublestar/v4"
 	"gopkg.in/yaml.v3"
+
+	"github.com/comerc/unused-interface-methods/pkg/results"
 )
 
 // OsExit используется для возможности мока os.Exit в тестах
 var OsExit = os.Exit
 
+// Коды возврата линтера
+const (
+	ExitOK       = 0 // проблем с серьезностью не ниже fail-on нет
+	ExitFindings = 1 // есть находки с серьезностью не ниже fail-on
+	ExitConfig   = 2 // ошибка конфигурации или аргументов командной строки
+	ExitLoad     = 3 // пакеты не загрузились или не прошли проверку типов
+	ExitInternal = 4 // внутренняя ошибка: запись отчета или baseline, вызов git
+)
+
 // Config содержит настройки линтера
 type Config struct {
-	// Паттерны для игнорирования файлов и директорий
+	// Не наследовать настройки из файлов родительских директорий
+	Root bool `yaml:"root"`
+	// Путь к общему файлу конфигурации относительно этого файла
+	Extends string `yaml:"extends"`
+	// Паттерны для игнорирования файлов и директорий; "!pattern" возвращает путь обратно
 	Ignore []string `yaml:"ignore"`
+	// Паттерны путей, которыми ограничивается анализ; пустой список - все пути
+	Include []string `yaml:"include"`
+	// Подавления находок по пакету, интерфейсу и методу, возможно временные
+	Exclude []ExcludeRule `yaml:"exclude"`
+	// Правила по пакету, интерфейсу и методу с действием ignore, warn, error или api
+	Rules []Rule `yaml:"rules"`
+	// Серьезность видов находок, например unused-method: warning
+	Severity map[results.RuleID]results.Severity `yaml:"severity"`
+	// Минимальная серьезность, при которой запуск завершается с ошибкой; off - никогда
+	FailOn results.Severity `yaml:"fail-on"`
+	// Конфигурации сборки, в каждой из которых анализируются пакеты; пустой список - текущая платформа
+	BuildMatrix []Build `yaml:"build-matrix"`
+
+	matched  map[string]bool // паттерны ignore и include, совпавшие хотя бы с одним путем
+	baseDir  string          // директория, от которой отсчитываются паттерны путей
+	path     string          // файл конфигурации; пусто у конфигурации по умолчанию
+	parent   *Config         // конфигурация ближайшей родительской директории
+	extended *Config         // конфигурация, подключенная через extends
+	nested   []*Config       // конфигурации поддиректорий, найденные Load
+}
+
+// IgnoreRule представляет паттерн ignore вместе с его положением в файле конфигурации
+type IgnoreRule struct {
+	Pattern string
+	File    string
+	Line    int
 }
 
 // DefaultConfig возвращает конфигурацию по умолчанию
@@ -34,7 +75,7 @@ func DefaultConfig() *Config {
 func LoadConfig(configPath string) (*Config, error) {
 	// Если путь не указан, ищем стандартные места
 	if configPath == "" {
-		configPath = findConfigFile()
+		configPath = FindConfigFile()
 	}
 
 	// Если файл не найден, используем конфигурацию по умолчанию
@@ -51,61 +92,166 @@ func LoadConfig(configPath string) (*Config, error) {
 		return nil, err
 	}
 
-	data, err := os.ReadFile(configPath)
+	return loadFile(configPath, DefaultConfig(), "", nil)
+}
+
+// loadFile читает файл конфигурации поверх base и загружает файл из extends.
+// baseDir задает директорию для паттернов путей: по умолчанию - директория файла.
+// seen содержит файлы, которые уже подключают этот файл через extends
+func loadFile(configPath string, base *Config, baseDir string, seen map[string]bool) (*Config, error) {
+	absPath, err := filepath.Abs(configPath)
 	if err != nil {
 		return nil, err
 	}
+	if seen[absPath] {
+		return nil, fmt.Errorf("extends cycle: %s", absPath)
+	}
 
-	config := DefaultConfig()
-	if err := yaml.Unmarshal(data, config); err != nil {
+	data, err := os.ReadFile(absPath)
+	if err != nil {
 		return nil, err
 	}
 
+	config := base
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("%s: %w", configPath, err)
+	}
+
+	// Паттерны путей отсчитываются от директории файла конфигурации
+	if baseDir == "" {
+		baseDir = configDir(absPath)
+	}
+	config.path = absPath
+	config.baseDir = baseDir
+	for i := range config.Rules {
+		config.Rules[i].File = absPath
+	}
+	for i := range config.Exclude {
+		config.Exclude[i].File = absPath
+	}
+
+	if config.Extends != "" {
+		extends := config.Extends
+		if !filepath.IsAbs(extends) {
+			extends = filepath.Join(filepath.Dir(absPath), extends)
+		}
+		chain := map[string]bool{absPath: true}
+		for p := range seen {
+			chain[p] = true
+		}
+		// Подключенный файл действует так, будто его содержимое записано в этом файле
+		if config.extended, err = loadFile(extends, &Config{}, baseDir, chain); err != nil {
+			return nil, fmt.Errorf("%s: extends: %w", configPath, err)
+		}
+	}
+
 	return config, nil
 }
 
-// findConfigFile ищет конфигурационный файл в стандартных местах
-func findConfigFile() string {
-	candidates := []string{
-		".unused-interface-methods.yml",
-		"unused-interface-methods.yml",
-		".config/unused-interface-methods.yml",
-		".unused-interface-methods.yaml",
-		"unused-interface-methods.yaml",
-		".config/unused-interface-methods.yaml",
+// configDir возвращает директорию, к которой относится файл конфигурации:
+// файл из .config/ относится к родительской директории
+func configDir(absPath string) string {
+	dir := filepath.Dir(absPath)
+	if filepath.Base(dir) == ".config" {
+		return filepath.Dir(dir)
+	}
+	return dir
+}
+
+// FindConfigFile ищет конфигурационный файл в стандартных местах.
+// Возвращает пустую строку, если файл не найден
+func FindConfigFile() string {
+	return findConfigIn(".")
+}
+
+// BaseDir возвращает директорию, от которой отсчитываются паттерны путей.
+// Пустое значение - файла конфигурации нет и директория еще не задана
+func (c *Config) BaseDir() string {
+	return c.baseDir
+}
+
+// SetBaseDir задает директорию, от которой отсчитываются паттерны путей.
+// Используется, когда файла конфигурации нет: обычно это корень модуля
+func (c *Config) SetBaseDir(dir string) {
+	if absDir, err := filepath.Abs(dir); err == nil {
+		dir = absDir
 	}
+	c.baseDir = dir
+}
 
-	for _, candidate := range candidates {
-		if _, err := os.Stat(candidate); err == nil {
-			return candidate
+// ModuleRoot возвращает ближайшую вверх от dir директорию с go.mod,
+// а если go.mod не найден - саму dir в абсолютном виде
+func ModuleRoot(dir string) string {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return dir
+	}
+	for d := absDir; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(filepath.Join(d, "go.mod")); err == nil {
+			return d
+		}
+		if filepath.Dir(d) == d {
+			return absDir
 		}
 	}
-
-	return ""
 }
 
 // ShouldIgnore проверяет, нужно ли игнорировать файл или директорию
 func (c *Config) ShouldIgnore(filePath string) bool {
-	// Нормализуем путь
-	filePath = filepath.Clean(filePath)
+	return c.Decide(Subject{File: filePath}).Action == ActionIgnore
+}
 
-	for _, pattern := range c.Ignore {
-		if c.matchPattern(pattern, filePath) {
-			return true
+// StaleIgnoreRules возвращает паттерны ignore из файлов конфигурации,
+// которые не совпали ни с одним путем с момента загрузки
+func (c *Config) StaleIgnoreRules() ([]IgnoreRule, error) {
+	var stale []IgnoreRule
+	for _, layer := range c.all() {
+		if layer.path == "" {
+			continue // паттерны по умолчанию не считаются правилами пользователя
 		}
-	}
 
-	return false
-}
+		data, err := os.ReadFile(layer.path)
+		if err != nil {
+			return nil, err
+		}
+		var root yaml.Node
+		if err := yaml.Unmarshal(data, &root); err != nil {
+			return nil, err
+		}
+		lines := ignoreLines(&root)
 
-// matchPattern проверяет соответствие файла паттерну
-func (c *Config) matchPattern(pattern, filePath string) bool {
-	// Нормализуем путь
-	filePath = filepath.Clean(filePath)
+		for _, pattern := range layer.Ignore {
+			line, ok := lines[pattern]
+			if !ok || layer.matched[pattern] {
+				continue // паттерны по умолчанию остаются, если в файле нет ключа ignore
+			}
+			stale = append(stale, IgnoreRule{Pattern: pattern, File: layer.path, Line: line})
+		}
+	}
+	return stale, nil
+}
 
-	// Используем doublestar для проверки соответствия
-	matched, _ := doublestar.Match(pattern, filePath)
-	return matched
+// ignoreLines возвращает номера строк элементов списка ignore
+func ignoreLines(root *yaml.Node) map[string]int {
+	lines := make(map[string]int)
+	if root.Kind != yaml.DocumentNode || len(root.Content) == 0 {
+		return lines
+	}
+	mapping := root.Content[0]
+	if mapping.Kind != yaml.MappingNode {
+		return lines
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value != "ignore" || mapping.Content[i+1].Kind != yaml.SequenceNode {
+			continue
+		}
+		for _, item := range mapping.Content[i+1].Content {
+			if _, ok := lines[item.Value]; !ok {
+				lines[item.Value] = item.Line
+			}
+		}
+	}
+	return lines
 }
 
 // GetRelativePath преобразует путь в относительный от текущей директории
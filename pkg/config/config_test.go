@@ -83,11 +83,18 @@ func TestLoadConfig(t *testing.T) {
 			t.Fatalf("LoadConfig() error = %v", err)
 		}
 
+		// Паттерны отсчитываются от директории найденного файла
+		baseDir, err := filepath.Abs(".")
+		if err != nil {
+			t.Fatal(err)
+		}
 		want := &Config{
 			Ignore: []string{
 				"vendor/**",
 				"**/*.pb.go",
 			},
+			baseDir: baseDir,
+			path:    filepath.Join(baseDir, ".unused-interface-methods.yml"),
 		}
 		if !reflect.DeepEqual(cfg, want) {
 			t.Errorf("LoadConfig() = %v, want %v", cfg, want)
@@ -130,6 +137,8 @@ func TestLoadConfig(t *testing.T) {
 			Ignore: []string{
 				"custom/**",
 			},
+			baseDir: tmpDir,
+			path:    customPath,
 		}
 		if !reflect.DeepEqual(cfg, want) {
 			t.Errorf("LoadConfig() = %v, want %v", cfg, want)
@@ -171,3 +180,40 @@ func TestLoadConfig(t *testing.T) {
 		os.Chmod(noAccessDir, 0700)
 	})
 }
+
+func TestStaleIgnoreRules(t *testing.T) {
+	content := []byte(`# паттерны игнорирования
+ignore:
+  - "vendor/**"
+  - "**/*.pb.go"
+  - "**/mocks/**"
+`)
+	configPath := filepath.Join(t.TempDir(), "unused-interface-methods.yml")
+	if err := os.WriteFile(configPath, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	cfg.ShouldIgnore(filepath.Join("api", "user.pb.go"))
+	cfg.ShouldIgnore(filepath.Join("service", "mocks", "user_mock.go"))
+	cfg.ShouldIgnore(filepath.Join("cmd", "main.go"))
+
+	stale, err := cfg.StaleIgnoreRules()
+	if err != nil {
+		t.Fatalf("StaleIgnoreRules() error = %v", err)
+	}
+	want := []IgnoreRule{{Pattern: "vendor/**", File: configPath, Line: 3}}
+	if !reflect.DeepEqual(stale, want) {
+		t.Errorf("StaleIgnoreRules() = %v, want %v", stale, want)
+	}
+
+	// Паттерны по умолчанию не относятся к файлу конфигурации и не проверяются
+	stale, err = DefaultConfig().StaleIgnoreRules()
+	if err != nil || stale != nil {
+		t.Errorf("StaleIgnoreRules() = %v, %v, want nil, nil", stale, err)
+	}
+}
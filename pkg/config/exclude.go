@@ -0,0 +1,162 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"gopkg.in/yaml.v3"
+)
+
+// dateLayout - формат даты в поле until
+const dateLayout = "2006-01-02"
+
+// ExcludeRule подавляет находки по пакету, интерфейсу и методу.
+// Пустое поле сопоставителя совпадает с любым значением
+type ExcludeRule struct {
+	Package   string `yaml:"package"`   // glob пути пакета, например "example.com/app/legacy/**"
+	Interface string `yaml:"interface"` // регулярное выражение для имени интерфейса целиком
+	Method    string `yaml:"method"`    // регулярное выражение для имени метода целиком
+	Reason    string `yaml:"reason"`
+	Owner     string `yaml:"owner"`
+	Until     Date   `yaml:"until"` // последний день действия; пустое значение - бессрочно
+
+	File string `yaml:"-"` // файл конфигурации с записью
+	Line int    `yaml:"-"` // строка записи в файле конфигурации
+
+	interfaceRe *regexp.Regexp
+	methodRe    *regexp.Regexp
+}
+
+// Date - календарная дата в формате YYYY-MM-DD
+type Date struct {
+	time.Time
+}
+
+// UnmarshalYAML разбирает дату из строки YYYY-MM-DD
+func (d *Date) UnmarshalYAML(value *yaml.Node) error {
+	t, err := time.Parse(dateLayout, value.Value)
+	if err != nil {
+		return fmt.Errorf("line %d: invalid date %q, expected YYYY-MM-DD", value.Line, value.Value)
+	}
+	d.Time = t
+	return nil
+}
+
+// String возвращает дату в формате YYYY-MM-DD
+func (d Date) String() string {
+	if d.IsZero() {
+		return ""
+	}
+	return d.Format(dateLayout)
+}
+
+// UnmarshalYAML разбирает запись exclude и проверяет сопоставители
+func (r *ExcludeRule) UnmarshalYAML(value *yaml.Node) error {
+	if err := checkFields(value, reflect.TypeOf(*r)); err != nil {
+		return err
+	}
+	type plain ExcludeRule // без метода UnmarshalYAML, чтобы избежать рекурсии
+	if err := value.Decode((*plain)(r)); err != nil {
+		return err
+	}
+	r.Line = value.Line
+
+	if r.Package == "" && r.Interface == "" && r.Method == "" {
+		return fmt.Errorf("line %d: exclude entry must set package, interface or method", value.Line)
+	}
+	if r.Package != "" && !doublestar.ValidatePattern(r.Package) {
+		return fmt.Errorf("line %d: invalid package pattern %q", value.Line, r.Package)
+	}
+
+	var err error
+	if r.interfaceRe, err = compileName(r.Interface); err != nil {
+		return fmt.Errorf("line %d: invalid interface regexp: %w", value.Line, err)
+	}
+	if r.methodRe, err = compileName(r.Method); err != nil {
+		return fmt.Errorf("line %d: invalid method regexp: %w", value.Line, err)
+	}
+	return nil
+}
+
+// compileName компилирует выражение, которое должно совпасть с именем целиком
+func compileName(expr string) (*regexp.Regexp, error) {
+	if expr == "" {
+		return nil, nil
+	}
+	return regexp.Compile("^(?:" + expr + ")$")
+}
+
+// Matches проверяет, относится ли запись к методу интерфейса
+func (r *ExcludeRule) Matches(pkgPath, interfaceName, methodName string) bool {
+	if r.Package != "" {
+		if matched, _ := doublestar.Match(r.Package, pkgPath); !matched {
+			return false
+		}
+	}
+	if r.interfaceRe != nil && !r.interfaceRe.MatchString(interfaceName) {
+		return false
+	}
+	if r.methodRe != nil && !r.methodRe.MatchString(methodName) {
+		return false
+	}
+	return true
+}
+
+// Expired сообщает, истек ли срок действия записи к моменту now
+func (r *ExcludeRule) Expired(now time.Time) bool {
+	return !r.Until.IsZero() && now.Format(dateLayout) > r.Until.String()
+}
+
+// String описывает сопоставитель записи, например package=app/**, method=Close
+func (r *ExcludeRule) String() string {
+	var parts []string
+	if r.Package != "" {
+		parts = append(parts, "package="+r.Package)
+	}
+	if r.Interface != "" {
+		parts = append(parts, "interface="+r.Interface)
+	}
+	if r.Method != "" {
+		parts = append(parts, "method="+r.Method)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ActiveExcludes возвращает записи exclude всех файлов конфигурации, действующие в момент now
+func (c *Config) ActiveExcludes(now time.Time) []*ExcludeRule {
+	return c.excludes(c.all(), func(r *ExcludeRule) bool { return !r.Expired(now) })
+}
+
+// ExpiredExcludes возвращает записи exclude всех файлов конфигурации, срок действия которых истек
+func (c *Config) ExpiredExcludes(now time.Time) []*ExcludeRule {
+	return c.excludes(c.all(), func(r *ExcludeRule) bool { return r.Expired(now) })
+}
+
+// ExcludesFor возвращает действующие записи exclude, которые применяются к файлу:
+// сначала записи ближайшего к файлу файла конфигурации. Пустой путь - записи корневой конфигурации
+func (c *Config) ExcludesFor(filePath string, now time.Time) []*ExcludeRule {
+	layers := c.layers()
+	if filePath != "" {
+		layers = c.forPath(filePath).layers()
+	}
+	slices.Reverse(layers)
+	return c.excludes(layers, func(r *ExcludeRule) bool { return !r.Expired(now) })
+}
+
+// excludes собирает записи exclude из слоев конфигурации, подходящие под условие
+func (c *Config) excludes(layers []*Config, keep func(*ExcludeRule) bool) []*ExcludeRule {
+	var rules []*ExcludeRule
+	for _, layer := range layers {
+		for i := range layer.Exclude {
+			if keep(&layer.Exclude[i]) {
+				rules = append(rules, &layer.Exclude[i])
+			}
+		}
+	}
+	return rules
+}
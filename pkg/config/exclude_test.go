@@ -0,0 +1,126 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// loadContent записывает конфигурацию во временный файл и загружает ее
+func loadContent(t *testing.T, content string) (*Config, error) {
+	t.Helper()
+	configPath := filepath.Join(t.TempDir(), "unused-interface-methods.yml")
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return LoadConfig(configPath)
+}
+
+func TestExclude(t *testing.T) {
+	cfg, err := loadContent(t, `exclude:
+  - package: "example.com/app/legacy/**"
+    interface: "Legacy.*"
+    reason: legacy API
+    owner: "@platform"
+    until: 2026-12-31
+  - method: "Close|Flush"
+    reason: called via io.Closer
+`)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if len(cfg.Exclude) != 2 {
+		t.Fatalf("len(Exclude) = %d, want 2", len(cfg.Exclude))
+	}
+
+	legacy, closer := cfg.Exclude[0], cfg.Exclude[1]
+	if legacy.Line != 2 || closer.Line != 7 {
+		t.Errorf("Line = %d, %d, want 2, 7", legacy.Line, closer.Line)
+	}
+	if got := legacy.Until.String(); got != "2026-12-31" {
+		t.Errorf("Until = %q, want 2026-12-31", got)
+	}
+	if got := legacy.String(); got != "package=example.com/app/legacy/**, interface=Legacy.*" {
+		t.Errorf("String() = %q", got)
+	}
+
+	matches := []struct {
+		rule                   *ExcludeRule
+		pkgPath, iface, method string
+		want                   bool
+	}{
+		{&legacy, "example.com/app/legacy/client", "LegacyClient", "Fetch", true},
+		{&legacy, "example.com/app/api", "LegacyClient", "Fetch", false},
+		{&legacy, "example.com/app/legacy/client", "Client", "Fetch", false},
+		{&legacy, "example.com/app/legacy/client", "ClientLegacy", "Fetch", false},
+		{&closer, "example.com/app", "Storage", "Close", true},
+		{&closer, "example.com/app", "Storage", "Flush", true},
+		{&closer, "example.com/app", "Storage", "CloseAll", false},
+	}
+	for _, m := range matches {
+		if got := m.rule.Matches(m.pkgPath, m.iface, m.method); got != m.want {
+			t.Errorf("%s: Matches(%q, %q, %q) = %v, want %v", m.rule, m.pkgPath, m.iface, m.method, got, m.want)
+		}
+	}
+
+	// Запись действует по день until включительно
+	lastDay := time.Date(2026, 12, 31, 23, 59, 0, 0, time.UTC)
+	if legacy.Expired(lastDay) {
+		t.Errorf("Expired(%v) = true, want false", lastDay)
+	}
+	nextDay := lastDay.Add(time.Hour)
+	if !legacy.Expired(nextDay) {
+		t.Errorf("Expired(%v) = false, want true", nextDay)
+	}
+	if closer.Expired(nextDay.AddDate(100, 0, 0)) {
+		t.Error("entry without until must never expire")
+	}
+
+	active, expired := cfg.ActiveExcludes(nextDay), cfg.ExpiredExcludes(nextDay)
+	if len(active) != 1 || active[0].Method != "Close|Flush" {
+		t.Errorf("ActiveExcludes() = %v", active)
+	}
+	if len(expired) != 1 || expired[0].Package != legacy.Package {
+		t.Errorf("ExpiredExcludes() = %v", expired)
+	}
+}
+
+func TestExcludeErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "empty matcher",
+			content: "exclude:\n  - reason: nothing\n",
+			wantErr: "must set package, interface or method",
+		},
+		{
+			name:    "invalid regexp",
+			content: "exclude:\n  - method: \"Close(\"\n",
+			wantErr: "invalid method regexp",
+		},
+		{
+			name:    "invalid package pattern",
+			content: "exclude:\n  - package: \"app/[\"\n",
+			wantErr: "invalid package pattern",
+		},
+		{
+			name:    "invalid date",
+			content: "exclude:\n  - method: Close\n    until: 31.12.2026\n",
+			wantErr: "invalid date",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadContent(t, tt.content)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadConfig() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,244 @@
+package config
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// configNames - имена файла конфигурации в порядке поиска внутри директории
+var configNames = []string{
+	".unused-interface-methods.yml",
+	"unused-interface-methods.yml",
+	".config/unused-interface-methods.yml",
+	".unused-interface-methods.yaml",
+	"unused-interface-methods.yaml",
+	".config/unused-interface-methods.yaml",
+}
+
+// IsConfigFile сообщает, что путь указывает на файл конфигурации со стандартным именем
+func IsConfigFile(path string) bool {
+	path = filepath.ToSlash(path)
+	for _, name := range configNames {
+		if path == name || strings.HasSuffix(path, "/"+name) {
+			return true
+		}
+	}
+	return false
+}
+
+// findConfigIn возвращает путь к файлу конфигурации в директории dir или пустую строку
+func findConfigIn(dir string) string {
+	for _, name := range configNames {
+		candidate := filepath.Join(dir, name)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// Load загружает все файлы конфигурации, которые относятся к анализу директории dir:
+// файлы из dir и ее родителей вплоть до файла с root: true и файлы поддиректорий.
+// Файл действует на свое поддерево и дополняет родительские: списки объединяются,
+// а для путей применяется ближайший файл. Без файлов в иерархии используется файл
+// из текущей директории, а без него - конфигурация по умолчанию от корня модуля
+func Load(dir string) (*Config, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	// Файлы от dir вверх: первый - ближайший к dir
+	var chain []string
+	for d := absDir; ; d = filepath.Dir(d) {
+		if path := findConfigIn(d); path != "" {
+			chain = append(chain, path)
+			root, err := isRoot(path)
+			if err != nil {
+				return nil, err
+			}
+			if root {
+				break
+			}
+		}
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+	if len(chain) == 0 {
+		if path := FindConfigFile(); path != "" {
+			chain = append(chain, path)
+		}
+	}
+
+	var cfg *Config
+	loaded := make(map[string]bool)
+	for i := len(chain) - 1; i >= 0; i-- {
+		if cfg, err = loadLayer(chain[i], cfg); err != nil {
+			return nil, err
+		}
+		loaded[cfg.path] = true
+	}
+	if cfg == nil {
+		cfg = DefaultConfig()
+		cfg.baseDir = ModuleRoot(absDir)
+	}
+
+	// Файлы поддиректорий наследуют ближайший уже загруженный файл
+	err = filepath.WalkDir(absDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != absDir && skipDir(d.Name()) {
+			return filepath.SkipDir
+		}
+		file := findConfigIn(path)
+		if file == "" {
+			return nil
+		}
+		if absFile, _ := filepath.Abs(file); loaded[absFile] {
+			return nil
+		}
+		nested, err := loadLayer(file, cfg.forPath(path))
+		if err != nil {
+			return err
+		}
+		loaded[nested.path] = true
+		cfg.nested = append(cfg.nested, nested)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// AddModules добавляет слои конфигурации корням модулей, которые анализируются вместе.
+// Модуль вне дерева, просмотренного Load, получает свой файл конфигурации, а модуль без
+// файла - конфигурацию по умолчанию: ее паттерны отсчитываются от корня модуля,
+// как при его отдельном анализе
+func (c *Config) AddModules(dirs []string) error {
+	for _, dir := range dirs {
+		absDir, err := filepath.Abs(dir)
+		if err != nil {
+			return err
+		}
+		if c.forPath(absDir).baseDir == absDir {
+			continue // у модуля уже есть свой файл
+		}
+		var parent *Config
+		if within(c.baseDir, absDir) {
+			parent = c.forPath(absDir)
+		}
+
+		module := DefaultConfig()
+		module.baseDir = absDir
+		if file := findConfigIn(absDir); file != "" {
+			if module, err = loadLayer(file, parent); err != nil {
+				return err
+			}
+		}
+		module.parent = parent
+		c.nested = append(c.nested, module)
+	}
+	return nil
+}
+
+// skipDir сообщает, что директорию не нужно просматривать в поисках файлов конфигурации:
+// go tool так же пропускает скрытые директории, testdata и vendor
+func skipDir(name string) bool {
+	return strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || name == "testdata" || name == "vendor"
+}
+
+// isRoot читает из файла конфигурации только ключ root
+func isRoot(path string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	var header struct {
+		Root bool `yaml:"root"`
+	}
+	if err := yaml.Unmarshal(data, &header); err != nil {
+		return false, err
+	}
+	return header.Root, nil
+}
+
+// loadLayer загружает файл конфигурации поверх родительского.
+// Верхний файл и файл с root: true дополняют конфигурацию по умолчанию
+func loadLayer(path string, parent *Config) (*Config, error) {
+	root, err := isRoot(path)
+	if err != nil {
+		return nil, err
+	}
+	base := &Config{}
+	if parent == nil || root {
+		base, parent = DefaultConfig(), nil
+	}
+	cfg, err := loadFile(path, base, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	cfg.parent = parent
+	return cfg, nil
+}
+
+// forPath возвращает самый глубокий файл конфигурации, поддерево которого содержит путь
+func (c *Config) forPath(path string) *Config {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return c
+	}
+	var best *Config
+	for _, nested := range c.nested {
+		if within(nested.baseDir, absPath) && (best == nil || len(nested.baseDir) > len(best.baseDir)) {
+			best = nested
+		}
+	}
+	if best == nil {
+		return c
+	}
+	return best
+}
+
+// within сообщает, находится ли путь внутри директории dir
+func within(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// layers возвращает слои конфигурации от самого общего к самому частному:
+// родительские файлы, затем файл из extends, затем сам файл
+func (c *Config) layers() []*Config {
+	var layers []*Config
+	if c.parent != nil {
+		layers = c.parent.layers()
+	}
+	if c.extended != nil {
+		layers = append(layers, c.extended.layers()...)
+	}
+	return append(layers, c)
+}
+
+// all возвращает все загруженные слои конфигурации без повторов
+func (c *Config) all() []*Config {
+	var all []*Config
+	seen := make(map[*Config]bool)
+	for _, cfg := range append([]*Config{c}, c.nested...) {
+		for _, layer := range cfg.layers() {
+			if !seen[layer] {
+				seen[layer] = true
+				all = append(all, layer)
+			}
+		}
+	}
+	return all
+}
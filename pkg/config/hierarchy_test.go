@@ -0,0 +1,240 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeFiles создает файлы с содержимым в директории root
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLoadHierarchy(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"go.mod": "module example.com/mono\n",
+		".unused-interface-methods.yml": `ignore:
+  - "gen/**"
+rules:
+  - package: "example.com/mono/**"
+    action: error
+`,
+		"shared/base.yml": `ignore:
+  - "*.pb.go"
+exclude:
+  - interface: Storage
+    reason: shared
+`,
+		"payments/.unused-interface-methods.yml": `extends: ../shared/base.yml
+ignore:
+  - "legacy/**"
+  - "vendor/**"
+rules:
+  - package: "example.com/mono/payments/**"
+    action: warn
+`,
+		"search/.config/unused-interface-methods.yml": `root: true
+ignore:
+  - "/fixtures/**"
+`,
+		"search/.hidden/.unused-interface-methods.yml": "ignore: [\"**\"]\n",
+	})
+
+	cfg, err := Load(root)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	ignored := []struct {
+		path string
+		want bool
+	}{
+		{"gen/api.go", true},
+		{"payments/gen/api.go", false},        // паттерн с "/" привязан к директории своего файла
+		{"payments/legacy/old.go", true},      // паттерн отсчитывается от payments/
+		{"legacy/old.go", false},              // и не действует вне payments/
+		{"payments/api/user.pb.go", true},     // паттерн из extends действует на payments/
+		{"search/api/user.pb.go", false},      // но не на соседей
+		{"search/gen/api.go", false},          // root: true отключает паттерны корня
+		{"search/fixtures/data.go", true},     // файл из .config/ относится к search/
+		{"search/.hidden/anything.go", false}, // скрытые директории не просматриваются
+		{"payments/store/store.go", false},
+	}
+	for _, tt := range ignored {
+		if got := cfg.ShouldIgnore(filepath.Join(root, filepath.FromSlash(tt.path))); got != tt.want {
+			t.Errorf("ShouldIgnore(%s) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+
+	// Правила ближайшего файла проверяются раньше родительских
+	decide := func(rel, pkgPath string) Action {
+		return cfg.Decide(Subject{
+			File:      filepath.Join(root, filepath.FromSlash(rel)),
+			PkgPath:   pkgPath,
+			Interface: "Storage",
+			Method:    "Save",
+		}).Action
+	}
+	if got := decide("payments/store/store.go", "example.com/mono/payments/store"); got != ActionWarn {
+		t.Errorf("Decide(payments) = %q, want %q", got, ActionWarn)
+	}
+	if got := decide("billing/store.go", "example.com/mono/billing"); got != ActionError {
+		t.Errorf("Decide(billing) = %q, want %q", got, ActionError)
+	}
+	if got := decide("search/store.go", "example.com/mono/search"); got != "" {
+		t.Errorf("Decide(search) = %q, want no action", got)
+	}
+
+	// Записи exclude из extends применяются только к поддереву подключившего файла
+	now := time.Now()
+	excludes := cfg.ExcludesFor(filepath.Join(root, "payments", "store", "store.go"), now)
+	if len(excludes) != 1 || excludes[0].File != filepath.Join(root, "shared", "base.yml") || excludes[0].Line != 4 {
+		t.Errorf("ExcludesFor(payments) = %v, want shared/base.yml:4", excludes)
+	}
+	if excludes := cfg.ExcludesFor(filepath.Join(root, "search", "store.go"), now); len(excludes) != 0 {
+		t.Errorf("ExcludesFor(search) = %v, want none", excludes)
+	}
+	if active := cfg.ActiveExcludes(now); len(active) != 1 {
+		t.Errorf("ActiveExcludes() = %v, want 1 entry", active)
+	}
+
+	// Неиспользованные паттерны ищутся во всех файлах
+	stale, err := cfg.StaleIgnoreRules()
+	if err != nil {
+		t.Fatalf("StaleIgnoreRules() error = %v", err)
+	}
+	wantStale := []IgnoreRule{{
+		Pattern: "vendor/**",
+		File:    filepath.Join(root, "payments", ".unused-interface-methods.yml"),
+		Line:    4,
+	}}
+	if !reflect.DeepEqual(stale, wantStale) {
+		t.Errorf("StaleIgnoreRules() = %v, want %v", stale, wantStale)
+	}
+}
+
+func TestLoadUpward(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		".unused-interface-methods.yml":          "ignore:\n  - \"*.pb.go\"\n",
+		"team/.unused-interface-methods.yml":     "ignore:\n  - \"legacy/**\"\n",
+		"team/app/.unused-interface-methods.yml": "root: true\nignore:\n  - \"tmp/**\"\n",
+	})
+
+	// Анализ поддиректории учитывает файлы ее родителей
+	cfg, err := Load(filepath.Join(root, "team"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	for _, rel := range []string{"team/api/user.pb.go", "team/legacy/old.go", "team/app/tmp/x.go"} {
+		if !cfg.ShouldIgnore(filepath.Join(root, filepath.FromSlash(rel))) {
+			t.Errorf("ShouldIgnore(%s) = false, want true", rel)
+		}
+	}
+
+	// root: true останавливает подъем по родителям
+	cfg, err = Load(filepath.Join(root, "team", "app"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.ShouldIgnore(filepath.Join(root, "team", "app", "api", "user.pb.go")) {
+		t.Error("root: true must stop inheritance of *.pb.go")
+	}
+	if got, want := cfg.BaseDir(), filepath.Join(root, "team", "app"); got != want {
+		t.Errorf("BaseDir() = %q, want %q", got, want)
+	}
+}
+
+func TestLoadWithoutConfig(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"go.mod":          "module example.com/app\n",
+		"internal/app.go": "package internal\n",
+	})
+
+	cfg, err := Load(filepath.Join(root, "internal"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !reflect.DeepEqual(cfg.Ignore, DefaultConfig().Ignore) || cfg.BaseDir() != root {
+		t.Errorf("Load() = %v with base %q, want defaults from module root", cfg.Ignore, cfg.BaseDir())
+	}
+}
+
+func TestExtendsErrors(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"a.yml":   "extends: b.yml\n",
+		"b.yml":   "extends: a.yml\n",
+		"bad.yml": "extends: missing.yml\n",
+	})
+
+	if _, err := LoadConfig(filepath.Join(root, "a.yml")); err == nil || !strings.Contains(err.Error(), "extends cycle") {
+		t.Errorf("LoadConfig() error = %v, want extends cycle", err)
+	}
+	if _, err := LoadConfig(filepath.Join(root, "bad.yml")); err == nil || !strings.Contains(err.Error(), "missing.yml") {
+		t.Errorf("LoadConfig() error = %v, want missing extends file", err)
+	}
+}
+
+func TestAddModules(t *testing.T) {
+	root := t.TempDir()
+	other := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"api/go.mod":                        "module example.com/api\n",
+		"app/go.mod":                        "module example.com/app\n",
+		"app/.unused-interface-methods.yml": "ignore:\n  - \"gen/**\"\n",
+	})
+	writeFiles(t, other, map[string]string{
+		".unused-interface-methods.yml": "ignore:\n  - \"legacy/**\"\n",
+	})
+
+	cfg, err := Load(root)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if err := cfg.AddModules([]string{filepath.Join(root, "api"), filepath.Join(root, "app"), other}); err != nil {
+		t.Fatalf("AddModules() error = %v", err)
+	}
+
+	ignored := map[string]bool{
+		filepath.Join(root, "api", "test", "helpers.go"): true, // паттерны по умолчанию от корня модуля
+		filepath.Join(root, "app", "gen", "api.go"):      true, // свой файл модуля сохраняется
+		filepath.Join(other, "legacy", "old.go"):         true, // файл модуля вне дерева загружается
+		filepath.Join(root, "api", "store.go"):           false,
+	}
+	for path, want := range ignored {
+		if got := cfg.ShouldIgnore(path); got != want {
+			t.Errorf("ShouldIgnore(%s) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestIsConfigFile(t *testing.T) {
+	for path, want := range map[string]bool{
+		"/repo/.unused-interface-methods.yml":             true,
+		"/repo/svc/.config/unused-interface-methods.yaml": true,
+		"unused-interface-methods.yml":                    true,
+		"/repo/my.unused-interface-methods.yml":           false,
+		"/repo/.config/other.yml":                         false,
+		"/repo/.unused-interface-methods.yml/service.go":  false,
+	} {
+		if got := IsConfigFile(path); got != want {
+			t.Errorf("IsConfigFile(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package config
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// notIncluded - правило для путей, не совпавших ни с одним паттерном include
+var notIncluded = Rule{Name: "not included", Action: ActionIgnore}
+
+// decidePath применяет паттерны ignore и include к пути файла или директории.
+// Паттерны проверяются по порядку, как в .gitignore: побеждает последний совпавший,
+// а "!pattern" возвращает путь обратно. Паттерны вложенного файла конфигурации
+// проверяются после родительских и отсчитываются от его директории
+func (c *Config) decidePath(filePath string) Decision {
+	layers := c.forPath(filePath).layers()
+
+	var ignored *Rule
+	for _, layer := range layers {
+		rel := layer.relPath(filePath)
+		for _, pattern := range layer.Ignore {
+			negated, ok := layer.matchOrdered(pattern, rel)
+			if !ok {
+				continue
+			}
+			if negated {
+				ignored = nil
+			} else {
+				ignored = &Rule{Path: pattern, Action: ActionIgnore, File: layer.path}
+			}
+		}
+	}
+	if ignored != nil {
+		return Decision{Action: ActionIgnore, Rule: ignored}
+	}
+
+	// include ограничивает Go-файлы: директория не исключается, пока в ней могут быть нужные файлы
+	if !strings.HasSuffix(filePath, ".go") {
+		return Decision{}
+	}
+	restricted, included := false, false
+	for _, layer := range layers {
+		rel := layer.relPath(filePath)
+		for _, pattern := range layer.Include {
+			restricted = true
+			if negated, ok := layer.matchOrdered(pattern, rel); ok {
+				included = !negated
+			}
+		}
+	}
+	if restricted && !included {
+		return Decision{Action: ActionIgnore, Rule: &notIncluded}
+	}
+	return Decision{}
+}
+
+// matchOrdered сопоставляет паттерн с возможным префиксом "!" и запоминает совпадение
+func (c *Config) matchOrdered(pattern, rel string) (negated, ok bool) {
+	negated = strings.HasPrefix(pattern, "!")
+	if !matchPath(strings.TrimPrefix(pattern, "!"), rel) {
+		return negated, false
+	}
+	if c.matched == nil {
+		c.matched = make(map[string]bool)
+	}
+	c.matched[pattern] = true
+	return negated, true
+}
+
+// matchPath проверяет путь по паттерну doublestar в стиле .gitignore:
+// паттерн без "/" совпадает с именем на любой глубине, "/" в начале привязывает его к корню,
+// а совпадение с директорией распространяется на все ее содержимое
+func matchPath(pattern, rel string) bool {
+	if anchored, ok := strings.CutPrefix(pattern, "/"); ok {
+		pattern = anchored // "/" в начале привязывает паттерн к корню, как в .gitignore
+	} else if !strings.Contains(pattern, "/") {
+		pattern = "**/" + pattern
+	}
+	for p := rel; p != "." && p != "/" && p != ""; p = path.Dir(p) {
+		if matched, _ := doublestar.Match(pattern, p); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// relPath приводит путь к виду, в котором его проверяют паттерны:
+// относительно базовой директории (или текущей, если она не задана) и с "/" в качестве разделителя.
+// Пути вне базовой директории остаются абсолютными
+func (c *Config) relPath(filePath string) string {
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return filepath.ToSlash(filepath.Clean(filePath))
+	}
+
+	base := c.baseDir
+	if base == "" {
+		if base, err = os.Getwd(); err != nil {
+			return filepath.ToSlash(absPath)
+		}
+	}
+	rel, err := filepath.Rel(base, absPath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return filepath.ToSlash(absPath)
+	}
+	return filepath.ToSlash(rel)
+}
@@ -0,0 +1,115 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIncludeAndNegation(t *testing.T) {
+	cfg := &Config{
+		Ignore: []string{
+			"**/mock/**",
+			"!**/mock/contract/**",
+			"*.pb.go",
+		},
+		Include: []string{
+			"internal/**",
+			"!internal/legacy/**",
+		},
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		path string
+		want bool
+	}{
+		{"internal/order/order.go", false},
+		{"internal/order/mock/order_mock.go", true},         // **/mock/**
+		{"internal/order/mock/contract/contract.go", false}, // !**/mock/contract/**
+		{"internal/api/user.pb.go", true},                   // *.pb.go на любой глубине
+		{"internal/legacy/client.go", true},                 // !internal/legacy/**
+		{"cmd/main.go", true},                               // не входит в include
+		{"cmd", false},                                      // include не исключает директории
+		{"internal/order/mock", true},                       // **/mock/**
+		{"internal/order/mock/contract", false},             // !**/mock/contract/**
+		{"./internal/order/../order/order.go", false},       // путь нормализуется
+	}
+
+	for _, tc := range testCases {
+		rel := filepath.FromSlash(tc.path)
+		if got := cfg.ShouldIgnore(rel); got != tc.want {
+			t.Errorf("ShouldIgnore(%s) = %v, want %v", rel, got, tc.want)
+		}
+		// Абсолютный путь дает тот же результат, что и относительный
+		abs := filepath.Join(wd, rel)
+		if got := cfg.ShouldIgnore(abs); got != tc.want {
+			t.Errorf("ShouldIgnore(%s) = %v, want %v", abs, got, tc.want)
+		}
+	}
+
+	d := cfg.Decide(Subject{File: "cmd/main.go"})
+	if d.Rule == nil || d.Rule.String() != "not included" {
+		t.Errorf("Decide(cmd/main.go).Rule = %v, want not included", d.Rule)
+	}
+	d = cfg.Decide(Subject{File: "internal/order/mock/order_mock.go"})
+	if d.Rule == nil || d.Rule.Path != "**/mock/**" {
+		t.Errorf("Decide(order_mock.go).Rule = %v, want **/mock/**", d.Rule)
+	}
+}
+
+func TestMatchPath(t *testing.T) {
+	testCases := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"vendor", "vendor/github.com/pkg/errors/errors.go", true}, // директория со всем содержимым
+		{"vendor", "internal/vendor/x.go", true},                   // имя без "/" на любой глубине
+		{"/vendor", "vendor/x.go", true},                           // "/" в начале - от корня
+		{"/vendor", "internal/vendor/x.go", false},
+		{"internal/*.go", "internal/a.go", true},
+		{"internal/*.go", "internal/sub/a.go", false},
+		{"**/*_test.go", "/abs/outside/cwd/a_test.go", true},
+	}
+
+	for _, tc := range testCases {
+		if got := matchPath(tc.pattern, tc.path); got != tc.want {
+			t.Errorf("matchPath(%q, %q) = %v, want %v", tc.pattern, tc.path, got, tc.want)
+		}
+	}
+}
+
+func TestBaseDir(t *testing.T) {
+	baseDir := t.TempDir()
+	cfg := &Config{Ignore: []string{"test/**"}}
+	cfg.SetBaseDir(baseDir)
+
+	if !cfg.ShouldIgnore(filepath.Join(baseDir, "test", "helpers", "helpers.go")) {
+		t.Error("test/** must match relative to the base directory")
+	}
+	// Относительный путь отсчитывается от текущей директории и оказывается вне базовой
+	if cfg.ShouldIgnore(filepath.Join("test", "helpers", "helpers.go")) {
+		t.Error("test/** must not match paths outside the base directory")
+	}
+}
+
+func TestModuleRoot(t *testing.T) {
+	root := ModuleRoot(".")
+	if _, err := os.Stat(filepath.Join(root, "go.mod")); err != nil {
+		t.Errorf("ModuleRoot(.) = %s, want directory with go.mod", root)
+	}
+	if got := ModuleRoot(filepath.Join("..", "..", "pkg", "config")); got != root {
+		t.Errorf("ModuleRoot(../../pkg/config) = %s, want %s", got, root)
+	}
+
+	// Без go.mod возвращается сама директория
+	dir := t.TempDir()
+	if got := ModuleRoot(dir); got != dir {
+		t.Errorf("ModuleRoot(%s) = %s, want %s", dir, got, dir)
+	}
+}
@@ -0,0 +1,202 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"gopkg.in/yaml.v3"
+)
+
+// Action определяет, как поступить с методом, к которому относится правило
+type Action string
+
+const (
+	ActionIgnore Action = "ignore" // метод не анализируется и не выводится
+	ActionWarn   Action = "warn"   // неиспользуемый метод выводится как предупреждение
+	ActionError  Action = "error"  // неиспользуемый метод выводится как ошибка
+	ActionAPI    Action = "api"    // метод - публичный API и считается используемым
+)
+
+// Rule - правило конфигурации для методов интерфейсов.
+// Заданные сопоставители должны совпасть все, пустые не проверяются
+type Rule struct {
+	Name       string `yaml:"name"`
+	Path       string `yaml:"path"`       // glob пути файла, как в ignore
+	Package    string `yaml:"package"`    // glob пути пакета
+	Interface  string `yaml:"interface"`  // регулярное выражение для имени интерфейса целиком
+	Method     string `yaml:"method"`     // регулярное выражение для имени метода целиком
+	Implements string `yaml:"implements"` // внешний интерфейс, который реализует интерфейс, например "io.Closer"
+	Generic    *bool  `yaml:"generic"`    // интерфейс с параметрами типа или без них
+	Action     Action `yaml:"action"`
+	Reason     string `yaml:"reason"`
+
+	File string `yaml:"-"` // файл конфигурации с правилом
+	Line int    `yaml:"-"` // строка правила в файле конфигурации
+
+	interfaceRe *regexp.Regexp
+	methodRe    *regexp.Regexp
+}
+
+// Subject описывает то, о чем принимается решение: файл, интерфейс или метод.
+// Для файла заполняется только File. Matches ждет File относительно базовой
+// директории конфигурации, Decide приводит к этому виду любой путь
+type Subject struct {
+	File      string
+	PkgPath   string
+	Interface string
+	Method    string // пустое значение - решение об интерфейсе целиком
+	Generic   bool
+	// Implements проверяет, реализует ли интерфейс внешний интерфейс "pkg/path.Name".
+	// nil - информации о типах нет
+	Implements func(iface string) bool
+}
+
+// Decision - решение движка правил вместе с правилом, которое его определило
+type Decision struct {
+	Action Action // пустое значение - ни одно правило не подошло
+	Rule   *Rule
+}
+
+// UnmarshalYAML разбирает правило и проверяет сопоставители
+func (r *Rule) UnmarshalYAML(value *yaml.Node) error {
+	if err := checkFields(value, reflect.TypeOf(*r)); err != nil {
+		return err
+	}
+	type plain Rule // без метода UnmarshalYAML, чтобы избежать рекурсии
+	if err := value.Decode((*plain)(r)); err != nil {
+		return err
+	}
+	r.Line = value.Line
+
+	switch r.Action {
+	case ActionIgnore, ActionWarn, ActionError, ActionAPI:
+	case "":
+		return fmt.Errorf("line %d: rule must set action (ignore, warn, error or api)", value.Line)
+	default:
+		return fmt.Errorf("line %d: unknown rule action %q (supported: ignore, warn, error, api)", value.Line, r.Action)
+	}
+	if r.Path == "" && !r.semantic() {
+		return fmt.Errorf("line %d: rule must set path, package, interface, method, implements or generic", value.Line)
+	}
+	if r.Path != "" && !doublestar.ValidatePattern(r.Path) {
+		return fmt.Errorf("line %d: invalid path pattern %q", value.Line, r.Path)
+	}
+	if r.Package != "" && !doublestar.ValidatePattern(r.Package) {
+		return fmt.Errorf("line %d: invalid package pattern %q", value.Line, r.Package)
+	}
+	if r.Implements != "" && !strings.Contains(r.Implements, ".") {
+		return fmt.Errorf("line %d: implements must be a qualified name like io.Closer, got %q", value.Line, r.Implements)
+	}
+
+	var err error
+	if r.interfaceRe, err = compileName(r.Interface); err != nil {
+		return fmt.Errorf("line %d: invalid interface regexp: %w", value.Line, err)
+	}
+	if r.methodRe, err = compileName(r.Method); err != nil {
+		return fmt.Errorf("line %d: invalid method regexp: %w", value.Line, err)
+	}
+	return nil
+}
+
+// semantic сообщает, задает ли правило сопоставители по типам, а не по пути
+func (r *Rule) semantic() bool {
+	return r.Package != "" || r.Interface != "" || r.Method != "" || r.Implements != "" || r.Generic != nil
+}
+
+// Matches проверяет, относится ли правило к объекту решения
+func (r *Rule) Matches(s Subject) bool {
+	if r.semantic() && s.Interface == "" {
+		return false // решение о файле принимается только по путям
+	}
+	if r.Path != "" {
+		if s.File == "" || !matchPath(r.Path, filepath.ToSlash(s.File)) {
+			return false
+		}
+	}
+	if r.Package != "" {
+		if matched, _ := doublestar.Match(r.Package, s.PkgPath); !matched {
+			return false
+		}
+	}
+	if r.interfaceRe != nil && !r.interfaceRe.MatchString(s.Interface) {
+		return false
+	}
+	if r.methodRe != nil && (s.Method == "" || !r.methodRe.MatchString(s.Method)) {
+		return false
+	}
+	if r.Implements != "" && (s.Implements == nil || !s.Implements(r.Implements)) {
+		return false
+	}
+	if r.Generic != nil && *r.Generic != s.Generic {
+		return false
+	}
+	return true
+}
+
+// String возвращает имя правила или описание его сопоставителей
+func (r *Rule) String() string {
+	if r.Name != "" {
+		return r.Name
+	}
+	var parts []string
+	for _, p := range [][2]string{
+		{"path", r.Path},
+		{"package", r.Package},
+		{"interface", r.Interface},
+		{"method", r.Method},
+		{"implements", r.Implements},
+	} {
+		if p[1] != "" {
+			parts = append(parts, p[0]+"="+p[1])
+		}
+	}
+	if r.Generic != nil {
+		parts = append(parts, fmt.Sprintf("generic=%t", *r.Generic))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Decide возвращает решение для файла, интерфейса или метода.
+// Сначала проверяются паттерны ignore и include, затем правила rules в порядке объявления:
+// правила ближайшего к файлу файла конфигурации проверяются раньше родительских
+func (c *Config) Decide(s Subject) Decision {
+	target := c
+	if s.File != "" {
+		if d := c.decidePath(s.File); d.Action != "" {
+			return d
+		}
+		target = c.forPath(s.File)
+	}
+
+	layers := target.layers()
+	for i := len(layers) - 1; i >= 0; i-- {
+		layer := layers[i]
+		subject := s
+		if s.File != "" {
+			subject.File = layer.relPath(s.File) // правила path отсчитываются от директории своего файла
+		}
+		for j := range layer.Rules {
+			if layer.Rules[j].Matches(subject) {
+				return Decision{Action: layer.Rules[j].Action, Rule: &layer.Rules[j]}
+			}
+		}
+	}
+	return Decision{}
+}
+
+// UsesImplements сообщает, есть ли правила implements: они проверяются
+// по информации о типах всех пакетов
+func (c *Config) UsesImplements() bool {
+	for _, layer := range c.all() {
+		for _, rule := range layer.Rules {
+			if rule.Implements != "" {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,168 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDecide(t *testing.T) {
+	cfg, err := loadContent(t, `ignore:
+  - "**/*.pb.go"
+rules:
+  - path: "**/generated/**"
+    action: ignore
+  - name: closers
+    implements: io.Closer
+    action: api
+  - package: "example.com/app/**"
+    interface: ".*Repository"
+    method: "Delete.*"
+    action: warn
+    reason: cleanup in progress
+  - generic: true
+    action: ignore
+  - package: "example.com/app/**"
+    action: error
+`)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	closer := func(iface string) bool { return iface == "io.Closer" }
+	tests := []struct {
+		name    string
+		subject Subject
+		action  Action
+		rule    string
+		line    int
+	}{
+		{
+			name:    "ignore pattern",
+			subject: Subject{File: filepath.Join("api", "user.pb.go")},
+			action:  ActionIgnore,
+			rule:    "path=**/*.pb.go",
+		},
+		{
+			name:    "path rule",
+			subject: Subject{File: filepath.Join("internal", "generated", "api.go")},
+			action:  ActionIgnore,
+			rule:    "path=**/generated/**",
+			line:    4,
+		},
+		{
+			name:    "semantic rules do not apply to files",
+			subject: Subject{File: filepath.Join("example.com", "app", "repo.go")},
+		},
+		{
+			name:    "implements",
+			subject: Subject{PkgPath: "example.com/app", Interface: "Conn", Method: "Flush", Implements: closer},
+			action:  ActionAPI,
+			rule:    "closers",
+			line:    6,
+		},
+		{
+			name:    "interface and method",
+			subject: Subject{PkgPath: "example.com/app/user", Interface: "UserRepository", Method: "DeleteAll"},
+			action:  ActionWarn,
+			rule:    "package=example.com/app/**, interface=.*Repository, method=Delete.*",
+			line:    9,
+		},
+		{
+			name:    "method rule does not apply to interface",
+			subject: Subject{PkgPath: "example.com/app/user", Interface: "UserRepository"},
+			action:  ActionError,
+			rule:    "package=example.com/app/**",
+			line:    16,
+		},
+		{
+			name:    "generic",
+			subject: Subject{PkgPath: "example.com/app", Interface: "Pool", Generic: true},
+			action:  ActionIgnore,
+			rule:    "generic=true",
+			line:    14,
+		},
+		{
+			name:    "no rule",
+			subject: Subject{PkgPath: "example.com/lib", Interface: "Conn", Method: "Close"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := cfg.Decide(tt.subject)
+			if d.Action != tt.action {
+				t.Errorf("Decide().Action = %q, want %q", d.Action, tt.action)
+			}
+			if tt.rule == "" {
+				if d.Rule != nil {
+					t.Errorf("Decide().Rule = %v, want nil", d.Rule)
+				}
+				return
+			}
+			if d.Rule == nil || d.Rule.String() != tt.rule || d.Rule.Line != tt.line {
+				t.Errorf("Decide().Rule = %v, want %q at line %d", d.Rule, tt.rule, tt.line)
+			}
+		})
+	}
+
+	if !cfg.ShouldIgnore(filepath.Join("internal", "generated", "api.go")) {
+		t.Error("ShouldIgnore() must follow path rules with action ignore")
+	}
+}
+
+func TestUsesImplements(t *testing.T) {
+	cfg, err := loadContent(t, "rules:\n  - implements: io.Closer\n    action: api\n")
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if !cfg.UsesImplements() {
+		t.Error("UsesImplements() = false, want true")
+	}
+	if DefaultConfig().UsesImplements() {
+		t.Error("DefaultConfig().UsesImplements() = true, want false")
+	}
+}
+
+func TestRuleErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "missing action",
+			content: "rules:\n  - interface: Foo\n",
+			wantErr: "rule must set action",
+		},
+		{
+			name:    "unknown action",
+			content: "rules:\n  - interface: Foo\n    action: skip\n",
+			wantErr: `unknown rule action "skip"`,
+		},
+		{
+			name:    "empty matcher",
+			content: "rules:\n  - action: ignore\n",
+			wantErr: "rule must set path, package",
+		},
+		{
+			name:    "invalid interface regexp",
+			content: "rules:\n  - interface: \"Foo(\"\n    action: warn\n",
+			wantErr: "invalid interface regexp",
+		},
+		{
+			name:    "unqualified implements",
+			content: "rules:\n  - implements: Closer\n    action: api\n",
+			wantErr: "implements must be a qualified name",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadContent(t, tt.content)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadConfig() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
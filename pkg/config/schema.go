@@ -0,0 +1,115 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"github.com/comerc/unused-interface-methods/pkg/results"
+)
+
+//go:generate sh -c "go run ../../cmd/v2 config schema > ../../unused-interface-methods.schema.json"
+
+// SchemaURL - адрес JSON Schema файла конфигурации для подсказок в редакторе
+const SchemaURL = "https://raw.githubusercontent.com/comerc/unused-interface-methods/main/unused-interface-methods.schema.json"
+
+// descriptions - описания ключей для редактора по "Тип.ключ"
+var descriptions = map[string]string{
+	"Config.root":           "Do not inherit config files from parent directories",
+	"Config.extends":        "Path to a shared config file, relative to this file",
+	"Config.ignore":         "Paths excluded from analysis, .gitignore-style; \"!pattern\" brings a path back",
+	"Config.include":        "Analyze only Go files matching these patterns; empty means all files",
+	"Config.exclude":        "Suppress unused methods by package, interface and method",
+	"Config.rules":          "Rules by path, package, interface or method; the first matching rule wins",
+	"Config.severity":       "Severity per finding kind; off hides findings of the kind",
+	"Config.fail-on":        "Lowest severity that fails the run (exit code 1 or 3); off never fails",
+	"Config.build-matrix":   "GOOS/GOARCH/tags combinations; a method is reported only when it is unused in every one",
+	"Build.goos":            "Target operating system, e.g. linux; empty means the current platform",
+	"Build.goarch":          "Target architecture, e.g. arm64; empty means the current platform",
+	"Build.tags":            "Build tags, e.g. integration",
+	"ExcludeRule.package":   "Package path glob, e.g. example.com/app/legacy/**",
+	"ExcludeRule.interface": "Regular expression for the whole interface name",
+	"ExcludeRule.method":    "Regular expression for the whole method name",
+	"ExcludeRule.reason":    "Why the methods are kept",
+	"ExcludeRule.owner":     "Who is responsible for the entry",
+	"ExcludeRule.until":     "Last day the entry applies, YYYY-MM-DD",
+	"Rule.name":             "Rule name shown in reports",
+	"Rule.path":             "File path glob, as in ignore",
+	"Rule.package":          "Package path glob",
+	"Rule.interface":        "Regular expression for the whole interface name",
+	"Rule.method":           "Regular expression for the whole method name",
+	"Rule.implements":       "External interface the interface implements, e.g. io.Closer",
+	"Rule.generic":          "Match interfaces with or without type parameters",
+	"Rule.action":           "ignore: skip the method; warn: report as warning; error: report as error; api: treat as used public API",
+	"Rule.reason":           "Why the rule exists",
+}
+
+// required - обязательные ключи по имени типа
+var required = map[string][]string{
+	"Rule": {"action"},
+}
+
+// Schema возвращает JSON Schema файла конфигурации, построенную по структуре Config
+func Schema() ([]byte, error) {
+	schema := objectSchema(reflect.TypeOf(Config{}))
+	schema["$schema"] = "http://json-schema.org/draft-07/schema#"
+	schema["$id"] = SchemaURL
+	schema["title"] = "unused-interface-methods config"
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// objectSchema описывает структуру с yaml-тегами; лишние ключи запрещены, как при загрузке
+func objectSchema(t reflect.Type) map[string]any {
+	properties := make(map[string]any)
+	for i := 0; i < t.NumField(); i++ {
+		name := yamlName(t.Field(i))
+		if name == "" {
+			continue
+		}
+		property := typeSchema(t.Field(i).Type)
+		if d := descriptions[t.Name()+"."+name]; d != "" {
+			property["description"] = d
+		}
+		properties[name] = property
+	}
+
+	schema := map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if keys := required[t.Name()]; len(keys) > 0 {
+		schema["required"] = keys
+	}
+	return schema
+}
+
+// typeSchema описывает тип значения ключа
+func typeSchema(t reflect.Type) map[string]any {
+	switch t {
+	case reflect.TypeOf(Action("")):
+		return map[string]any{"type": "string", "enum": []Action{ActionIgnore, ActionWarn, ActionError, ActionAPI}}
+	case reflect.TypeOf(Date{}):
+		return map[string]any{"type": "string", "format": "date"}
+	case reflect.TypeOf(results.Severity("")):
+		return map[string]any{"type": "string", "enum": results.Severities}
+	case reflect.TypeOf(map[results.RuleID]results.Severity{}):
+		kinds := make(map[string]any)
+		for _, kind := range results.Kinds {
+			kinds[string(kind)] = typeSchema(t.Elem())
+		}
+		return map[string]any{"type": "object", "properties": kinds, "additionalProperties": false}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return typeSchema(t.Elem())
+	case reflect.Slice:
+		return map[string]any{"type": "array", "items": typeSchema(t.Elem())}
+	case reflect.Struct:
+		return objectSchema(t)
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	default:
+		return map[string]any{"type": "string"}
+	}
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/comerc/unused-interface-methods/pkg/results"
+)
+
+// SeverityFor возвращает серьезность вида находок из ближайшего к файлу файла конфигурации,
+// где она задана. Пустой путь - корневая конфигурация. ok = false - серьезность не настроена
+func (c *Config) SeverityFor(kind results.RuleID, filePath string) (severity results.Severity, ok bool) {
+	layers := c.layers()
+	if filePath != "" {
+		layers = c.forPath(filePath).layers()
+	}
+	for i := len(layers) - 1; i >= 0; i-- {
+		if severity, ok = layers[i].Severity[kind]; ok {
+			return severity, true
+		}
+	}
+	return "", false
+}
+
+// FailOnLevel возвращает порог fail-on корневой конфигурации.
+// По умолчанию запуск проваливают только находки с серьезностью error
+func (c *Config) FailOnLevel() results.Severity {
+	layers := c.layers()
+	for i := len(layers) - 1; i >= 0; i-- {
+		if layers[i].FailOn != "" {
+			return layers[i].FailOn
+		}
+	}
+	return results.SeverityError
+}
+
+// checkSeverities проверяет виды находок и значения серьезности в ключах severity и fail-on
+func checkSeverities(value *yaml.Node) error {
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		key, val := value.Content[i], value.Content[i+1]
+		switch key.Value {
+		case "fail-on":
+			if err := checkSeverity(val); err != nil {
+				return err
+			}
+		case "severity":
+			if val.Kind != yaml.MappingNode {
+				continue // ошибку типа уже вернул Decode
+			}
+			for j := 0; j+1 < len(val.Content); j += 2 {
+				kind := val.Content[j]
+				if !slices.Contains(results.Kinds, results.RuleID(kind.Value)) {
+					return fmt.Errorf("line %d: unknown finding kind %q (supported: %s)", kind.Line, kind.Value, joinValues(results.Kinds))
+				}
+				if err := checkSeverity(val.Content[j+1]); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// checkSeverity проверяет значение серьезности
+func checkSeverity(value *yaml.Node) error {
+	if !slices.Contains(results.Severities, results.Severity(value.Value)) {
+		return fmt.Errorf("line %d: invalid severity %q (supported: %s)", value.Line, value.Value, joinValues(results.Severities))
+	}
+	return nil
+}
+
+// joinValues перечисляет строковые значения через запятую
+func joinValues[T ~string](values []T) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = string(v)
+	}
+	return strings.Join(parts, ", ")
+}
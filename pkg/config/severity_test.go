@@ -0,0 +1,89 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/comerc/unused-interface-methods/pkg/results"
+)
+
+func TestSeverity(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		".unused-interface-methods.yml": `severity:
+  unused-method: warning
+  load-error: error
+fail-on: warning
+`,
+		"legacy/.unused-interface-methods.yml": `severity:
+  unused-method: off
+fail-on: off
+`,
+	})
+
+	cfg, err := Load(root)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	tests := []struct {
+		kind  results.RuleID
+		file  string
+		want  results.Severity
+		isSet bool
+	}{
+		{results.RuleUnusedMethod, filepath.Join(root, "api", "api.go"), results.SeverityWarning, true},
+		{results.RuleUnusedMethod, filepath.Join(root, "legacy", "old.go"), results.SeverityOff, true},
+		{results.RuleLoadError, filepath.Join(root, "legacy", "old.go"), results.SeverityError, true}, // наследуется от корня
+		{results.RuleLoadError, "", results.SeverityError, true},
+		{results.RuleGenericSkipped, "", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := cfg.SeverityFor(tt.kind, tt.file)
+		if got != tt.want || ok != tt.isSet {
+			t.Errorf("SeverityFor(%s, %s) = %q, %v, want %q, %v", tt.kind, tt.file, got, ok, tt.want, tt.isSet)
+		}
+	}
+
+	// fail-on берется из корневой конфигурации, вложенные файлы на него не влияют
+	if got := cfg.FailOnLevel(); got != results.SeverityWarning {
+		t.Errorf("FailOnLevel() = %q, want %q", got, results.SeverityWarning)
+	}
+	if got := DefaultConfig().FailOnLevel(); got != results.SeverityError {
+		t.Errorf("DefaultConfig().FailOnLevel() = %q, want %q", got, results.SeverityError)
+	}
+}
+
+func TestSeverityErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "unknown kind",
+			content: "severity:\n  unused: warning\n",
+			wantErr: `line 2: unknown finding kind "unused"`,
+		},
+		{
+			name:    "invalid severity",
+			content: "severity:\n  test-only: fatal\n",
+			wantErr: `line 2: invalid severity "fatal" (supported: error, warning, info, off)`,
+		},
+		{
+			name:    "invalid fail-on",
+			content: "fail-on: always\n",
+			wantErr: `line 1: invalid severity "always"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadContent(t, tt.content)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadConfig() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
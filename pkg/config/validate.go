@@ -0,0 +1,115 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// UnmarshalYAML разбирает конфигурацию и отклоняет неизвестные ключи:
+// опечатка вроде "ignores:" иначе молча отключила бы фильтрацию
+func (c *Config) UnmarshalYAML(value *yaml.Node) error {
+	if err := checkFields(value, reflect.TypeOf(*c)); err != nil {
+		return err
+	}
+	type plain Config // без метода UnmarshalYAML, чтобы избежать рекурсии
+	if err := value.Decode((*plain)(c)); err != nil {
+		return err
+	}
+	return checkSeverities(value)
+}
+
+// LoadFile загружает явно указанный файл конфигурации без поиска по директориям.
+// В отличие от LoadConfig отсутствие файла - ошибка
+func LoadFile(configPath string) (*Config, error) {
+	if _, err := os.Stat(configPath); err != nil {
+		return nil, err
+	}
+	return loadFile(configPath, DefaultConfig(), "", nil)
+}
+
+// Files возвращает пути всех загруженных файлов конфигурации, включая подключенные через extends
+func (c *Config) Files() []string {
+	var files []string
+	for _, layer := range c.all() {
+		if layer.path != "" {
+			files = append(files, layer.path)
+		}
+	}
+	return files
+}
+
+// checkFields проверяет, что ключи mapping-узла есть среди yaml-тегов структуры t
+func checkFields(value *yaml.Node, t reflect.Type) error {
+	if value.Kind != yaml.MappingNode {
+		return nil
+	}
+	known := fieldNames(t)
+	for i := 0; i < len(value.Content); i += 2 {
+		key := value.Content[i]
+		if slices.Contains(known, key.Value) {
+			continue
+		}
+		if s := suggest(key.Value, known); s != "" {
+			return fmt.Errorf("line %d: unknown field %q (did you mean %q?)", key.Line, key.Value, s)
+		}
+		return fmt.Errorf("line %d: unknown field %q (supported: %s)", key.Line, key.Value, strings.Join(known, ", "))
+	}
+	return nil
+}
+
+// fieldNames возвращает имена yaml-ключей структуры в порядке объявления полей
+func fieldNames(t reflect.Type) []string {
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		if name := yamlName(t.Field(i)); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// yamlName возвращает имя ключа поля или пустую строку для полей вне yaml
+func yamlName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+	if !f.IsExported() || name == "-" {
+		return ""
+	}
+	return name
+}
+
+// suggest возвращает известный ключ, ближайший к опечатке, или пустую строку
+func suggest(key string, known []string) string {
+	best, bestDistance := "", 3 // подсказываем при расстоянии не больше 2
+	for _, name := range known {
+		if d := distance(key, name); d < bestDistance {
+			best, bestDistance = name, d
+		}
+	}
+	return best
+}
+
+// distance - расстояние Левенштейна между строками
+func distance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,121 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUnknownFields(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "typo with suggestion",
+			content: "ignores:\n  - \"vendor/**\"\n",
+			wantErr: `line 1: unknown field "ignores" (did you mean "ignore"?)`,
+		},
+		{
+			name:    "unknown field",
+			content: "ignore: []\nlanguage: go\n",
+			wantErr: `line 2: unknown field "language" (supported: root, extends, ignore, include, exclude, rules, severity, fail-on, build-matrix)`,
+		},
+		{
+			name:    "rule field",
+			content: "rules:\n  - interface: Foo\n    actoin: warn\n",
+			wantErr: `line 3: unknown field "actoin" (did you mean "action"?)`,
+		},
+		{
+			name:    "exclude field",
+			content: "exclude:\n  - method: Foo\n    expires: 2030-01-01\n",
+			wantErr: `line 3: unknown field "expires"`,
+		},
+		{
+			name:    "internal field",
+			content: "rules:\n  - interface: Foo\n    action: warn\n    line: 3\n",
+			wantErr: `line 4: unknown field "line"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadContent(t, tt.content)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadConfig() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	if _, err := LoadFile(filepath.Join(t.TempDir(), "missing.yml")); err == nil {
+		t.Error("LoadFile() error = nil, want error for missing file")
+	}
+}
+
+func TestSchemaUpToDate(t *testing.T) {
+	schema, err := Schema()
+	if err != nil {
+		t.Fatalf("Schema() error = %v", err)
+	}
+	committed, err := os.ReadFile(filepath.Join("..", "..", "unused-interface-methods.schema.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(append(schema, '\n'), committed) {
+		t.Error("unused-interface-methods.schema.json is outdated, run go generate ./pkg/config")
+	}
+}
+
+func TestInit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), defaultConfigName)
+	var stdout, stderr bytes.Buffer
+
+	if code, ok := RunCommand([]string{"init", path}, &stdout, &stderr); !ok || code != 0 {
+		t.Fatalf("init = %d, %v: %s", code, ok, stderr.String())
+	}
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	if !reflect.DeepEqual(cfg.Ignore, DefaultConfig().Ignore) {
+		t.Errorf("init wrote ignore = %v, want defaults", cfg.Ignore)
+	}
+
+	// Существующий файл перезаписывается только с -force
+	if code, _ := RunCommand([]string{"init", path}, &stdout, &stderr); code != ExitConfig {
+		t.Errorf("init over existing file = %d, want %d", code, ExitConfig)
+	}
+	if code, _ := RunCommand([]string{"init", "-force", path}, &stdout, &stderr); code != 0 {
+		t.Errorf("init -force = %d, want 0", code)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"good.yml": "ignore:\n  - \"vendor/**\"\n",
+		"bad.yml":  "ignores:\n  - \"vendor/**\"\n",
+	})
+	var stdout, stderr bytes.Buffer
+
+	code, ok := RunCommand([]string{"config", "validate", filepath.Join(dir, "good.yml"), filepath.Join(dir, "bad.yml")}, &stdout, &stderr)
+	if !ok || code != ExitConfig {
+		t.Errorf("config validate = %d, %v, want %d", code, ok, ExitConfig)
+	}
+	if !strings.Contains(stdout.String(), "good.yml: OK") {
+		t.Errorf("stdout = %q, want good.yml: OK", stdout.String())
+	}
+	if !strings.Contains(stderr.String(), `bad.yml: line 1: unknown field "ignores"`) {
+		t.Errorf("stderr = %q, want unknown field error", stderr.String())
+	}
+
+	if _, ok := RunCommand([]string{"./config"}, &stdout, &stderr); ok {
+		t.Error("RunCommand() must not handle analysis paths")
+	}
+}
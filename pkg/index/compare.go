@@ -0,0 +1,97 @@
+package index
+
+import (
+	"fmt"
+	"io"
+)
+
+// Changes - изменение использования методов интерфейсов между двумя индексами.
+// Методы сравниваются по полному имени: перенос в другую строку или файл не изменение
+type Changes struct {
+	AddedInterfaces   []Interface    // интерфейсы, объявленные только в новом индексе
+	RemovedInterfaces []Interface    // интерфейсы, объявленные только в старом индексе
+	BecameUnused      []MethodResult // методы, которые перестали использоваться
+	BecameUsed        []MethodResult // методы, которые стали использоваться
+	AddedUnused       []MethodResult // методы, добавленные после старого индекса и не используемые в новом
+}
+
+// Empty сообщает, что использование методов не изменилось
+func (c *Changes) Empty() bool {
+	return len(c.AddedInterfaces) == 0 && len(c.RemovedInterfaces) == 0 &&
+		len(c.BecameUnused) == 0 && len(c.BecameUsed) == 0 && len(c.AddedUnused) == 0
+}
+
+// Compare сравнивает индекс before с индексом after. Методы новых интерфейсов
+// тоже считаются добавленными
+func Compare(before, after *Index) *Changes {
+	c := &Changes{}
+	old := make(map[string]Interface, len(before.Interfaces))
+	for _, iface := range before.Interfaces {
+		old[iface.ID()] = iface
+	}
+	current := make(map[string]bool, len(after.Interfaces))
+
+	for _, iface := range after.Interfaces {
+		current[iface.ID()] = true
+		prev, existed := old[iface.ID()]
+		if !existed {
+			c.AddedInterfaces = append(c.AddedInterfaces, iface)
+		}
+		verdicts := make(map[string]string, len(prev.Methods))
+		for _, m := range prev.Methods {
+			verdicts[m.Name] = m.Verdict
+		}
+		for _, m := range iface.Methods {
+			result := MethodResult{Package: iface.Package, Interface: iface.Name, Method: m}
+			verdict, declared := verdicts[m.Name]
+			switch {
+			case !declared:
+				if m.Verdict == "unused" {
+					c.AddedUnused = append(c.AddedUnused, result)
+				}
+			case verdict == m.Verdict:
+			case m.Verdict == "unused":
+				c.BecameUnused = append(c.BecameUnused, result)
+			case verdict == "unused":
+				c.BecameUsed = append(c.BecameUsed, result)
+			}
+		}
+	}
+	for _, iface := range before.Interfaces {
+		if !current[iface.ID()] {
+			c.RemovedInterfaces = append(c.RemovedInterfaces, iface)
+		}
+	}
+	return c
+}
+
+// WriteText выводит изменения по разделам; пустые разделы пропускаются
+func (c *Changes) WriteText(w io.Writer) {
+	if c.Empty() {
+		fmt.Fprintln(w, "No changes in interface method usage")
+		return
+	}
+	interfaces := func(title, mark string, list []Interface) {
+		if len(list) == 0 {
+			return
+		}
+		fmt.Fprintf(w, "%s:\n", title)
+		for _, iface := range list {
+			fmt.Fprintf(w, "  %s %s (%s:%d): %s\n", mark, iface.ID(), iface.File, iface.Line, plural(len(iface.Methods), "method"))
+		}
+	}
+	methods := func(title string, list []MethodResult) {
+		if len(list) == 0 {
+			return
+		}
+		fmt.Fprintf(w, "%s:\n", title)
+		for _, m := range list {
+			fmt.Fprintf(w, "  %s.%s.%s%s (%s:%d)\n", m.Package, m.Interface, m.Name, m.Signature, m.File, m.Line)
+		}
+	}
+	interfaces("Interfaces added", "+", c.AddedInterfaces)
+	interfaces("Interfaces removed", "-", c.RemovedInterfaces)
+	methods("Became unused", c.BecameUnused)
+	methods("Became used", c.BecameUsed)
+	methods("Added and never used", c.AddedUnused)
+}
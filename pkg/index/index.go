@@ -0,0 +1,218 @@
+package index
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/comerc/unused-interface-methods/pkg/config"
+	"github.com/comerc/unused-interface-methods/pkg/linter"
+	"github.com/comerc/unused-interface-methods/pkg/results"
+)
+
+// Version - версия формата индекса. Увеличивается при любом несовместимом изменении
+const Version = 1
+
+// DefaultFile - файл индекса по умолчанию
+const DefaultFile = "unused-interface-methods.index.json"
+
+// Index - модель проанализированного кода: интерфейсы, их методы, встроенные интерфейсы,
+// реализации и места вызовов. Запросы к индексу не загружают пакеты
+type Index struct {
+	Version    int         `json:"version"`
+	Interfaces []Interface `json:"interfaces"`
+}
+
+// Interface - интерфейс со встроенными интерфейсами и конкретными типами, которые его реализуют
+type Interface struct {
+	Package      string   `json:"package"`
+	Name         string   `json:"name"`
+	File         string   `json:"file"`
+	Line         int      `json:"line"`
+	TypeParams   string   `json:"type_params,omitempty"` // методы дженериков не анализируются
+	Embeds       []string `json:"embeds"`
+	Implementers []string `json:"implementers"`
+	Methods      []Method `json:"methods"`
+}
+
+// Method - метод интерфейса с вердиктом и всеми найденными использованиями
+type Method struct {
+	Name            string           `json:"name"`
+	Signature       string           `json:"signature"`
+	File            string           `json:"file"`
+	Line            int              `json:"line"`
+	Verdict         string           `json:"verdict"`
+	CallSites       []Site           `json:"call_sites"`
+	Uses            []Use            `json:"uses"` // остальные использования: обращения без вызова, поля, reflect
+	Implementations []Implementation `json:"implementations"`
+}
+
+// Site - место в исходном коде
+type Site struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+}
+
+// Use - использование метода, кроме вызова
+type Use struct {
+	Kind string `json:"kind"`
+	Site
+}
+
+// Implementation - метод конкретного типа, реализующий метод интерфейса
+type Implementation struct {
+	Type string `json:"type"`
+	Site
+}
+
+// ID возвращает полное имя интерфейса pkg.Name
+func (i Interface) ID() string {
+	return i.Package + "." + i.Name
+}
+
+// Build строит индекс по объявлениям интерфейсов и находкам анализа со всеми использованиями.
+// Встроенные интерфейсы входят в индекс из объявлений, а не из находок
+func Build(decls []linter.InterfaceDecl, findings []results.Finding) *Index {
+	idx := &Index{Version: Version, Interfaces: []Interface{}}
+	byID := make(map[string]int)
+	lookup := func(pkgPath, name string, position results.Position) *Interface {
+		id := pkgPath + "." + name
+		if i, ok := byID[id]; ok {
+			return &idx.Interfaces[i]
+		}
+		byID[id] = len(idx.Interfaces)
+		site := newSite(position)
+		idx.Interfaces = append(idx.Interfaces, Interface{
+			Package: pkgPath, Name: name, File: site.File, Line: site.Line,
+			Embeds: []string{}, Implementers: []string{}, Methods: []Method{},
+		})
+		return &idx.Interfaces[len(idx.Interfaces)-1]
+	}
+
+	// Объявление из нескольких конфигураций сборки объединяется
+	for _, decl := range decls {
+		iface := lookup(decl.PkgPath, decl.Name, decl.Position)
+		iface.TypeParams = decl.TypeParams
+		iface.Embeds = union(iface.Embeds, decl.Embeds)
+		iface.Implementers = union(iface.Implementers, decl.Implementers)
+	}
+
+	for _, f := range findings {
+		if f.RuleID() == results.RuleUnusedEmbed {
+			continue // встроенный интерфейс - не метод
+		}
+		iface := lookup(f.PkgPath, f.Interface, f.Range.Start)
+		if hasMethod(iface.Methods, f.Method) {
+			continue
+		}
+		site := newSite(f.Range.Start)
+		method := Method{
+			Name: f.Method, Signature: f.Signature, File: site.File, Line: site.Line,
+			Verdict:   string(f.Verdict),
+			CallSites: []Site{}, Uses: []Use{}, Implementations: []Implementation{},
+		}
+		for _, e := range f.Evidence {
+			if e.Kind == results.EvidenceCall {
+				method.CallSites = append(method.CallSites, newSite(e.Position))
+			} else {
+				method.Uses = append(method.Uses, Use{Kind: string(e.Kind), Site: newSite(e.Position)})
+			}
+		}
+		for _, impl := range f.Implementations {
+			method.Implementations = append(method.Implementations, Implementation{Type: impl.Type, Site: newSite(impl.Position)})
+		}
+		iface.Methods = append(iface.Methods, method)
+	}
+
+	sort.Slice(idx.Interfaces, func(i, j int) bool {
+		return idx.Interfaces[i].ID() < idx.Interfaces[j].ID()
+	})
+	for i := range idx.Interfaces {
+		iface := &idx.Interfaces[i]
+		sort.SliceStable(iface.Methods, func(i, j int) bool {
+			a, b := iface.Methods[i], iface.Methods[j]
+			if a.File != b.File {
+				return a.File < b.File
+			}
+			return a.Line < b.Line
+		})
+		sort.Strings(iface.Implementers)
+	}
+	return idx
+}
+
+// Write записывает индекс в файл
+func (idx *Index) Write(path string) error {
+	data, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// Load читает индекс из файла и проверяет версию формата
+func Load(path string) (*Index, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var idx Index
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if idx.Version != Version {
+		return nil, fmt.Errorf("%s: unsupported index version %d (expected %d), rebuild the index", path, idx.Version, Version)
+	}
+	return &idx, nil
+}
+
+// Find ищет интерфейсы по полному имени pkg.Name. Путь пакета можно сократить
+// до последних элементов или опустить; точное совпадение важнее
+func (idx *Index) Find(id string) []Interface {
+	var exact, partial []Interface
+	for _, iface := range idx.Interfaces {
+		switch full := iface.ID(); {
+		case full == id:
+			exact = append(exact, iface)
+		case strings.HasSuffix(full, "/"+id), iface.Name == id:
+			partial = append(partial, iface)
+		}
+	}
+	if len(exact) > 0 {
+		return exact
+	}
+	return partial
+}
+
+// newSite преобразует позицию в место относительно текущей директории, как в JSON-отчете
+func newSite(p results.Position) Site {
+	file := p.File
+	if file != "" {
+		file = filepath.ToSlash(config.GetRelativePath(file))
+	}
+	return Site{File: file, Line: p.Line, Column: p.Column}
+}
+
+// union добавляет в список отсутствующие в нем строки
+func union(list, add []string) []string {
+	for _, s := range add {
+		if !slices.Contains(list, s) {
+			list = append(list, s)
+		}
+	}
+	return list
+}
+
+func hasMethod(methods []Method, name string) bool {
+	for _, m := range methods {
+		if m.Name == name {
+			return true
+		}
+	}
+	return false
+}
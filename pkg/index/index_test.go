@@ -0,0 +1,194 @@
+package index
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/comerc/unused-interface-methods/pkg/linter"
+	"github.com/comerc/unused-interface-methods/pkg/results"
+)
+
+// sample строит индекс из двух интерфейсов: Store с реализацией и встроенным io.Closer
+// и Logger без реализаций
+func sample() *Index {
+	decls := []linter.InterfaceDecl{
+		{PkgPath: "example.com/app/store", Name: "Store", Position: results.Position{File: "store/store.go", Line: 5},
+			Embeds: []string{"io.Closer"}, Implementers: []string{"*example.com/app/store.mem"}},
+		{PkgPath: "example.com/app/log", Name: "Logger", Position: results.Position{File: "log/log.go", Line: 3}, Embeds: []string{}},
+		// То же объявление из другой конфигурации сборки
+		{PkgPath: "example.com/app/store", Name: "Store", Position: results.Position{File: "store/store.go", Line: 5},
+			Embeds: []string{"io.Closer"}, Implementers: []string{"example.com/app/store.disk"}},
+	}
+	method := func(pkgPath, iface, name string, line int, verdict results.Verdict, evidence ...results.Evidence) results.Finding {
+		return results.Finding{
+			PkgPath: pkgPath, Interface: iface, Method: name, Signature: "()",
+			Range:   results.Range{Start: results.Position{File: "x.go", Line: line}},
+			Verdict: verdict, Evidence: evidence,
+		}
+	}
+	call := func(line int) results.Evidence {
+		return results.Evidence{Kind: results.EvidenceCall, Position: results.Position{File: "main.go", Line: line}}
+	}
+	findings := []results.Finding{
+		method("example.com/app/store", "Store", "Put", 8, results.VerdictUsed, call(10)),
+		method("example.com/app/store", "Store", "Get", 7, results.VerdictUsed, call(11), call(12),
+			results.Evidence{Kind: results.EvidenceMethodValue, Position: results.Position{File: "main.go", Line: 13}}),
+		method("example.com/app/log", "Logger", "Debug", 4, results.VerdictUnused),
+		{PkgPath: "example.com/app/store", Interface: "Store", Method: "io.Closer", Verdict: results.VerdictUnused, Kind: results.RuleUnusedEmbed},
+	}
+	findings[2].Kind = results.RuleTestOnly
+	return Build(decls, findings)
+}
+
+func TestBuild(t *testing.T) {
+	idx := sample()
+	if !assert.Len(t, idx.Interfaces, 2) {
+		return
+	}
+	logger, store := idx.Interfaces[0], idx.Interfaces[1]
+	assert.Equal(t, "example.com/app/log.Logger", logger.ID())
+	assert.Len(t, logger.Methods, 1, "метод, используемый только в тестах, входит в индекс")
+
+	assert.Equal(t, []string{"io.Closer"}, store.Embeds)
+	assert.Equal(t, []string{"*example.com/app/store.mem", "example.com/app/store.disk"}, store.Implementers)
+	if assert.Len(t, store.Methods, 2, "встроенный интерфейс не входит в методы") {
+		get := store.Methods[0]
+		assert.Equal(t, "Get", get.Name, "методы упорядочены по строкам")
+		assert.Equal(t, []Site{{File: "main.go", Line: 11}, {File: "main.go", Line: 12}}, get.CallSites)
+		assert.Equal(t, []Use{{Kind: "method-value", Site: Site{File: "main.go", Line: 13}}}, get.Uses)
+	}
+
+	// Индекс читается без изменений; другая версия формата не читается
+	path := filepath.Join(t.TempDir(), "index.json")
+	assert.NoError(t, idx.Write(path))
+	loaded, err := Load(path)
+	assert.NoError(t, err)
+	assert.Equal(t, idx, loaded)
+
+	assert.NoError(t, os.WriteFile(path, []byte(`{"version": 99}`), 0644))
+	_, err = Load(path)
+	assert.ErrorContains(t, err, "unsupported index version 99")
+}
+
+func TestQuery(t *testing.T) {
+	idx := sample()
+	interfaces := func(args ...string) []string {
+		t.Helper()
+		q, err := ParseQuery(append([]string{"interfaces"}, args...))
+		assert.NoError(t, err)
+		var ids []string
+		for _, iface := range idx.SelectInterfaces(q) {
+			ids = append(ids, iface.ID())
+		}
+		return ids
+	}
+	methods := func(args ...string) []string {
+		t.Helper()
+		q, err := ParseQuery(append([]string{"methods"}, args...))
+		assert.NoError(t, err)
+		var ids []string
+		for _, m := range idx.SelectMethods(q) {
+			ids = append(ids, m.Interface+"."+m.Name)
+		}
+		return ids
+	}
+
+	assert.Equal(t, []string{"example.com/app/store.Store"}, interfaces("methods>1"))
+	assert.Equal(t, []string{"example.com/app/log.Logger"}, interfaces("implementers=0", "unused>=1"))
+	assert.Equal(t, []string{"example.com/app/store.Store"}, interfaces("package=**/store", "embeds=1"))
+	assert.Empty(t, interfaces("methods>8"))
+
+	assert.Equal(t, []string{"Store.Put"}, methods("calls=1"))
+	assert.Equal(t, []string{"Store.Get"}, methods("uses>2"))
+	assert.Equal(t, []string{"Logger.Debug"}, methods("verdict!=used"))
+
+	for _, args := range [][]string{
+		{},
+		{"types"},
+		{"interfaces", "methods"},
+		{"interfaces", "calls=1"},
+		{"methods", "calls=one"},
+		{"methods", "name>Get"},
+		{"implementers"},
+	} {
+		_, err := ParseQuery(args)
+		assert.Error(t, err, "%q", args)
+	}
+}
+
+func TestRunCommand(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index.json")
+	assert.NoError(t, sample().Write(path))
+
+	run := func(args ...string) (string, string, int) {
+		var stdout, stderr bytes.Buffer
+		code, handled := RunCommand(append([]string{"query", "-index", path}, args...), &stdout, &stderr)
+		assert.True(t, handled)
+		return stdout.String(), stderr.String(), code
+	}
+
+	out, _, code := run("implementers", "store.Store")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "*example.com/app/store.mem\nexample.com/app/store.disk\n", out)
+
+	out, _, _ = run("interfaces", "methods=1")
+	assert.Equal(t, "example.com/app/log.Logger (log/log.go:3): 1 method, 0 implementers\n", out)
+
+	out, _, _ = run("methods", "calls=2")
+	assert.Equal(t, "example.com/app/store.Store.Get() (x.go:7): used, 2 call sites\n", out)
+
+	out, _, _ = run("-format", "json", "implementers", "Logger")
+	assert.JSONEq(t, "[]", out)
+
+	_, errOut, code := run("implementers", "Missing")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, errOut, "interface Missing not found")
+
+	_, handled := RunCommand([]string{"index"}, &bytes.Buffer{}, &bytes.Buffer{})
+	assert.False(t, handled)
+}
+
+func TestCompare(t *testing.T) {
+	before := sample()
+	after := sample()
+	// Logger удален; Store.Put перестал использоваться, Store.Get перенесен и по-прежнему используется;
+	// добавлены интерфейс Cache и метод Store.Delete без использований
+	after.Interfaces = after.Interfaces[1:]
+	store := &after.Interfaces[0]
+	store.Methods[0].Line = 20
+	store.Methods[1].Verdict = "unused"
+	store.Methods = append(store.Methods, Method{Name: "Delete", Verdict: "unused"}, Method{Name: "Len", Verdict: "used"})
+	after.Interfaces = append(after.Interfaces, Interface{Package: "example.com/app/store", Name: "Cache",
+		Methods: []Method{{Name: "Load", Verdict: "unused"}}})
+
+	c := Compare(before, after)
+	names := func(list []MethodResult) []string {
+		var ids []string
+		for _, m := range list {
+			ids = append(ids, m.Interface+"."+m.Name)
+		}
+		return ids
+	}
+	if assert.Len(t, c.AddedInterfaces, 1) {
+		assert.Equal(t, "Cache", c.AddedInterfaces[0].Name)
+	}
+	if assert.Len(t, c.RemovedInterfaces, 1) {
+		assert.Equal(t, "Logger", c.RemovedInterfaces[0].Name)
+	}
+	assert.Equal(t, []string{"Store.Put"}, names(c.BecameUnused))
+	assert.Empty(t, c.BecameUsed)
+	assert.Equal(t, []string{"Store.Delete", "Cache.Load"}, names(c.AddedUnused))
+
+	reverse := Compare(after, before)
+	assert.Equal(t, []string{"Store.Put"}, names(reverse.BecameUsed))
+	assert.Equal(t, []string{"Logger.Debug"}, names(reverse.AddedUnused), "методы нового интерфейса тоже добавлены")
+
+	var out bytes.Buffer
+	Compare(before, before).WriteText(&out)
+	assert.Equal(t, "No changes in interface method usage\n", out.String())
+	assert.True(t, Compare(before, before).Empty())
+}
@@ -0,0 +1,294 @@
+package index
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+
+	"github.com/comerc/unused-interface-methods/pkg/config"
+)
+
+// Query - запрос к индексу: что выбирать и условия отбора
+type Query struct {
+	Subject    string      // interfaces, methods или implementers
+	Target     string      // интерфейс для implementers
+	Conditions []Condition // все условия должны выполняться
+}
+
+// Condition - условие вида field>8 или package=**/store
+type Condition struct {
+	Field string
+	Op    string
+	Value string
+}
+
+// Поля условий: числовые сравниваются как числа, строковые - как паттерны doublestar,
+// как package и path в правилах конфигурации
+var (
+	interfaceFields = map[string]bool{"methods": true, "implementers": true, "embeds": true, "unused": true, "package": false, "name": false, "file": false}
+	methodFields    = map[string]bool{"calls": true, "uses": true, "implementations": true, "package": false, "interface": false, "name": false, "verdict": false, "file": false}
+)
+
+var conditionPattern = regexp.MustCompile(`^([a-z_]+)(>=|<=|!=|=|>|<)(.*)$`)
+
+// ParseQuery разбирает аргументы запроса:
+//
+//	interfaces [условие...]
+//	methods [условие...]
+//	implementers pkg.Interface
+func ParseQuery(args []string) (*Query, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("missing query subject: interfaces, methods or implementers")
+	}
+	q := &Query{Subject: args[0]}
+	var fields map[string]bool
+	switch q.Subject {
+	case "interfaces":
+		fields = interfaceFields
+	case "methods":
+		fields = methodFields
+	case "implementers":
+		if len(args) != 2 {
+			return nil, fmt.Errorf("implementers requires exactly one interface: pkg.Interface")
+		}
+		q.Target = args[1]
+		return q, nil
+	default:
+		return nil, fmt.Errorf("unknown query subject %q (supported: interfaces, methods, implementers)", q.Subject)
+	}
+
+	for _, arg := range args[1:] {
+		m := conditionPattern.FindStringSubmatch(arg)
+		if m == nil {
+			return nil, fmt.Errorf("invalid condition %q, expected field<op>value, e.g. methods>8", arg)
+		}
+		c := Condition{Field: m[1], Op: m[2], Value: m[3]}
+		numeric, ok := fields[c.Field]
+		if !ok {
+			return nil, fmt.Errorf("unknown field %q for %s (supported: %s)", c.Field, q.Subject, fieldNames(fields))
+		}
+		if numeric {
+			if _, err := strconv.Atoi(c.Value); err != nil {
+				return nil, fmt.Errorf("condition %q: %s is a number", arg, c.Field)
+			}
+		} else {
+			if c.Op != "=" && c.Op != "!=" {
+				return nil, fmt.Errorf("condition %q: %s supports only = and !=", arg, c.Field)
+			}
+			if !doublestar.ValidatePattern(c.Value) {
+				return nil, fmt.Errorf("condition %q: invalid pattern", arg)
+			}
+		}
+		q.Conditions = append(q.Conditions, c)
+	}
+	return q, nil
+}
+
+// MethodResult - метод, выбранный запросом, вместе с его интерфейсом
+type MethodResult struct {
+	Package   string `json:"package"`
+	Interface string `json:"interface"`
+	Method
+}
+
+// SelectInterfaces возвращает интерфейсы, удовлетворяющие условиям запроса
+func (idx *Index) SelectInterfaces(q *Query) []Interface {
+	found := []Interface{}
+	for _, iface := range idx.Interfaces {
+		unused := 0
+		for _, m := range iface.Methods {
+			if m.Verdict == "unused" {
+				unused++
+			}
+		}
+		values := map[string]any{
+			"methods":      len(iface.Methods),
+			"implementers": len(iface.Implementers),
+			"embeds":       len(iface.Embeds),
+			"unused":       unused,
+			"package":      iface.Package,
+			"name":         iface.Name,
+			"file":         iface.File,
+		}
+		if q.matches(values) {
+			found = append(found, iface)
+		}
+	}
+	return found
+}
+
+// SelectMethods возвращает методы, удовлетворяющие условиям запроса
+func (idx *Index) SelectMethods(q *Query) []MethodResult {
+	found := []MethodResult{}
+	for _, iface := range idx.Interfaces {
+		for _, m := range iface.Methods {
+			values := map[string]any{
+				"calls":           len(m.CallSites),
+				"uses":            len(m.CallSites) + len(m.Uses),
+				"implementations": len(m.Implementations),
+				"package":         iface.Package,
+				"interface":       iface.Name,
+				"name":            m.Name,
+				"verdict":         m.Verdict,
+				"file":            m.File,
+			}
+			if q.matches(values) {
+				found = append(found, MethodResult{Package: iface.Package, Interface: iface.Name, Method: m})
+			}
+		}
+	}
+	return found
+}
+
+// matches проверяет все условия запроса на значениях полей
+func (q *Query) matches(values map[string]any) bool {
+	for _, c := range q.Conditions {
+		switch v := values[c.Field].(type) {
+		case int:
+			n, _ := strconv.Atoi(c.Value)
+			if !compare(v, c.Op, n) {
+				return false
+			}
+		case string:
+			matched, _ := doublestar.Match(c.Value, v)
+			if matched != (c.Op == "=") {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func compare(a int, op string, b int) bool {
+	switch op {
+	case ">":
+		return a > b
+	case "<":
+		return a < b
+	case ">=":
+		return a >= b
+	case "<=":
+		return a <= b
+	case "!=":
+		return a != b
+	}
+	return a == b
+}
+
+// fieldNames перечисляет поля через запятую в алфавитном порядке
+func fieldNames(fields map[string]bool) string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// RunCommand выполняет подкоманду query по индексу, не загружая пакеты:
+//
+//	query [-index FILE] [-format text|json] interfaces|methods [условие...]
+//	query [-index FILE] [-format text|json] implementers pkg.Interface
+//
+// handled = false - аргументы не относятся к подкоманде
+func RunCommand(args []string, stdout, stderr io.Writer) (code int, handled bool) {
+	if len(args) == 0 || args[0] != "query" {
+		return 0, false
+	}
+
+	flags := flag.NewFlagSet("query", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	file := flags.String("index", DefaultFile, "Index file written by the index command")
+	format := flags.String("format", "text", "Output format: text, json")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: unused-interface-methods query [-index FILE] [-format text|json] interfaces|methods [condition...]")
+		fmt.Fprintln(stderr, "       unused-interface-methods query [-index FILE] [-format text|json] implementers pkg.Interface")
+		fmt.Fprintln(stderr, "Conditions: field<op>value with op =, !=, >, <, >=, <=; strings match as glob patterns (**/store)")
+		fmt.Fprintf(stderr, "  interfaces: %s\n", fieldNames(interfaceFields))
+		fmt.Fprintf(stderr, "  methods:    %s\n", fieldNames(methodFields))
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args[1:]); err != nil {
+		return config.ExitConfig, true
+	}
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(stderr, "Error: unknown format %q (supported: text, json)\n", *format)
+		return config.ExitConfig, true
+	}
+
+	q, err := ParseQuery(flags.Args())
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return config.ExitConfig, true
+	}
+	idx, err := Load(*file)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error loading index: %v\n", err)
+		return config.ExitConfig, true
+	}
+
+	var found any
+	switch q.Subject {
+	case "interfaces":
+		found = idx.SelectInterfaces(q)
+	case "methods":
+		found = idx.SelectMethods(q)
+	case "implementers":
+		ifaces := idx.Find(q.Target)
+		switch len(ifaces) {
+		case 0:
+			fmt.Fprintf(stderr, "Error: interface %s not found in the index\n", q.Target)
+			return config.ExitConfig, true
+		case 1:
+			found = ifaces[0].Implementers
+		default:
+			fmt.Fprintf(stderr, "Error: %s matches several interfaces, specify the package path:\n", q.Target)
+			for _, iface := range ifaces {
+				fmt.Fprintf(stderr, "  %s\n", iface.ID())
+			}
+			return config.ExitConfig, true
+		}
+	}
+
+	if *format == "json" {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(found); err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return config.ExitInternal, true
+		}
+		return config.ExitOK, true
+	}
+	switch found := found.(type) {
+	case []Interface:
+		for _, iface := range found {
+			fmt.Fprintf(stdout, "%s (%s:%d): %s, %s\n", iface.ID(), iface.File, iface.Line,
+				plural(len(iface.Methods), "method"), plural(len(iface.Implementers), "implementer"))
+		}
+	case []MethodResult:
+		for _, m := range found {
+			fmt.Fprintf(stdout, "%s.%s.%s%s (%s:%d): %s, %s\n", m.Package, m.Interface, m.Name, m.Signature,
+				m.File, m.Line, m.Verdict, plural(len(m.CallSites), "call site"))
+		}
+	case []string:
+		for _, name := range found {
+			fmt.Fprintln(stdout, name)
+		}
+	}
+	return config.ExitOK, true
+}
+
+// plural форматирует число с существительным в нужном числе: 1 method, 2 methods
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
@@ -0,0 +1,209 @@
+package linter
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/comerc/unused-interface-methods/pkg/cache"
+)
+
+// cacheFormat меняется вместе с packageFacts, чтобы не читать значения старого формата
+const cacheFormat = "4"
+
+// keyMode - данные пакетов для ключей кэша: файлы и граф импортов без проверки типов
+const keyMode = packages.NeedName | packages.NeedFiles | packages.NeedImports |
+	packages.NeedDeps | packages.NeedModule | packages.NeedForTest
+
+// SetCache включает кэш фактов пакетов; nil - кэш отключен
+func (l *UnusedMethodLinter) SetCache(c *cache.Cache) {
+	l.cache = c
+}
+
+// loadCached берет из кэша факты пакетов с неизменными ключами и загружает с типами
+// остальные. Ключ пакета включает ключи его зависимостей, поэтому изменение пакета
+// заново анализирует и все пакеты, которые его импортируют. С графом (SetGraph)
+// go list для ключей запускается только для пакетов измененных файлов
+func (l *UnusedMethodLinter) loadCached(dir string) error {
+	var (
+		roots []*packages.Package
+		keys  map[string]string
+	)
+	if l.graph != nil {
+		var err error
+		if roots, keys, err = l.graph.load(l, dir); err != nil {
+			return err
+		}
+	} else {
+		pkgs, err := packages.Load(l.packagesConfig(dir, keyMode), l.patterns()...)
+		if err != nil {
+			return fmt.Errorf("failed to load packages: %w", err)
+		}
+		roots, keys = withTests(pkgs), make(map[string]string)
+	}
+
+	build := l.buildKey()
+	l.cached = make(map[string]*packageFacts)
+	l.keys = make(map[string]string)
+	missing := make(map[string]bool) // ID пакетов без фактов в кэше
+	var patterns []string
+	for _, pkg := range l.filterPackages(roots) {
+		l.order = append(l.order, pkg.PkgPath)
+		key, err := packageKey(pkg, build, keys, l.overlay)
+		if err != nil {
+			missing[pkg.ID] = true // без ключа пакет анализируется, но не сохраняется
+			patterns = appendUnique(patterns, importPath(pkg))
+			continue
+		}
+		var facts packageFacts
+		if l.cache.Get(key, &facts) {
+			l.cached[pkg.PkgPath] = &facts
+			for _, e := range facts.LoadErrors {
+				if l.verbose {
+					l.logf("Warning: %s\n", e.Message)
+				}
+				l.loadErrors = append(l.loadErrors, e)
+			}
+			continue
+		}
+		l.keys[pkg.PkgPath] = key
+		missing[pkg.ID] = true
+		patterns = appendUnique(patterns, importPath(pkg))
+	}
+	if l.verbose {
+		l.logf("Cache: %d packages from %s, %d to analyze\n", len(l.cached), l.cache.Dir(), len(missing))
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	// Зависимости загружаемых пакетов берутся из данных экспорта, как и без кэша.
+	// Пакет загружается вместе с тестами, из вариантов остаются те, которых нет в кэше
+	loaded, err := packages.Load(l.packagesConfig(dir, loadMode), patterns...)
+	if err != nil {
+		return fmt.Errorf("failed to load packages: %w", err)
+	}
+	for _, pkg := range withTests(loaded) {
+		if !missing[pkg.ID] {
+			continue
+		}
+		l.packages = append(l.packages, pkg)
+		l.addLoadErrors(pkg.PkgPath, pkg.Errors)
+	}
+	return nil
+}
+
+// appendUnique добавляет строку в список, если ее там еще нет
+func appendUnique(list []string, s string) []string {
+	if contains(list, s) {
+		return list
+	}
+	return append(list, s)
+}
+
+// packageFacts возвращает факты всех пакетов в порядке загрузки: из кэша или собранные
+// по загруженным пакетам. Новые факты сохраняются в кэш
+func (l *UnusedMethodLinter) packageFacts() []*packageFacts {
+	if l.facts != nil {
+		return l.facts
+	}
+
+	loaded := make(map[string]*packages.Package, len(l.packages))
+	order := l.order
+	for _, pkg := range l.packages {
+		loaded[pkg.PkgPath] = pkg
+		if l.order == nil {
+			order = append(order, pkg.PkgPath)
+		}
+	}
+
+	l.facts = []*packageFacts{}
+	for _, path := range order {
+		facts := l.cached[path]
+		if facts == nil {
+			pkg := loaded[path]
+			if pkg == nil {
+				continue
+			}
+			facts = analyzePackage(pkg)
+			if key := l.keys[path]; key != "" {
+				if err := l.cache.Put(key, facts); err != nil && l.verbose {
+					l.logf("Warning: cache: %v\n", err)
+				}
+			}
+		}
+		l.facts = append(l.facts, facts)
+	}
+	return l.facts
+}
+
+// buildKey описывает конфигурацию сборки, с которой выбираются файлы пакетов
+func (l *UnusedMethodLinter) buildKey() string {
+	goos, goarch := l.goos, l.goarch
+	if goos == "" {
+		goos = envOr("GOOS", runtime.GOOS)
+	}
+	if goarch == "" {
+		goarch = envOr("GOARCH", runtime.GOARCH)
+	}
+	return strings.Join([]string{goos, goarch, strings.Join(l.tags, ","), os.Getenv("CGO_ENABLED")}, " ")
+}
+
+// packageKey вычисляет ключ пакета по конфигурации сборки, файлам и ключам импортов.
+// Файлы анализируемых модулей хешируются по содержимому (из overlay, если оно задано);
+// стандартная библиотека и кэш модулей не меняются на месте, поэтому для них достаточно
+// размера и времени изменения
+func packageKey(pkg *packages.Package, build string, keys map[string]string, overlay map[string][]byte) (string, error) {
+	if key, ok := keys[pkg.ID]; ok {
+		return key, nil
+	}
+
+	h := cache.NewHash(cacheFormat)
+	h.Add(build, pkg.ID, pkg.Name)
+	for _, err := range pkg.Errors {
+		h.Add(err.Error())
+	}
+
+	analyzed := pkg.Module != nil && pkg.Module.Main
+	for _, file := range append(append([]string{}, pkg.GoFiles...), pkg.OtherFiles...) {
+		var err error
+		if data, ok := overlay[file]; ok {
+			h.AddData(file, data)
+		} else if analyzed {
+			err = h.AddFile(file)
+		} else {
+			err = h.AddStat(file)
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+
+	paths := make([]string, 0, len(pkg.Imports))
+	for path := range pkg.Imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		key, err := packageKey(pkg.Imports[path], build, keys, overlay)
+		if err != nil {
+			return "", err
+		}
+		h.Add(path, key)
+	}
+
+	keys[pkg.ID] = h.Sum()
+	return keys[pkg.ID], nil
+}
+
+// envOr возвращает переменную окружения или значение по умолчанию
+func envOr(name, def string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return def
+}
@@ -0,0 +1,159 @@
+package linter
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/comerc/unused-interface-methods/pkg/cache"
+	"github.com/comerc/unused-interface-methods/pkg/config"
+	"github.com/comerc/unused-interface-methods/pkg/results"
+)
+
+// cacheModule - модуль, в котором service импортирует store, а audit ни от кого не зависит
+var cacheModule = map[string]string{
+	"go.mod": "module example.com/cached\n\ngo 1.21\n",
+	"store/store.go": `package store
+
+type Store interface {
+	Get() string
+	Put(v string)
+}
+
+type memory struct{}
+
+func (memory) Get() string { return "" }
+func (memory) Put(v string) {}
+
+func New() Store { return memory{} }
+`,
+	"service/service.go": `package service
+
+import "example.com/cached/store"
+
+func Run() string { return store.New().Get() }
+`,
+	"audit/audit.go": `package audit
+
+type Logger interface {
+	Log(msg string)
+}
+`,
+}
+
+// writeModule записывает файлы модуля в dir
+func writeModule(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+}
+
+// analyzeCached анализирует модуль с кэшем и возвращает результат и пакеты,
+// которые пришлось загрузить с типами
+func analyzeCached(t *testing.T, dir string, c *cache.Cache) (*results.Result, []string) {
+	t.Helper()
+	l := New(config.DefaultConfig(), false)
+	l.SetLogOutput(io.Discard)
+	l.SetCache(c)
+	assert.NoError(t, l.LoadPackages(dir))
+	l.ExtractInterfaceMethods()
+
+	var loaded []string
+	for _, pkg := range l.packages {
+		loaded = append(loaded, pkg.PkgPath)
+	}
+	sort.Strings(loaded)
+	return l.FindUnusedMethods(), loaded
+}
+
+func TestCache(t *testing.T) {
+	dir := t.TempDir()
+	writeModule(t, dir, cacheModule)
+	c, err := cache.Open(t.TempDir())
+	assert.NoError(t, err)
+
+	uncached, _ := analyzeCached(t, dir, nil)
+	first, loaded := analyzeCached(t, dir, c)
+	assert.Equal(t, uncached, first, "результат с пустым кэшем должен совпадать с результатом без кэша")
+	assert.Equal(t, []string{"example.com/cached/audit", "example.com/cached/service", "example.com/cached/store"}, loaded)
+
+	// Неизмененные пакеты берутся из кэша целиком
+	second, loaded := analyzeCached(t, dir, c)
+	assert.Empty(t, loaded)
+	assert.Equal(t, first, second)
+
+	// Измененный пакет анализируется заново, а использования в пакетах из кэша учитываются
+	writeModule(t, dir, map[string]string{
+		"service/service.go": `package service
+
+import "example.com/cached/store"
+
+func Run() string {
+	s := store.New()
+	s.Put("x")
+	return s.Get()
+}
+`,
+	})
+	third, loaded := analyzeCached(t, dir, c)
+	assert.Equal(t, []string{"example.com/cached/service"}, loaded)
+	assert.Equal(t, []string{"Logger.Log"}, unusedNames(third))
+
+	// Изменение зависимости заново анализирует пакеты, которые ее импортируют
+	writeModule(t, dir, map[string]string{
+		"store/store.go": cacheModule["store/store.go"] + "\nfunc Reset() {}\n",
+	})
+	_, loaded = analyzeCached(t, dir, c)
+	assert.Equal(t, []string{"example.com/cached/service", "example.com/cached/store"}, loaded)
+}
+
+func TestOverlay(t *testing.T) {
+	dir := t.TempDir()
+	writeModule(t, dir, cacheModule)
+	overlay := map[string][]byte{
+		filepath.Join(dir, "audit", "audit.go"): []byte(`package audit
+
+type Logger interface {
+	Log(msg string)
+}
+
+func Audit(l Logger) { l.Log("audit") }
+`),
+	}
+
+	// Содержимое из overlay заменяет файл на диске и входит в ключ кэша
+	c := cache.Memory()
+	for _, withOverlay := range []bool{false, true, false} {
+		l := New(config.DefaultConfig(), false)
+		l.SetLogOutput(io.Discard)
+		l.SetCache(c)
+		if withOverlay {
+			l.SetOverlay(overlay)
+		}
+		assert.NoError(t, l.LoadPackages(dir))
+		l.ExtractInterfaceMethods()
+		want := []string{"Logger.Log"}
+		if withOverlay {
+			want = nil
+		}
+		assert.Equal(t, want, unusedNames(l.FindUnusedMethods()))
+	}
+}
+
+// unusedNames возвращает неиспользуемые методы в виде Interface.Method
+func unusedNames(res *results.Result) []string {
+	var names []string
+	for _, f := range res.Findings {
+		if f.Verdict == results.VerdictUnused {
+			names = append(names, f.Interface+"."+f.Method)
+		}
+	}
+	return names
+}
@@ -0,0 +1,314 @@
+package linter
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/comerc/unused-interface-methods/pkg/results"
+)
+
+// packageFacts - все, что анализ узнает об одном пакете: объявленные интерфейсы, их методы
+// и встроенные интерфейсы, использования методов и наборы методов типов. Факты не содержат информации о типах
+// и не зависят от конфигурации, поэтому хранятся в кэше по содержимому пакета
+type packageFacts struct {
+	PkgPath         string
+	Files           []string // файлы пакета, в порядке загрузки
+	Interfaces      []InterfaceDecl
+	Methods         []InterfaceMethod
+	Embeds          []EmbeddedInterface
+	GenericWarnings []GenericWarning
+	Directives      []results.Suppression
+	Usages          []usage
+	Types           []concreteType
+	LoadErrors      []results.LoadError
+}
+
+// usage - выражение или поле, через которое может использоваться метод интерфейса.
+// Вместо типов хранит их ключи, поэтому сохраняется в кэше
+type usage struct {
+	Kind      results.EvidenceKind
+	Method    string // имя выбранного метода; пусто для поля
+	Named     string // имя именованного типа выражения или поля
+	Signature string // signatureKey метода интерфейса, на котором выбран метод
+	Interface string // interfaceKey типа поля
+	Position  results.Position
+}
+
+// concreteType - конкретный тип пакета и его наборы методов по ключу methodKey
+type concreteType struct {
+	Name       string // полное имя типа
+	Methods    map[string]results.Position
+	PtrMethods map[string]results.Position
+}
+
+// analyzePackage собирает факты пакета. Из тестовых файлов собираются только использования:
+// их интерфейсы не проверяются, а вызовы из них не делают метод используемым в коде
+func analyzePackage(pkg *packages.Package) *packageFacts {
+	facts := &packageFacts{PkgPath: pkg.PkgPath}
+	for _, err := range pkg.Errors {
+		facts.LoadErrors = append(facts.LoadErrors, newLoadError(pkg.PkgPath, err))
+	}
+
+	// Методы извлекаются без фильтров конфигурации: они применяются к фактам
+	extractor := New(nil, false)
+	for _, file := range pkg.Syntax {
+		filename := pkg.Fset.Position(file.Pos()).Filename
+		facts.Usages = append(facts.Usages, collectUsages(pkg, file)...)
+		if isTestFile(filename) {
+			continue
+		}
+		if !contains(facts.Files, filename) {
+			facts.Files = append(facts.Files, filename)
+		}
+		extractor.ExtractInterfaceMethodsFromFile(pkg, file, filename)
+	}
+	facts.Interfaces = extractor.interfaces
+	facts.Methods = extractor.methods
+	facts.Embeds = extractor.embeds
+	facts.GenericWarnings = extractor.genericWarnings
+	facts.Directives = extractor.directives
+	facts.Types = concreteTypes(pkg)
+	return facts
+}
+
+// collectUsages находит в файле выражения и поля, через которые может использоваться
+// метод интерфейса, в порядке обхода
+func collectUsages(pkg *packages.Package, file *ast.File) []usage {
+	if pkg.TypesInfo == nil {
+		return nil
+	}
+
+	var usages []usage
+	calls := make(map[*ast.SelectorExpr]bool) // селекторы, уже учтенные как вызовы
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.CallExpr:
+			if u, ok := reflectionUsage(pkg, x); ok {
+				usages = append(usages, u)
+			}
+			// Вызовы методов (obj.method())
+			if sel, ok := x.Fun.(*ast.SelectorExpr); ok {
+				calls[sel] = true
+				if u, ok := selectorUsage(pkg, sel); ok {
+					u.Kind = results.EvidenceCall
+					u.Position = results.NewPosition(pkg.Fset.Position(x.Pos()))
+					usages = append(usages, u)
+				}
+			}
+		case *ast.SelectorExpr:
+			// Обращения к методу без вызова (obj.method); переменная слева - обращение к полю
+			if calls[x] {
+				break
+			}
+			if ident, ok := x.X.(*ast.Ident); ok {
+				obj := pkg.TypesInfo.ObjectOf(ident)
+				if _, isVar := obj.(*types.Var); obj == nil || isVar {
+					break
+				}
+			}
+			if u, ok := selectorUsage(pkg, x); ok {
+				u.Kind = results.EvidenceMethodValue
+				u.Position = results.NewPosition(pkg.Fset.Position(x.Pos()))
+				usages = append(usages, u)
+			}
+		case *ast.Field:
+			// Поля с типом-интерфейсом из этого же пакета
+			ident, ok := x.Type.(*ast.Ident)
+			if !ok {
+				break
+			}
+			obj, ok := pkg.TypesInfo.ObjectOf(ident).(*types.TypeName)
+			if !ok {
+				break
+			}
+			if named, ok := obj.Type().(*types.Named); ok {
+				if iface, ok := named.Underlying().(*types.Interface); ok && iface.NumMethods() > 0 {
+					usages = append(usages, usage{
+						Kind:      results.EvidenceField,
+						Named:     ident.Name,
+						Interface: interfaceKey(iface),
+						Position:  results.NewPosition(pkg.Fset.Position(x.Pos())),
+					})
+				}
+			}
+		}
+		return true
+	})
+	return usages
+}
+
+// reflectionUsage распознает поиск метода по имени через reflect: MethodByName
+// у reflect.Value или reflect.Type с константным именем. Тип значения неизвестен,
+// поэтому такой вызов только выводится в explain и не делает метод используемым
+func reflectionUsage(pkg *packages.Package, call *ast.CallExpr) (usage, bool) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "MethodByName" || len(call.Args) != 1 {
+		return usage{}, false
+	}
+	named, ok := pkg.TypesInfo.TypeOf(sel.X).(*types.Named)
+	if !ok || named.Obj().Pkg() == nil || named.Obj().Pkg().Path() != "reflect" {
+		return usage{}, false
+	}
+	name := pkg.TypesInfo.Types[call.Args[0]].Value
+	if name == nil || name.Kind() != constant.String {
+		return usage{}, false
+	}
+	return usage{
+		Kind:     results.EvidenceReflection,
+		Method:   constant.StringVal(name),
+		Position: results.NewPosition(pkg.Fset.Position(call.Pos())),
+	}, true
+}
+
+// selectorUsage описывает тип выражения слева от селектора: имя именованного типа
+// и сигнатуру выбранного метода, если тип - интерфейс
+func selectorUsage(pkg *packages.Package, sel *ast.SelectorExpr) (usage, bool) {
+	exprType := pkg.TypesInfo.TypeOf(sel.X)
+	if exprType == nil {
+		return usage{}, false
+	}
+
+	u := usage{Method: sel.Sel.Name}
+	if named, ok := exprType.(*types.Named); ok {
+		u.Named = named.Obj().Name()
+		exprType = named.Underlying()
+	}
+	if iface, ok := exprType.(*types.Interface); ok {
+		if method := lookupMethod(iface, sel.Sel.Name); method != nil {
+			u.Signature = signatureKey(method.Type().(*types.Signature))
+		}
+	}
+	return u, u.Named != "" || u.Signature != ""
+}
+
+// uses сообщает, использует ли выражение или поле метод интерфейса: по имени интерфейса,
+// совпадающему интерфейсу поля, методу с той же сигнатурой или, возможно, имени метода в reflect
+func (u usage) uses(method InterfaceMethod) bool {
+	switch u.Kind {
+	case results.EvidenceField:
+		return u.Named == method.InterfaceName && u.Interface != "" && u.Interface == method.InterfaceKey
+	case results.EvidenceReflection:
+		return u.Method == method.MethodName
+	}
+	if u.Method != method.MethodName {
+		return false
+	}
+	return u.Named == method.InterfaceName || u.Signature != "" && u.Signature == method.SignatureKey
+}
+
+// concreteTypes собирает наборы методов конкретных типов пакета, кроме объявленных в тестах.
+// Дженерик-типы без инстанцирования не проверить, поэтому они пропускаются
+func concreteTypes(pkg *packages.Package) []concreteType {
+	if pkg.Types == nil {
+		return nil
+	}
+
+	var concrete []concreteType
+	scope := pkg.Types.Scope()
+	for _, name := range scope.Names() {
+		typeName, ok := scope.Lookup(name).(*types.TypeName)
+		if !ok || typeName.IsAlias() || types.IsInterface(typeName.Type()) {
+			continue
+		}
+		if isTestFile(pkg.Fset.Position(typeName.Pos()).Filename) {
+			continue
+		}
+		if named, ok := typeName.Type().(*types.Named); ok && named.TypeParams().Len() > 0 {
+			continue
+		}
+
+		typ := typeName.Type()
+		concrete = append(concrete, concreteType{
+			Name:       types.TypeString(typ, nil),
+			Methods:    methodSet(pkg, typ),
+			PtrMethods: methodSet(pkg, types.NewPointer(typ)),
+		})
+	}
+	return concrete
+}
+
+// methodSet возвращает позиции методов типа по ключу methodKey
+func methodSet(pkg *packages.Package, typ types.Type) map[string]results.Position {
+	mset := types.NewMethodSet(typ)
+	methods := make(map[string]results.Position, mset.Len())
+	for i := 0; i < mset.Len(); i++ {
+		fn, ok := mset.At(i).Obj().(*types.Func)
+		if !ok {
+			continue
+		}
+		var position results.Position
+		if fn.Pos().IsValid() {
+			position = results.NewPosition(pkg.Fset.Position(fn.Pos()))
+		}
+		methods[methodKey(fn)] = position
+	}
+	return methods
+}
+
+// implements сообщает, есть ли в наборе методов все методы интерфейса
+func implements(methods map[string]results.Position, keys []string) bool {
+	for _, key := range keys {
+		if _, ok := methods[key]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// signatureKey записывает сигнатуру без имен параметров и с полными путями пакетов:
+// одинаковые ключи у сигнатур, для которых types.Identical возвращает true
+func signatureKey(sig *types.Signature) string {
+	qualifier := func(p *types.Package) string { return p.Path() }
+	tuple := func(t *types.Tuple, variadic bool) string {
+		var parts []string
+		for i := 0; i < t.Len(); i++ {
+			typ := t.At(i).Type()
+			if variadic && i == t.Len()-1 {
+				if slice, ok := typ.(*types.Slice); ok {
+					parts = append(parts, "..."+types.TypeString(slice.Elem(), qualifier))
+					continue
+				}
+			}
+			parts = append(parts, types.TypeString(typ, qualifier))
+		}
+		return "(" + strings.Join(parts, ", ") + ")"
+	}
+	return tuple(sig.Params(), sig.Variadic()) + " " + tuple(sig.Results(), false)
+}
+
+// methodKey идентифицирует метод в наборе методов: неэкспортируемые имена
+// уточняются пакетом, как в types.Id
+func methodKey(fn *types.Func) string {
+	name := fn.Name()
+	if !fn.Exported() && fn.Pkg() != nil {
+		name = fn.Pkg().Path() + "." + name
+	}
+	return name + signatureKey(fn.Type().(*types.Signature))
+}
+
+// interfaceKey перечисляет ключи методов интерфейса, по одному на строку; методы
+// интерфейса упорядочены по types.Id, поэтому одинаковые интерфейсы дают одинаковый ключ
+func interfaceKey(iface *types.Interface) string {
+	if !iface.IsMethodSet() {
+		return "" // ограничения с наборами типов не бывают типами полей и не реализуются
+	}
+	keys := make([]string, iface.NumMethods())
+	for i := range keys {
+		keys[i] = methodKey(iface.Method(i))
+	}
+	return strings.Join(keys, "\n")
+}
+
+// contains сообщает, есть ли строка в списке
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
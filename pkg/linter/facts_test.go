@@ -0,0 +1,255 @@
+package linter
+
+import (
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/comerc/unused-interface-methods/pkg/cache"
+	"github.com/comerc/unused-interface-methods/pkg/config"
+	"github.com/comerc/unused-interface-methods/pkg/results"
+)
+
+// evidenceModule - модуль, где Plugin.Start вызывается через reflect, а Plugin.Name - дважды
+var evidenceModule = map[string]string{
+	"go.mod": "module example.com/evidence\n\ngo 1.21\n",
+	"plugin/plugin.go": `package plugin
+
+type Plugin interface {
+	Name() string
+	Start()
+	Stop()
+}
+`,
+	"app/app.go": `package app
+
+import (
+	"reflect"
+
+	"example.com/evidence/plugin"
+)
+
+func Describe(p plugin.Plugin) string { return p.Name() }
+
+func Title(p plugin.Plugin) string { return p.Name() }
+
+func Run(p any) {
+	reflect.ValueOf(p).MethodByName("Start").Call(nil)
+}
+
+func Find(t reflect.Type, name string) {
+	t.MethodByName(name) // имя не константа - метод не известен
+}
+`,
+}
+
+// analyzeEvidence анализирует модуль и возвращает находки по методам
+func analyzeEvidence(t *testing.T, all bool) map[string]results.Finding {
+	t.Helper()
+	dir := t.TempDir()
+	writeModule(t, dir, evidenceModule)
+	l := New(config.DefaultConfig(), false)
+	l.SetLogOutput(io.Discard)
+	l.SetAllEvidence(all)
+	assert.NoError(t, l.LoadPackages(dir))
+	l.ExtractInterfaceMethods()
+
+	findings := make(map[string]results.Finding)
+	for _, f := range l.FindUnusedMethods().Findings {
+		findings[f.Method] = f
+	}
+	return findings
+}
+
+func TestReflectionUsage(t *testing.T) {
+	start := analyzeEvidence(t, false)["Start"]
+	assert.Equal(t, results.VerdictUnused, start.Verdict, "вызов через reflect не делает метод используемым")
+	assert.Empty(t, start.Evidence)
+
+	findings := analyzeEvidence(t, true)
+	start = findings["Start"]
+	assert.Equal(t, results.VerdictUnused, start.Verdict)
+	if assert.Len(t, start.Evidence, 1) {
+		assert.Equal(t, results.EvidenceReflection, start.Evidence[0].Kind)
+		assert.Equal(t, 14, start.Evidence[0].Position.Line)
+	}
+	assert.Empty(t, findings["Stop"].Evidence, "MethodByName с неконстантным именем не выводится")
+}
+
+func TestAllEvidence(t *testing.T) {
+	assert.Len(t, analyzeEvidence(t, false)["Name"].Evidence, 1, "по умолчанию достаточно первого использования")
+
+	lines := func(evidence []results.Evidence) []int {
+		var lines []int
+		for _, e := range evidence {
+			assert.Equal(t, results.EvidenceCall, e.Kind)
+			lines = append(lines, e.Position.Line)
+		}
+		return lines
+	}
+	assert.Equal(t, []int{9, 11}, lines(analyzeEvidence(t, true)["Name"].Evidence))
+}
+
+func TestInterfaces(t *testing.T) {
+	dir := t.TempDir()
+	writeModule(t, dir, map[string]string{
+		"go.mod": "module example.com/decls\n\ngo 1.21\n",
+		"store/store.go": `package store
+
+import "io"
+
+type Store interface {
+	io.Closer
+	Get(key string) string
+}
+
+type Number interface{ ~int | ~float64 }
+
+type Cache[K comparable] interface {
+	Get(key K) string
+}
+
+type mem struct{}
+
+func (mem) Get(key string) string { return "" }
+func (*mem) Close() error        { return nil }
+`,
+	})
+	l := New(config.DefaultConfig(), false)
+	l.SetLogOutput(io.Discard)
+	assert.NoError(t, l.LoadPackages(dir))
+	l.ExtractInterfaceMethods()
+
+	decls := make(map[string]InterfaceDecl)
+	for _, decl := range l.Interfaces() {
+		assert.Equal(t, "example.com/decls/store", decl.PkgPath)
+		decls[decl.Name] = decl
+	}
+	assert.Len(t, decls, 3)
+
+	store := decls["Store"]
+	assert.Equal(t, 5, store.Position.Line)
+	assert.Equal(t, []string{"io.Closer"}, store.Embeds)
+	// Close есть только у указателя, поэтому интерфейс реализует *mem
+	assert.Equal(t, []string{"*example.com/decls/store.mem"}, store.Implementers)
+
+	assert.Equal(t, []string{"~int | ~float64"}, decls["Number"].Embeds)
+	assert.Empty(t, decls["Number"].Implementers, "ограничения не реализуются")
+	assert.Equal(t, "[K comparable]", decls["Cache"].TypeParams)
+	assert.Empty(t, decls["Cache"].Implementers)
+}
+
+// kindsModule - модуль с находками всех видов: Store.Put вызывается только в тестах,
+// Store.Reset - только во внешнем тестовом пакете, Store.Flush экспортирован,
+// io.Closer и fmt.Stringer встроены и не используются
+var kindsModule = map[string]string{
+	"go.mod": "module example.com/kinds\n\ngo 1.21\n",
+	"store/store.go": `package store
+
+import (
+	"fmt"
+	"io"
+)
+
+type Store interface {
+	io.Closer
+	Get() string
+	Put(v string)
+	Reset()
+	Flush()
+}
+
+type cache interface {
+	fmt.Stringer
+	Drop()
+}
+
+var (
+	Default Store
+	current cache
+)
+
+func Get() string { return Default.Get() }
+`,
+	"store/store_test.go": `package store
+
+import "testing"
+
+func TestPut(t *testing.T) {
+	if Default != nil {
+		Default.Put("x")
+	}
+}
+`,
+	"store/export_test.go": `package store_test
+
+import (
+	"testing"
+
+	"example.com/kinds/store"
+)
+
+var _ = store.Get
+
+func TestReset(t *testing.T) {
+	if store.Default != nil {
+		store.Default.Reset()
+	}
+}
+`,
+	"internal/hidden/hidden.go": `package hidden
+
+type Hidden interface {
+	Ping()
+}
+`,
+}
+
+func TestFindingKinds(t *testing.T) {
+	dir := t.TempDir()
+	writeModule(t, dir, kindsModule)
+	c, err := cache.Open(t.TempDir())
+	assert.NoError(t, err)
+
+	for _, cached := range []*cache.Cache{nil, c, c} {
+		l := New(config.DefaultConfig(), false)
+		l.SetLogOutput(io.Discard)
+		l.SetCache(cached)
+		assert.NoError(t, l.LoadPackages(dir))
+		l.ExtractInterfaceMethods()
+
+		findings := make(map[string]results.Finding)
+		for _, f := range l.FindUnusedMethods().Findings {
+			findings[f.Interface+"."+f.Method] = f
+		}
+		assert.Len(t, findings, 8)
+
+		assert.Equal(t, results.VerdictUsed, findings["Store.Get"].Verdict)
+		assert.Equal(t, results.RuleUnusedMethod, findings["Store.Get"].RuleID())
+
+		put := findings["Store.Put"]
+		assert.Equal(t, results.VerdictUnused, put.Verdict, "вызов из теста не делает метод используемым")
+		assert.Equal(t, results.RuleTestOnly, put.RuleID())
+		assert.Equal(t, results.SeverityWarning, put.Level())
+		assert.Empty(t, put.Fixes, "удаление метода сломает тесты")
+		if assert.Len(t, put.Evidence, 1) {
+			assert.Equal(t, filepath.Join(dir, "store", "store_test.go"), put.Evidence[0].Position.File)
+		}
+
+		assert.Equal(t, results.RuleTestOnly, findings["Store.Reset"].RuleID(), "внешний тестовый пакет тоже учитывается")
+		assert.Equal(t, results.RuleExportedUnused, findings["Store.Flush"].RuleID())
+		assert.Equal(t, results.RuleUnusedMethod, findings["cache.Drop"].RuleID(), "интерфейс не экспортирован")
+		assert.Equal(t, results.RuleUnusedMethod, findings["Hidden.Ping"].RuleID(), "пакет internal не импортируют другие модули")
+
+		closer := findings["Store.io.Closer"]
+		assert.Equal(t, results.VerdictUnused, closer.Verdict)
+		assert.Equal(t, results.RuleUnusedEmbed, closer.RuleID())
+		assert.Equal(t, 9, closer.Range.Start.Line)
+		if assert.Len(t, closer.Fixes, 1) {
+			assert.Equal(t, "Remove embedded interface io.Closer from the interface", closer.Fixes[0].Description)
+		}
+		assert.Equal(t, results.RuleUnusedEmbed, findings["cache.fmt.Stringer"].RuleID())
+	}
+}
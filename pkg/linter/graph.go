@@ -0,0 +1,182 @@
+package linter
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// errGraphChanged - изменения меняют состав пакетов, граф нужно загрузить целиком
+var errGraphChanged = errors.New("package graph changed")
+
+// Graph хранит граф пакетов одной конфигурации сборки между запусками (watch).
+// После изменения файлов go list загружает заново только пакеты этих файлов,
+// а ключи кэша пересчитываются для них и пакетов, которые их импортируют.
+// Граф используется без overlay
+type Graph struct {
+	roots   []*packages.Package          // пакеты анализа до фильтра конфигурации; nil - граф не загружен
+	byID    map[string]*packages.Package // все пакеты графа вместе с зависимостями
+	keys    map[string]string            // ключи кэша пакетов по ID
+	changed []string                     // файлы, измененные после загрузки графа
+}
+
+// NewGraph возвращает пустой граф: первый запуск загружает его целиком
+func NewGraph() *Graph {
+	return &Graph{}
+}
+
+// Invalidate запоминает измененные файлы; граф обновляется при следующей загрузке
+func (g *Graph) Invalidate(files []string) {
+	g.changed = append(g.changed, files...)
+}
+
+// SetGraph включает повторное использование графа пакетов между запусками;
+// работает вместе с кэшем фактов (SetCache)
+func (l *UnusedMethodLinter) SetGraph(g *Graph) {
+	l.graph = g
+}
+
+// load возвращает пакеты анализа и ключи кэша пакетов графа. Граф загружается
+// целиком в первый раз и когда изменения меняют состав пакетов или модулей
+func (g *Graph) load(l *UnusedMethodLinter, dir string) ([]*packages.Package, map[string]string, error) {
+	if g.roots != nil {
+		patterns, owners, ok := g.affected()
+		if !ok {
+			g.roots = nil
+		} else if len(patterns) > 0 {
+			switch err := g.update(l, dir, patterns, owners); {
+			case errors.Is(err, errGraphChanged):
+				g.roots = nil
+			case err != nil:
+				g.roots = nil // после ошибки граф может быть неполным
+				return nil, nil, err
+			}
+		}
+	}
+
+	if g.roots == nil {
+		pkgs, err := packages.Load(l.packagesConfig(dir, keyMode), l.patterns()...)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load packages: %w", err)
+		}
+		g.roots = withTests(pkgs)
+		g.byID = reachable(g.roots)
+		g.keys = make(map[string]string)
+	}
+	g.changed = nil
+	return g.roots, g.keys, nil
+}
+
+// affected находит пакеты графа с измененными файлами и пути для их загрузки.
+// false - появился Go-файл, которого нет в графе, файл удален, изменился файл,
+// исключенный ограничениями сборки, go.mod или go.work: меняется состав пакетов.
+// Остальные файлы вне графа (конфигурация, baseline) на него не влияют
+func (g *Graph) affected() (patterns []string, owners map[string]bool, ok bool) {
+	owners = make(map[string]bool)
+	for _, file := range g.changed {
+		if name := filepath.Base(file); name == "go.mod" || name == "go.work" {
+			return nil, nil, false
+		}
+		found := false
+		for id, pkg := range g.byID {
+			if slices.Contains(pkg.IgnoredFiles, file) {
+				return nil, nil, false
+			}
+			if slices.Contains(pkg.GoFiles, file) || slices.Contains(pkg.OtherFiles, file) {
+				owners[id] = true
+				patterns = appendUnique(patterns, importPath(pkg))
+				found = true
+			}
+		}
+		if !strings.HasSuffix(file, ".go") {
+			continue
+		}
+		if !found {
+			return nil, nil, false
+		}
+		if _, err := os.Stat(file); err != nil {
+			return nil, nil, false
+		}
+	}
+	return patterns, owners, true
+}
+
+// update загружает заново пакеты patterns и заменяет ими узлы графа. Ключи пакетов
+// owners и всех пакетов, которые их импортируют, сбрасываются
+func (g *Graph) update(l *UnusedMethodLinter, dir string, patterns []string, owners map[string]bool) error {
+	pkgs, err := packages.Load(l.packagesConfig(dir, keyMode), patterns...)
+	if err != nil {
+		return fmt.Errorf("failed to load packages: %w", err)
+	}
+
+	// Тестовые варианты пакета меняются вместе с составом его файлов
+	var before, after []string
+	for _, pkg := range g.roots {
+		if slices.Contains(patterns, importPath(pkg)) {
+			before = append(before, pkg.ID)
+		}
+	}
+	for _, pkg := range withTests(pkgs) {
+		after = append(after, pkg.ID)
+	}
+	slices.Sort(before)
+	slices.Sort(after)
+	if !slices.Equal(before, after) {
+		return errGraphChanged
+	}
+	if l.verbose {
+		l.logf("Packages: %s reloaded\n", strings.Join(patterns, ", "))
+	}
+
+	// Новые узлы заменяют старые и в импортах пакетов, которые не загружались
+	for id, pkg := range reachable(pkgs) {
+		g.byID[id] = pkg
+	}
+	for _, pkg := range g.byID {
+		for path, imp := range pkg.Imports {
+			pkg.Imports[path] = g.byID[imp.ID]
+		}
+	}
+	for i, pkg := range g.roots {
+		g.roots[i] = g.byID[pkg.ID]
+	}
+
+	dirty := owners
+	for grown := true; grown; {
+		grown = false
+		for id, pkg := range g.byID {
+			if dirty[id] {
+				continue
+			}
+			for _, imp := range pkg.Imports {
+				if dirty[imp.ID] {
+					dirty[id], grown = true, true
+					break
+				}
+			}
+		}
+	}
+
+	// Пакеты, которые больше никто не импортирует, уходят из графа
+	g.byID = reachable(g.roots)
+	for id := range g.keys {
+		if dirty[id] || g.byID[id] == nil {
+			delete(g.keys, id)
+		}
+	}
+	return nil
+}
+
+// reachable возвращает пакеты и все их зависимости по ID
+func reachable(pkgs []*packages.Package) map[string]*packages.Package {
+	byID := make(map[string]*packages.Package)
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		byID[pkg.ID] = pkg
+	})
+	return byID
+}
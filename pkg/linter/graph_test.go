@@ -0,0 +1,95 @@
+package linter
+
+import (
+	"io"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/comerc/unused-interface-methods/pkg/cache"
+	"github.com/comerc/unused-interface-methods/pkg/config"
+	"github.com/comerc/unused-interface-methods/pkg/results"
+)
+
+// analyzeGraph анализирует модуль с кэшем и графом и возвращает результат и пакеты,
+// которые пришлось загрузить с типами
+func analyzeGraph(t *testing.T, dir string, c *cache.Cache, g *Graph) (*results.Result, []string) {
+	t.Helper()
+	l := New(config.DefaultConfig(), false)
+	l.SetLogOutput(io.Discard)
+	l.SetCache(c)
+	l.SetGraph(g)
+	assert.NoError(t, l.LoadPackages(dir))
+	l.ExtractInterfaceMethods()
+
+	var loaded []string
+	for _, pkg := range l.packages {
+		loaded = append(loaded, pkg.PkgPath)
+	}
+	sort.Strings(loaded)
+	return l.FindUnusedMethods(), loaded
+}
+
+func TestGraph(t *testing.T) {
+	dir, err := filepath.EvalSymlinks(t.TempDir()) // go list возвращает пути без символических ссылок
+	assert.NoError(t, err)
+	writeModule(t, dir, cacheModule)
+	c := cache.Memory()
+	g := NewGraph()
+
+	_, loaded := analyzeGraph(t, dir, c, g)
+	assert.Len(t, loaded, 3)
+	audit := g.byID["example.com/cached/audit"]
+	service := g.byID["example.com/cached/service"]
+
+	// Конфигурация не входит в граф: пакеты не загружаются заново
+	g.Invalidate([]string{filepath.Join(dir, ".unused-interface-methods.yml")})
+	_, loaded = analyzeGraph(t, dir, c, g)
+	assert.Empty(t, loaded)
+	assert.Same(t, service, g.byID["example.com/cached/service"])
+
+	// go list загружает заново только пакет измененного файла
+	writeModule(t, dir, map[string]string{
+		"service/service.go": `package service
+
+import "example.com/cached/store"
+
+func Run() string {
+	s := store.New()
+	s.Put("x")
+	return s.Get()
+}
+`,
+	})
+	g.Invalidate([]string{filepath.Join(dir, "service", "service.go")})
+	res, loaded := analyzeGraph(t, dir, c, g)
+	assert.Equal(t, []string{"example.com/cached/service"}, loaded)
+	assert.Equal(t, []string{"Logger.Log"}, unusedNames(res))
+	assert.Same(t, audit, g.byID["example.com/cached/audit"])
+	assert.NotSame(t, service, g.byID["example.com/cached/service"])
+
+	// Изменение зависимости сбрасывает ключи пакетов, которые ее импортируют
+	writeModule(t, dir, map[string]string{
+		"store/store.go": cacheModule["store/store.go"] + "\nfunc Reset() {}\n",
+	})
+	g.Invalidate([]string{filepath.Join(dir, "store", "store.go")})
+	_, loaded = analyzeGraph(t, dir, c, g)
+	assert.Equal(t, []string{"example.com/cached/service", "example.com/cached/store"}, loaded)
+	assert.Same(t, audit, g.byID["example.com/cached/audit"])
+	assert.Same(t, g.byID["example.com/cached/store"], g.byID["example.com/cached/service"].Imports["example.com/cached/store"])
+
+	// Новый файл меняет состав пакета: граф загружается целиком
+	writeModule(t, dir, map[string]string{
+		"audit/use.go": "package audit\n\nfunc Audit(l Logger) { l.Log(\"audit\") }\n",
+	})
+	g.Invalidate([]string{filepath.Join(dir, "audit", "use.go")})
+	res, loaded = analyzeGraph(t, dir, c, g)
+	assert.Equal(t, []string{"example.com/cached/audit"}, loaded)
+	assert.Empty(t, unusedNames(res))
+	assert.NotSame(t, audit, g.byID["example.com/cached/audit"])
+
+	uncached, _ := analyzeCached(t, dir, nil)
+	assert.Equal(t, uncached, res, "результат с графом должен совпадать с результатом без кэша")
+}
@@ -1,29 +1,59 @@
 package linter
 
 import (
+	"bytes"
 	"fmt"
 	"go/ast"
 	"go/token"
 	"go/types"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 
 	"golang.org/x/tools/go/packages"
+
+	"github.com/comerc/unused-interface-methods/pkg/cache"
+	"github.com/comerc/unused-interface-methods/pkg/results"
+	"github.com/comerc/unused-interface-methods/pkg/suppress"
 )
 
 // InterfaceMethod представляет метод интерфейса
 type InterfaceMethod struct {
+	PkgPath       string
 	InterfaceName string
 	MethodName    string
 	Signature     string
 	File          string
 	Line          int
-	Interface     *types.Interface // Добавляем информацию о типе интерфейса
+	Range         results.Range        // положение имени метода в объявлении
+	Fix           *results.Fix         // исправление, удаляющее метод из интерфейса
+	Suppression   *results.Suppression // директива подавления на методе, интерфейсе или файле
+	Interface     *types.Interface     `json:"-"` // Добавляем информацию о типе интерфейса
+	SignatureKey  string               // сигнатура для сравнения без информации о типах (signatureKey)
+	MethodKey     string               // ключ метода в наборах методов типов (methodKey)
+	InterfaceKey  string               // ключ интерфейса для сравнения без информации о типах (interfaceKey)
+	Exported      bool                 // метод экспортированного интерфейса в пакете, который могут импортировать другие модули
+}
+
+// EmbeddedInterface представляет интерфейс, встроенный в другой интерфейс, и методы,
+// которые он добавляет встраивающему интерфейсу
+type EmbeddedInterface struct {
+	PkgPath       string
+	InterfaceName string // встраивающий интерфейс
+	Embed         string // встроенный интерфейс, как он записан в объявлении, например io.Reader
+	File          string
+	Range         results.Range        // положение встроенного интерфейса в объявлении
+	Fix           *results.Fix         // исправление, удаляющее встроенный интерфейс
+	Suppression   *results.Suppression // директива подавления на встроенном интерфейсе, интерфейсе или файле
+	Methods       []InterfaceMethod    // добавленные методы как методы встраивающего интерфейса
 }
 
 // GenericWarning представляет предупреждение о дженерике
 type GenericWarning struct {
+	PkgPath       string
 	InterfaceName string
 	File          string
 	Line          int
@@ -31,6 +61,18 @@ type GenericWarning struct {
 	TypeParams    string
 }
 
+// InterfaceDecl представляет объявление интерфейса для индекса: встроенные интерфейсы
+// и конкретные типы, которые его реализуют
+type InterfaceDecl struct {
+	PkgPath      string
+	Name         string
+	Position     results.Position
+	TypeParams   string   // параметры типа дженерик-интерфейса
+	Embeds       []string // встроенные интерфейсы и элементы ограничений, например io.Reader
+	Key          string   // interfaceKey; пусто у дженериков, ограничений и пустых интерфейсов
+	Implementers []string `json:"-"` // заполняется по фактам всех пакетов
+}
+
 // ConfigInterface определяет интерфейс для конфигурации
 type ConfigInterface interface {
 	ShouldIgnore(filePath string) bool
@@ -41,8 +83,27 @@ type UnusedMethodLinter struct {
 	packages        []*packages.Package
 	methods         []InterfaceMethod
 	genericWarnings []GenericWarning
+	interfaces      []InterfaceDecl
+	embeds          []EmbeddedInterface
 	verbose         bool
 	config          ConfigInterface
+	logOutput       io.Writer              // куда выводить подробный лог, по умолчанию os.Stdout
+	directives      []results.Suppression  // директивы подавления из проанализированных файлов
+	loadErrors      []results.LoadError    // ошибки загрузки и проверки типов анализируемых пакетов
+	goos, goarch    string                 // платформа сборки; пустые значения - текущая
+	tags            []string               // теги сборки
+	goWork          string                 // значение GOWORK для go list; пустое значение - по умолчанию
+	modules         []string               // корни модулей, загружаемых вместе; пусто - модуль директории
+	scope           func(file string) bool // выбранные файлы, интерфейсы из которых проверяются; nil - все
+	overlay         map[string][]byte      // содержимое файлов, которое заменяет файлы на диске
+	allEvidence     bool                   // собирать все использования метода, а не первое
+	cache           *cache.Cache           // кэш фактов пакетов; nil - кэш отключен
+	graph           *Graph                 // граф пакетов из прошлых запусков; nil - загружается заново
+	cached          map[string]*packageFacts
+	keys            map[string]string // ключи кэша пакетов, факты которых нужно сохранить
+	order           []string          // пути пакетов в порядке загрузки; nil - порядок l.packages
+	facts           []*packageFacts   // факты всех пакетов в порядке загрузки
+	usages          []usage           // использования из непропущенных файлов
 }
 
 func New(config ConfigInterface, verbose bool) *UnusedMethodLinter {
@@ -54,81 +115,333 @@ func New(config ConfigInterface, verbose bool) *UnusedMethodLinter {
 	}
 }
 
-// LoadPackages загружает пакеты с информацией о типах
+// SetLogOutput задает, куда выводить подробный лог (-v)
+func (l *UnusedMethodLinter) SetLogOutput(w io.Writer) {
+	l.logOutput = w
+}
+
+// logf выводит строку подробного лога
+func (l *UnusedMethodLinter) logf(format string, args ...interface{}) {
+	w := l.logOutput
+	if w == nil {
+		w = os.Stdout
+	}
+	fmt.Fprintf(w, format, args...)
+}
+
+// SetBuild задает платформу и теги, с которыми LoadPackages выбирает файлы пакетов.
+// Пустые goos и goarch - текущая платформа
+func (l *UnusedMethodLinter) SetBuild(goos, goarch string, tags []string) {
+	l.goos, l.goarch, l.tags = goos, goarch, tags
+}
+
+// SetModules задает модули, которые LoadPackages загружает вместе, и файл go.work для них
+func (l *UnusedMethodLinter) SetModules(goWork string, modules []string) {
+	l.goWork, l.modules = goWork, modules
+}
+
+// loadMode - данные пакетов, нужные для анализа
+const loadMode = packages.NeedName | packages.NeedFiles | packages.NeedCompiledGoFiles |
+	packages.NeedImports | packages.NeedTypes | packages.NeedTypesInfo |
+	packages.NeedSyntax | packages.NeedModule | packages.NeedForTest
+
+// LoadPackages загружает пакеты с информацией о типах. С кэшем загружаются только
+// измененные пакеты и пакеты, которые от них зависят, остальные берутся из кэша
 func (l *UnusedMethodLinter) LoadPackages(dir string) error {
-	// Настройка загрузки пакетов
-	cfg := &packages.Config{
-		Mode: packages.NeedName | packages.NeedFiles | packages.NeedCompiledGoFiles |
-			packages.NeedImports | packages.NeedTypes | packages.NeedTypesInfo |
-			packages.NeedSyntax | packages.NeedModule,
-		Dir: dir,
+	if l.cache != nil {
+		return l.loadCached(dir)
 	}
 
-	// Загружаем пакеты рекурсивно
-	pattern := "./..."
-	pkgs, err := packages.Load(cfg, pattern)
+	pkgs, err := packages.Load(l.packagesConfig(dir, loadMode), l.patterns()...)
 	if err != nil {
 		return fmt.Errorf("failed to load packages: %w", err)
 	}
+	l.packages = l.filterPackages(withTests(pkgs))
+	for _, pkg := range l.packages {
+		l.addLoadErrors(pkg.PkgPath, pkg.Errors)
+	}
+	return nil
+}
 
-	// Фильтруем пакеты, исключая ненужные директории
+// packagesConfig настраивает go/packages на платформу, теги и модули анализа
+func (l *UnusedMethodLinter) packagesConfig(dir string, mode packages.LoadMode) *packages.Config {
+	cfg := &packages.Config{Mode: mode, Dir: dir, Tests: true}
+
+	// Другая платформа не требует ее инструментов: go list только выбирает файлы
+	var env []string
+	if l.goos != "" {
+		env = append(env, "GOOS="+l.goos)
+	}
+	if l.goarch != "" {
+		env = append(env, "GOARCH="+l.goarch)
+	}
+	if l.goWork != "" {
+		env = append(env, "GOWORK="+l.goWork)
+	}
+	if len(l.modules) > 0 {
+		// В режиме go.work go list отказывается работать с -mod=mod
+		env = append(env, "GOFLAGS="+workspaceFlags(os.Getenv("GOFLAGS")))
+	}
+	if len(env) > 0 {
+		cfg.Env = append(os.Environ(), env...)
+	}
+	if len(l.tags) > 0 {
+		cfg.BuildFlags = []string{"-tags=" + strings.Join(l.tags, ",")}
+	}
+	cfg.Overlay = l.overlay
+	return cfg
+}
+
+// patterns возвращает паттерны загрузки: пакеты рекурсивно; модули рабочего
+// пространства - одним вызовом, чтобы типы из разных модулей совпадали
+// и вызовы между модулями находились
+func (l *UnusedMethodLinter) patterns() []string {
+	if len(l.modules) == 0 {
+		return []string{"./..."}
+	}
+	var patterns []string
+	for _, module := range l.modules {
+		patterns = append(patterns, filepath.Join(module, "...")) // абсолютный путь не зависит от Dir
+	}
+	return patterns
+}
+
+// withTests заменяет пакеты их вариантами с тестовыми файлами и убирает сгенерированные
+// пакеты main тестов: вызовы из тестов нужны, чтобы отличить методы, которые используются
+// только в тестах. Внешние тестовые пакеты (_test) остаются
+func withTests(pkgs []*packages.Package) []*packages.Package {
+	tested := make(map[string]bool)
+	for _, pkg := range pkgs {
+		if pkg.ForTest == pkg.PkgPath {
+			tested[pkg.PkgPath] = true
+		}
+	}
+	var selected []*packages.Package
+	for _, pkg := range pkgs {
+		if pkg.ForTest == "" && (tested[pkg.PkgPath] || strings.HasSuffix(pkg.PkgPath, ".test")) {
+			continue
+		}
+		selected = append(selected, pkg)
+	}
+	return selected
+}
+
+// importPath возвращает путь, по которому загружается пакет или его тестовый вариант
+func importPath(pkg *packages.Package) string {
+	if pkg.ForTest != "" {
+		return pkg.ForTest
+	}
+	return pkg.PkgPath
+}
+
+// isTestFile сообщает, является ли файл тестовым
+func isTestFile(filename string) bool {
+	return strings.HasSuffix(filename, "_test.go")
+}
+
+// filterPackages исключает пакеты из ненужных директорий
+func (l *UnusedMethodLinter) filterPackages(pkgs []*packages.Package) []*packages.Package {
 	var filteredPkgs []*packages.Package
 	for _, pkg := range pkgs {
-		shouldIgnore := false
-		for _, dir := range pkg.GoFiles {
-			if l.config.ShouldIgnore(filepath.Dir(dir)) {
+		// Пакет исключается вместе с директорией или если исключены все его файлы:
+		// паттерны include и отрицания "!pattern" могут относиться к отдельным файлам.
+		// Тестовые файлы не решают судьбу пакета: вызовы из тестов учитываются, даже если
+		// тесты исключены, поэтому внешний тестовый пакет (_test) остается
+		shouldIgnore := slices.ContainsFunc(pkg.GoFiles, func(file string) bool { return !isTestFile(file) })
+		for _, file := range pkg.GoFiles {
+			if l.config.ShouldIgnore(filepath.Dir(file)) {
 				shouldIgnore = true
 				break
 			}
+			if !isTestFile(file) && !l.config.ShouldIgnore(file) {
+				shouldIgnore = false
+				break
+			}
 		}
 
 		if !shouldIgnore {
-			// Проверяем ошибки в пакетах
-			if len(pkg.Errors) > 0 {
-				for _, err := range pkg.Errors {
-					if l.verbose {
-						fmt.Printf("Warning: %v\n", err)
-					}
-
-				}
-			}
 			filteredPkgs = append(filteredPkgs, pkg)
 		} else if l.verbose {
-			fmt.Printf("Excluding package: %s\n", pkg.PkgPath)
+			l.logf("Excluding package: %s\n", pkg.PkgPath)
 		}
 	}
+	return filteredPkgs
+}
 
-	l.packages = filteredPkgs
-	return nil
+// addLoadErrors запоминает ошибки пакета: они не прерывают анализ, серьезность задает конфигурация
+func (l *UnusedMethodLinter) addLoadErrors(pkgPath string, errs []packages.Error) {
+	for _, err := range errs {
+		if l.verbose {
+			l.logf("Warning: %v\n", err)
+		}
+		l.loadErrors = append(l.loadErrors, newLoadError(pkgPath, err))
+	}
 }
 
-// ExtractInterfaceMethods извлекает все методы интерфейсов
-func (l *UnusedMethodLinter) ExtractInterfaceMethods() {
+// workspaceFlags убирает из GOFLAGS флаг -mod=mod, недопустимый для рабочего пространства
+func workspaceFlags(goflags string) string {
+	var flags []string
+	for _, flag := range strings.Fields(goflags) {
+		if flag != "-mod=mod" {
+			flags = append(flags, flag)
+		}
+	}
+	return strings.Join(flags, " ")
+}
+
+// newLoadError преобразует ошибку go/packages; позиция имеет вид file, file:line или file:line:col
+func newLoadError(pkgPath string, err packages.Error) results.LoadError {
+	loadErr := results.LoadError{PkgPath: pkgPath, Message: err.Msg}
+	if err.Pos == "" || err.Pos == "-" {
+		return loadErr
+	}
+
+	// Номера разбираются справа: в пути файла тоже может быть ":"
+	file, numbers := err.Pos, []int{}
+	for len(numbers) < 2 {
+		i := strings.LastIndex(file, ":")
+		if i < 0 {
+			break
+		}
+		n, convErr := strconv.Atoi(file[i+1:])
+		if convErr != nil {
+			break
+		}
+		numbers = append([]int{n}, numbers...)
+		file = file[:i]
+	}
+	loadErr.Position.File = file
+	if len(numbers) > 0 {
+		loadErr.Position.Line = numbers[0]
+	}
+	if len(numbers) > 1 {
+		loadErr.Position.Column = numbers[1]
+	}
+	return loadErr
+}
+
+// Directives возвращает директивы подавления из проанализированных файлов
+func (l *UnusedMethodLinter) Directives() []results.Suppression {
+	return l.directives
+}
+
+// Interfaces возвращает объявления интерфейсов из проверяемых файлов вместе
+// с конкретными типами, которые их реализуют
+func (l *UnusedMethodLinter) Interfaces() []InterfaceDecl {
+	decls := make([]InterfaceDecl, 0, len(l.interfaces))
+	for _, decl := range l.interfaces {
+		if decl.Key != "" {
+			for _, impl := range l.findImplementers(decl.Key) {
+				decl.Implementers = append(decl.Implementers, impl.name)
+			}
+		}
+		decls = append(decls, decl)
+	}
+	return decls
+}
+
+// TypesPackages возвращает информацию о типах загруженных пакетов
+func (l *UnusedMethodLinter) TypesPackages() []*types.Package {
+	var pkgs []*types.Package
 	for _, pkg := range l.packages {
-		for _, file := range pkg.Syntax {
-			if l.shouldSkipFile(pkg, file) {
+		if pkg.Types != nil {
+			pkgs = append(pkgs, pkg.Types)
+		}
+	}
+	return pkgs
+}
+
+// ExtractInterfaceMethods извлекает все методы интерфейсов из фактов загруженных пакетов
+// и пакетов из кэша
+func (l *UnusedMethodLinter) ExtractInterfaceMethods() {
+	for _, facts := range l.packageFacts() {
+		for _, filename := range facts.Files {
+			if l.skipFile(facts.PkgPath, filename) {
 				continue
 			}
-
-			filename := pkg.Fset.Position(file.Pos()).Filename
+			if !l.inScope(filename) {
+				continue // невыбранные пакеты нужны только для поиска использований
+			}
 			if l.verbose {
-				fmt.Printf("  Analyzing: %s\n", getRelativePath(filename))
+				l.logf("  Analyzing: %s\n", getRelativePath(filename))
 			}
 
-			l.ExtractInterfaceMethodsFromFile(pkg, file, filename)
+			for _, method := range facts.Methods {
+				if method.File == filename {
+					l.methods = append(l.methods, method)
+				}
+			}
+			for _, embed := range facts.Embeds {
+				if embed.File == filename {
+					l.embeds = append(l.embeds, embed)
+				}
+			}
+			for _, decl := range facts.Interfaces {
+				if decl.Position.File == filename {
+					l.interfaces = append(l.interfaces, decl)
+				}
+			}
+			for _, warning := range facts.GenericWarnings {
+				if warning.File == filename {
+					l.genericWarnings = append(l.genericWarnings, warning)
+					if l.verbose {
+						l.logf("⚠️  WARNING: Skipping generic interface '%s%s' at %s:%d (%d methods)\n",
+							warning.InterfaceName, warning.TypeParams, getRelativePath(filename), warning.Line, warning.MethodCount)
+					}
+				}
+			}
+			for _, d := range facts.Directives {
+				if d.Position.File == filename {
+					l.directives = append(l.directives, d)
+				}
+			}
 		}
 	}
 }
 
+// SetScope ограничивает проверку интерфейсами из выбранных файлов.
+// Использования по-прежнему ищутся во всех загруженных пакетах
+func (l *UnusedMethodLinter) SetScope(contains func(file string) bool) {
+	l.scope = contains
+}
+
+// SetOverlay задает содержимое файлов по абсолютному пути, которое используется вместо
+// файлов на диске: несохраненные изменения из редактора
+func (l *UnusedMethodLinter) SetOverlay(overlay map[string][]byte) {
+	l.overlay = overlay
+}
+
+// SetAllEvidence включает сбор всех использований каждого метода вместо первого найденного:
+// для объяснения вердикта, а не для обычного отчета
+func (l *UnusedMethodLinter) SetAllEvidence(all bool) {
+	l.allEvidence = all
+}
+
+// inScope сообщает, проверяются ли интерфейсы из файла
+func (l *UnusedMethodLinter) inScope(filename string) bool {
+	return l.scope == nil || l.scope(filename)
+}
+
 // ExtractInterfaceMethodsFromFile извлекает методы интерфейсов из файла
 func (l *UnusedMethodLinter) ExtractInterfaceMethodsFromFile(pkg *packages.Package, file *ast.File, filename string) {
+	fileSuppression := suppress.ForFile(pkg.Fset, file)
+
+	var (
+		decl          *ast.GenDecl // type-декларация, внутри которой находится текущая спецификация
+		genericRanges [][2]int     // строки дженерик-интерфейсов вместе с комментариями
+	)
 	ast.Inspect(file, func(n ast.Node) bool {
 		switch x := n.(type) {
+		case *ast.GenDecl:
+			decl = x
 		case *ast.TypeSpec:
 			if interfaceType, ok := x.Type.(*ast.InterfaceType); ok {
+				l.interfaces = append(l.interfaces, l.interfaceDecl(pkg, x, interfaceType))
+
 				// НОВАЯ ПРОВЕРКА: детектируем дженерики
 				if l.isGenericInterface(x) {
-					l.addGenericWarning(x, interfaceType, filename, pkg.Fset)
+					l.addGenericWarning(pkg, x, interfaceType, filename)
+					genericRanges = append(genericRanges, declLines(pkg.Fset, decl, x))
 					return true // Пропускаем анализ дженерик-интерфейсов
 				}
 
@@ -136,7 +449,8 @@ func (l *UnusedMethodLinter) ExtractInterfaceMethodsFromFile(pkg *packages.Packa
 				if obj := pkg.TypesInfo.Defs[x.Name]; obj != nil {
 					if namedType, ok := obj.Type().(*types.Named); ok {
 						if iface, ok := namedType.Underlying().(*types.Interface); ok {
-							l.extractMethodsFromInterface(pkg, x.Name.Name, interfaceType, iface, filename)
+							ifaceSuppression := suppress.Resolve(nil, suppress.ForInterface(pkg.Fset, decl, x), fileSuppression)
+							l.extractMethodsFromInterface(pkg, x.Name.Name, interfaceType, iface, filename, ifaceSuppression)
 						}
 					}
 				}
@@ -144,6 +458,67 @@ func (l *UnusedMethodLinter) ExtractInterfaceMethodsFromFile(pkg *packages.Packa
 		}
 		return true
 	})
+
+	// Директивы на дженерик-интерфейсах не проверяются: их методы не анализируются
+	for _, d := range suppress.Collect(pkg.Fset, file) {
+		inGeneric := false
+		for _, r := range genericRanges {
+			if d.Position.Line >= r[0] && d.Position.Line <= r[1] {
+				inGeneric = true
+				break
+			}
+		}
+		if !inGeneric {
+			l.directives = append(l.directives, d)
+		}
+	}
+}
+
+// interfaceDecl описывает объявление интерфейса: встроенные интерфейсы с полными путями
+// пакетов и ключ для поиска реализаций
+func (l *UnusedMethodLinter) interfaceDecl(pkg *packages.Package, spec *ast.TypeSpec, interfaceAST *ast.InterfaceType) InterfaceDecl {
+	generic := l.isGenericInterface(spec)
+	decl := InterfaceDecl{
+		PkgPath:  pkg.PkgPath,
+		Name:     spec.Name.Name,
+		Position: results.NewPosition(pkg.Fset.Position(spec.Name.Pos())),
+		Embeds:   []string{},
+	}
+	if generic {
+		decl.TypeParams = l.getTypeParamsString(spec.TypeParams)
+	}
+	qualifier := func(p *types.Package) string { return p.Path() }
+	for _, field := range interfaceAST.Methods.List {
+		if len(field.Names) > 0 {
+			continue
+		}
+		if typ := pkg.TypesInfo.TypeOf(field.Type); typ != nil {
+			decl.Embeds = append(decl.Embeds, types.TypeString(typ, qualifier))
+		} else {
+			decl.Embeds = append(decl.Embeds, types.ExprString(field.Type))
+		}
+	}
+	if obj := pkg.TypesInfo.Defs[spec.Name]; obj != nil && !generic {
+		if iface, ok := obj.Type().Underlying().(*types.Interface); ok && iface.NumMethods() > 0 {
+			decl.Key = interfaceKey(iface)
+		}
+	}
+	return decl
+}
+
+// declLines возвращает первую и последнюю строки объявления типа вместе с комментариями
+func declLines(fset *token.FileSet, decl *ast.GenDecl, spec *ast.TypeSpec) [2]int {
+	start, end := spec.Pos(), spec.End()
+	if spec.Doc != nil {
+		start = spec.Doc.Pos()
+	}
+	if decl != nil && decl.Doc != nil && decl.Doc.Pos() < start {
+		start = decl.Doc.Pos()
+	}
+	if spec.Comment != nil && spec.Comment.End() > end {
+		end = spec.Comment.End()
+	}
+	return [2]int{fset.Position(start).Line, fset.Position(end).Line}
 }
 
 // isGenericInterface проверяет, является ли интерфейс дженериком
@@ -152,8 +527,8 @@ func (l *UnusedMethodLinter) isGenericInterface(typeSpec *ast.TypeSpec) bool {
 }
 
 // addGenericWarning добавляет предупреждение о дженерик-интерфейсе
-func (l *UnusedMethodLinter) addGenericWarning(typeSpec *ast.TypeSpec, interfaceType *ast.InterfaceType, filename string, fset *token.FileSet) {
-	position := fset.Position(typeSpec.Pos())
+func (l *UnusedMethodLinter) addGenericWarning(pkg *packages.Package, typeSpec *ast.TypeSpec, interfaceType *ast.InterfaceType, filename string) {
+	position := pkg.Fset.Position(typeSpec.Pos())
 
 	// Подсчитываем количество методов
 	methodCount := 0
@@ -167,6 +542,7 @@ func (l *UnusedMethodLinter) addGenericWarning(typeSpec *ast.TypeSpec, interface
 	typeParams := l.getTypeParamsString(typeSpec.TypeParams)
 
 	warning := GenericWarning{
+		PkgPath:       pkg.PkgPath,
 		InterfaceName: typeSpec.Name.Name,
 		File:          filename,
 		Line:          position.Line,
@@ -175,11 +551,6 @@ func (l *UnusedMethodLinter) addGenericWarning(typeSpec *ast.TypeSpec, interface
 	}
 
 	l.genericWarnings = append(l.genericWarnings, warning)
-
-	if l.verbose {
-		fmt.Printf("⚠️  WARNING: Skipping generic interface '%s%s' at %s:%d (%d methods)\n",
-			warning.InterfaceName, warning.TypeParams, getRelativePath(filename), warning.Line, warning.MethodCount)
-	}
 }
 
 // getTypeParamsString извлекает строковое представление типовых параметров
@@ -202,29 +573,201 @@ func (l *UnusedMethodLinter) getTypeParamsString(typeParams *ast.FieldList) stri
 	return "[" + strings.Join(params, ", ") + "]"
 }
 
-// extractMethodsFromInterface извлекает методы из интерфейса
+// extractMethodsFromInterface извлекает методы из интерфейса.
+// Директивы подавления читаются из комментариев метода, иначе действует ifaceSuppression
 func (l *UnusedMethodLinter) extractMethodsFromInterface(pkg *packages.Package, interfaceName string,
-	interfaceAST *ast.InterfaceType, interfaceType *types.Interface, filename string) {
+	interfaceAST *ast.InterfaceType, interfaceType *types.Interface, filename string, ifaceSuppression *results.Suppression) {
 
-	for _, method := range interfaceAST.Methods.List {
+	exported := token.IsExported(interfaceName) && importable(pkg)
+	for i, method := range interfaceAST.Methods.List {
+		suppression := suppress.Resolve(suppress.ForMethod(pkg.Fset, method), ifaceSuppression, nil)
 		if len(method.Names) == 0 {
-			continue // Встроенный интерфейс
+			l.extractEmbed(pkg, interfaceName, interfaceAST.Methods, i, interfaceType, filename, suppression)
+			continue
 		}
 
 		for _, name := range method.Names {
 			position := pkg.Fset.Position(name.Pos())
-			signature := l.getMethodSignature(method)
+			signature := l.getTypedMethodSignature(pkg, interfaceType, name.Name)
+			if signature == "" {
+				signature = l.getMethodSignature(method)
+			}
 
-			l.methods = append(l.methods, InterfaceMethod{
+			ifaceMethod := InterfaceMethod{
+				PkgPath:       pkg.PkgPath,
 				InterfaceName: interfaceName,
 				MethodName:    name.Name,
 				Signature:     signature,
 				File:          filename,
 				Line:          position.Line,
+				Range:         results.NewRange(pkg.Fset, name.Pos(), name.End()),
+				Fix:           removeMethodFix(pkg.Fset, interfaceAST.Methods, i),
+				Suppression:   suppression,
 				Interface:     interfaceType,
-			})
+				Exported:      exported && name.IsExported(),
+			}
+			ifaceMethod.setKeys()
+			l.methods = append(l.methods, ifaceMethod)
+		}
+	}
+}
+
+// extractEmbed запоминает встроенный интерфейс с методами, которые он добавляет.
+// Элементы ограничений и интерфейсы без методов не проверяются
+func (l *UnusedMethodLinter) extractEmbed(pkg *packages.Package, interfaceName string, list *ast.FieldList, idx int,
+	interfaceType *types.Interface, filename string, suppression *results.Suppression) {
+
+	field := list.List[idx]
+	embedded, ok := pkg.TypesInfo.TypeOf(field.Type).(*types.Named)
+	if !ok {
+		return
+	}
+	iface, ok := embedded.Underlying().(*types.Interface)
+	if !ok || !iface.IsMethodSet() || iface.NumMethods() == 0 {
+		return
+	}
+
+	embed := EmbeddedInterface{
+		PkgPath:       pkg.PkgPath,
+		InterfaceName: interfaceName,
+		Embed:         types.ExprString(field.Type),
+		File:          filename,
+		Range:         results.NewRange(pkg.Fset, field.Type.Pos(), field.Type.End()),
+		Fix:           removeMethodFix(pkg.Fset, list, idx),
+		Suppression:   suppression,
+	}
+	for i := 0; i < iface.NumMethods(); i++ {
+		fn := iface.Method(i)
+		embed.Methods = append(embed.Methods, InterfaceMethod{
+			PkgPath:       pkg.PkgPath,
+			InterfaceName: interfaceName,
+			MethodName:    fn.Name(),
+			SignatureKey:  signatureKey(fn.Type().(*types.Signature)),
+			MethodKey:     methodKey(fn),
+			InterfaceKey:  interfaceKey(interfaceType),
+		})
+	}
+	l.embeds = append(l.embeds, embed)
+}
+
+// importable сообщает, могут ли пакет импортировать другие модули: не main и не internal
+func importable(pkg *packages.Package) bool {
+	if pkg.Name == "main" {
+		return false
+	}
+	for _, elem := range strings.Split(pkg.PkgPath, "/") {
+		if elem == "internal" {
+			return false
 		}
 	}
+	return true
+}
+
+// setKeys вычисляет ключи метода для сравнения без информации о типах
+func (m *InterfaceMethod) setKeys() {
+	fn := lookupMethod(m.Interface, m.MethodName)
+	if fn == nil {
+		return
+	}
+	m.SignatureKey = signatureKey(fn.Type().(*types.Signature))
+	m.MethodKey = methodKey(fn)
+	m.InterfaceKey = interfaceKey(m.Interface)
+}
+
+// removeMethodFix строит исправление, удаляющее объявление метода вместе с комментариями.
+// Возвращает nil, если метод делит строку с другими элементами интерфейса
+func removeMethodFix(fset *token.FileSet, list *ast.FieldList, idx int) *results.Fix {
+	field := list.List[idx]
+	if len(field.Names) > 1 || !field.Pos().IsValid() {
+		return nil
+	}
+	file := fset.File(field.Pos())
+	if file == nil {
+		return nil
+	}
+
+	start, end := field.Pos(), field.End()
+	if field.Doc != nil {
+		start = field.Doc.Pos()
+	}
+	if field.Comment != nil {
+		end = field.Comment.End()
+	}
+
+	// Соседние элементы не должны находиться на удаляемых строках
+	prevEnd := list.Opening
+	if idx > 0 {
+		prev := list.List[idx-1]
+		prevEnd = prev.End()
+		if prev.Comment != nil {
+			prevEnd = prev.Comment.End()
+		}
+	}
+	nextStart := list.Closing
+	if idx+1 < len(list.List) {
+		next := list.List[idx+1]
+		nextStart = next.Pos()
+		if next.Doc != nil {
+			nextStart = next.Doc.Pos()
+		}
+	}
+	if !prevEnd.IsValid() || !nextStart.IsValid() {
+		return nil
+	}
+
+	startLine, endLine := file.Line(start), file.Line(end)
+	if file.Line(prevEnd) >= startLine || file.Line(nextStart) <= endLine {
+		return nil
+	}
+
+	description := fmt.Sprintf("Remove embedded interface %s from the interface", types.ExprString(field.Type))
+	if len(field.Names) == 1 {
+		description = fmt.Sprintf("Remove method %s from the interface", field.Names[0].Name)
+	}
+	return &results.Fix{
+		Description: description,
+		Edits: []results.Edit{{
+			Range: results.Range{
+				Start: results.Position{File: file.Name(), Line: startLine, Column: 1},
+				End:   results.Position{File: file.Name(), Line: endLine + 1, Column: 1},
+			},
+		}},
+	}
+}
+
+// getTypedMethodSignature получает сигнатуру метода из информации о типах.
+// Типы из других пакетов квалифицируются именем пакета
+func (l *UnusedMethodLinter) getTypedMethodSignature(pkg *packages.Package, iface *types.Interface, methodName string) string {
+	method := lookupMethod(iface, methodName)
+	if method == nil {
+		return ""
+	}
+	sig, ok := method.Type().(*types.Signature)
+	if !ok {
+		return ""
+	}
+	qualifier := func(p *types.Package) string {
+		if pkg.Types != nil && p.Path() == pkg.Types.Path() {
+			return ""
+		}
+		return p.Name()
+	}
+	var buf bytes.Buffer
+	types.WriteSignature(&buf, sig, qualifier)
+	return buf.String()
+}
+
+// lookupMethod возвращает метод интерфейса по имени или nil
+func lookupMethod(iface *types.Interface, name string) *types.Func {
+	if iface == nil {
+		return nil
+	}
+	for i := 0; i < iface.NumMethods(); i++ {
+		if iface.Method(i).Name() == name {
+			return iface.Method(i)
+		}
+	}
+	return nil
 }
 
 // getMethodSignature получает сигнатуру метода в виде строки
@@ -291,9 +834,11 @@ func (l *UnusedMethodLinter) typeToString(expr ast.Expr) string {
 	}
 }
 
-// FindUnusedMethods находит неиспользуемые методы
-func (l *UnusedMethodLinter) FindUnusedMethods() bool {
-	fmt.Println("DEBUG: Starting FindUnusedMethods")
+// FindUnusedMethods проверяет все методы интерфейсов и возвращает результаты
+func (l *UnusedMethodLinter) FindUnusedMethods() *results.Result {
+	if l.verbose {
+		l.logf("DEBUG: Starting FindUnusedMethods\n")
+	}
 
 	// Группируем методы по интерфейсам
 	interfaceMap := make(map[string][]InterfaceMethod)
@@ -301,372 +846,213 @@ func (l *UnusedMethodLinter) FindUnusedMethods() bool {
 		interfaceMap[method.InterfaceName] = append(interfaceMap[method.InterfaceName], method)
 	}
 
-	fmt.Printf("DEBUG: Found %d interfaces to check\n", len(interfaceMap))
+	if l.verbose {
+		l.logf("DEBUG: Found %d interfaces to check\n", len(interfaceMap))
+	}
 
-	unusedCount := 0
-	usedCount := 0
-	skippedMethodsCount := 0
+	res := &results.Result{LoadErrors: l.loadErrors}
+	implementers := make(map[string][]implementer)
 
 	interfaceNum := 0
 	for interfaceName, methods := range interfaceMap {
 		interfaceNum++
-		fmt.Printf("DEBUG: Checking interface %d/%d: %s (%d methods)\n",
-			interfaceNum, len(interfaceMap), interfaceName, len(methods))
-
 		if l.verbose {
-			fmt.Printf("Interface: %s\n", interfaceName)
+			l.logf("DEBUG: Checking interface %d/%d: %s (%d methods)\n",
+				interfaceNum, len(interfaceMap), interfaceName, len(methods))
+			l.logf("Interface: %s\n", interfaceName)
 		}
 
-		methodNum := 0
 		for _, method := range methods {
-			methodNum++
-			fmt.Printf("DEBUG: Checking method %d/%d: %s.%s\n",
-				methodNum, len(methods), interfaceName, method.MethodName)
-
-			used := l.isMethodUsed(method)
-			if !used {
-				fmt.Printf("UNUSED: %s.%s%s (%s:%d)\n",
-					method.InterfaceName, method.MethodName, method.Signature,
-					getRelativePath(method.File), method.Line)
-				unusedCount++
-			} else {
-				if l.verbose {
-					fmt.Printf("  USED: %s%s\n", method.MethodName, method.Signature)
+			finding := results.Finding{
+				PkgPath:     method.PkgPath,
+				Interface:   method.InterfaceName,
+				Method:      method.MethodName,
+				Signature:   method.Signature,
+				Range:       method.Range,
+				Verdict:     results.VerdictUnused,
+				Engine:      results.EngineLinter,
+				Suppression: method.Suppression,
+			}
+			finding.Evidence = l.findMethodUsages(method, l.allEvidence)
+			switch {
+			case slices.ContainsFunc(finding.Evidence, provesUseInCode):
+				finding.Verdict = results.VerdictUsed
+			case slices.ContainsFunc(finding.Evidence, results.Evidence.ProvesUse):
+				// Удаление метода сломает тесты, поэтому исправление не предлагается
+				finding.Kind = results.RuleTestOnly
+			default:
+				if method.Exported {
+					finding.Kind = results.RuleExportedUnused
+				}
+				if method.Fix != nil {
+					finding.Fixes = append(finding.Fixes, *method.Fix)
 				}
-				usedCount++
 			}
-		}
 
-	}
+			if method.InterfaceKey != "" {
+				if _, ok := implementers[method.InterfaceKey]; !ok {
+					implementers[method.InterfaceKey] = l.findImplementers(method.InterfaceKey)
+				}
+				finding.Implementations = findImplementations(implementers[method.InterfaceKey], method.MethodKey)
+			}
 
-	// Подсчитываем пропущенные методы из дженериков
-	for _, warning := range l.genericWarnings {
-		skippedMethodsCount += warning.MethodCount
+			res.Findings = append(res.Findings, finding)
+		}
 	}
 
-	// Выводим предупреждения о дженериках
-	l.printGenericWarnings()
-
-	// Итоговая статистика
-	fmt.Printf("\nDEBUG: Final stats - %d used, %d unused, %d total", usedCount, unusedCount, len(l.methods))
-	if skippedMethodsCount > 0 {
-		fmt.Printf(" (%d methods skipped due to generics)", skippedMethodsCount)
+	for _, embed := range l.embeds {
+		res.Findings = append(res.Findings, l.checkEmbed(embed))
 	}
-	fmt.Println()
 
-	return unusedCount == 0
-}
-
-// printGenericWarnings выводит предупреждения о дженериках
-func (l *UnusedMethodLinter) printGenericWarnings() {
-	if len(l.genericWarnings) == 0 {
-		return
+	for _, warning := range l.genericWarnings {
+		res.GenericWarnings = append(res.GenericWarnings, results.GenericWarning{
+			PkgPath:     warning.PkgPath,
+			Interface:   warning.InterfaceName,
+			TypeParams:  warning.TypeParams,
+			Position:    results.Position{File: warning.File, Line: warning.Line},
+			MethodCount: warning.MethodCount,
+		})
 	}
 
-	if !l.verbose {
-		// Краткий режим - только общая статистика
-		totalSkipped := 0
-		for _, warning := range l.genericWarnings {
-			totalSkipped += warning.MethodCount
-		}
+	res.Stale = suppress.Stale(l.directives, res.Findings)
 
-		if totalSkipped > 0 {
-			fmt.Printf("\n⚠️  %d generic interface%s skipped (%d methods not analyzed)\n",
-				len(l.genericWarnings),
-				func() string {
-					if len(l.genericWarnings) == 1 {
-						return ""
-					} else {
-						return "s"
-					}
-				}(),
-				totalSkipped)
-		}
-	} else {
-		// Подробный режим - детальные предупреждения
-		fmt.Println("\n⚠️  Generic Interface Warnings:")
-		for _, warning := range l.genericWarnings {
-			fmt.Printf("  - '%s%s' at %s:%d (%d methods skipped)\n",
-				warning.InterfaceName, warning.TypeParams, getRelativePath(warning.File), warning.Line, warning.MethodCount)
-		}
-	}
+	res.Sort()
+	return res
 }
 
-// isMethodUsed проверяет, используется ли метод в коде с учетом типов
-func (l *UnusedMethodLinter) isMethodUsed(method InterfaceMethod) bool {
-	if l.verbose {
-		fmt.Printf("    Checking usage of: %s.%s\n", method.InterfaceName, method.MethodName)
+// checkEmbed проверяет, используется ли через встраивающий интерфейс хотя бы один метод
+// встроенного интерфейса, в том числе в тестах
+func (l *UnusedMethodLinter) checkEmbed(embed EmbeddedInterface) results.Finding {
+	finding := results.Finding{
+		PkgPath:     embed.PkgPath,
+		Interface:   embed.InterfaceName,
+		Method:      embed.Embed,
+		Range:       embed.Range,
+		Verdict:     results.VerdictUnused,
+		Engine:      results.EngineLinter,
+		Suppression: embed.Suppression,
+		Kind:        results.RuleUnusedEmbed,
 	}
-
-	for _, pkg := range l.packages {
-		for _, file := range pkg.Syntax {
-			if l.shouldSkipFile(pkg, file) {
-				continue
-			}
-
-			filename := pkg.Fset.Position(file.Pos()).Filename
-			if l.verbose {
-				fmt.Printf("      Checking file: %s\n", getRelativePath(filename))
-			}
-
-			if l.checkMethodUsageWithTypes(pkg, file, method) {
-				if l.verbose {
-					fmt.Printf("        Found usage in %s\n", getRelativePath(filename))
-				}
-				return true
-			}
+	for _, method := range embed.Methods {
+		if evidence := l.findMethodUsages(method, false); len(evidence) > 0 {
+			finding.Verdict = results.VerdictUsed
+			finding.Evidence = evidence
+			return finding
 		}
 	}
-
-	if l.verbose {
-		fmt.Printf("      No usage found\n")
+	if embed.Fix != nil {
+		finding.Fixes = append(finding.Fixes, *embed.Fix)
 	}
-	return false
+	return finding
 }
 
-// checkMethodUsageWithTypes проверяет использование метода с учетом информации о типах
-func (l *UnusedMethodLinter) checkMethodUsageWithTypes(pkg *packages.Package, file *ast.File, method InterfaceMethod) bool {
-	if l.verbose {
-		fmt.Printf("      DEBUG: Checking method usage for %s.%s in file %s\n",
-			method.InterfaceName, method.MethodName, pkg.Fset.Position(file.Pos()).Filename)
-	}
+// provesUseInCode сообщает, доказывает ли доказательство использование метода вне тестов
+func provesUseInCode(e results.Evidence) bool {
+	return e.ProvesUse() && !isTestFile(e.Position.File)
+}
 
-	found := false
+// implementer - конкретный тип, реализующий интерфейс, и набор методов, через который
+type implementer struct {
+	name    string
+	methods map[string]results.Position
+}
 
-	ast.Inspect(file, func(n ast.Node) bool {
-		switch x := n.(type) {
-		case *ast.CallExpr:
-			// Проверяем вызовы методов (obj.method())
-			if sel, ok := x.Fun.(*ast.SelectorExpr); ok {
-				if sel.Sel.Name == method.MethodName {
-					if l.verbose {
-						fmt.Printf("        DEBUG: Found method call %s\n", sel.Sel.Name)
-					}
-					// Получаем тип объекта, на котором вызывается метод
-					if l.isMethodCallOnInterface(pkg, sel, method) {
-						found = true
-						return false
-					}
-				}
-			}
-		case *ast.SelectorExpr:
-			// Проверяем обращения к методу без вызова (obj.method)
-			if x.Sel.Name == method.MethodName {
-				if l.verbose {
-					fmt.Printf("        DEBUG: Found selector expression %s\n", x.Sel.Name)
-				}
-				// Проверяем, что это не поле структуры
-				if ident, ok := x.X.(*ast.Ident); ok {
-					if l.verbose {
-						fmt.Printf("        DEBUG: Found identifier %s\n", ident.Name)
-					}
-					// Получаем тип идентификатора
-					if obj := pkg.TypesInfo.ObjectOf(ident); obj != nil {
-						if l.verbose {
-							fmt.Printf("        DEBUG: Found object of type %T\n", obj)
-						}
-						// Проверяем, что это не поле структуры
-						if _, ok := obj.(*types.Var); !ok {
-							if l.isMethodCallOnInterface(pkg, x, method) {
-								found = true
-								return false
-							}
-						} else {
-							if l.verbose {
-								fmt.Printf("        DEBUG: Skipping field access\n")
-							}
-						}
-					}
-				} else {
-					if l.verbose {
-						fmt.Printf("        DEBUG: Non-identifier selector base: %T\n", x.X)
-					}
-					if l.isMethodCallOnInterface(pkg, x, method) {
-						found = true
-						return false
-					}
-				}
-			}
-		case *ast.Field:
-			// Проверяем поля структур
-			if ident, ok := x.Type.(*ast.Ident); ok {
-				if l.verbose {
-					fmt.Printf("        DEBUG: Found field with type %s\n", ident.Name)
-				}
-				// Проверяем, что это поле с типом нашего интерфейса
-				if ident.Name == method.InterfaceName {
-					if l.verbose {
-						fmt.Printf("        DEBUG: Field type matches interface name\n")
-					}
-					// Получаем тип поля
-					if obj := pkg.TypesInfo.ObjectOf(ident); obj != nil {
-						if l.verbose {
-							fmt.Printf("        DEBUG: Found field type object: %T\n", obj)
-						}
-						// Проверяем, что это тип
-						if _, ok := obj.(*types.TypeName); ok {
-							if l.verbose {
-								fmt.Printf("        DEBUG: Field type is a type name\n")
-							}
-							// Проверяем, что это наш интерфейс
-							if named, ok := obj.Type().(*types.Named); ok {
-								if iface, ok := named.Underlying().(*types.Interface); ok {
-									if l.verbose {
-										fmt.Printf("        DEBUG: Found interface with %d methods\n", iface.NumMethods())
-									}
-									// Проверяем, что это именно тот интерфейс, который мы ищем
-									if types.Identical(method.Interface, iface) {
-										if l.verbose {
-											fmt.Printf("        DEBUG: Interface types are identical\n")
-										}
-										// Проверяем, что метод используется
-										for i := 0; i < iface.NumMethods(); i++ {
-											if iface.Method(i).Name() == method.MethodName {
-												if l.verbose {
-													fmt.Printf("        DEBUG: Found method in interface\n")
-												}
-												found = true
-												return false
-											}
-										}
-									}
-								}
-							}
-						}
-					}
-				}
+// findImplementers ищет среди загруженных пакетов конкретные типы, реализующие интерфейс.
+// Для типов, реализующих интерфейс только по указателю, возвращается указатель
+func (l *UnusedMethodLinter) findImplementers(ifaceKey string) []implementer {
+	keys := strings.Split(ifaceKey, "\n")
+	var implementers []implementer
+	for _, facts := range l.packageFacts() {
+		for _, typ := range facts.Types {
+			switch {
+			case implements(typ.Methods, keys):
+				implementers = append(implementers, implementer{typ.Name, typ.Methods})
+			case implements(typ.PtrMethods, keys):
+				implementers = append(implementers, implementer{"*" + typ.Name, typ.PtrMethods})
 			}
 		}
-		return !found
-	})
-
-	if l.verbose {
-		if found {
-			fmt.Printf("      DEBUG: Method usage found\n")
-		} else {
-			fmt.Printf("      DEBUG: Method usage not found\n")
-		}
 	}
-
-	return found
+	return implementers
 }
 
-// isMethodCallOnInterface проверяет, вызывается ли метод на нужном интерфейсе
-func (l *UnusedMethodLinter) isMethodCallOnInterface(pkg *packages.Package, sel *ast.SelectorExpr, method InterfaceMethod) bool {
-	if l.verbose {
-		fmt.Printf("        DEBUG: Checking method call %s.%s\n", method.InterfaceName, method.MethodName)
-	}
-
-	// Получаем тип выражения слева от селектора
-	exprType := pkg.TypesInfo.TypeOf(sel.X)
-	if exprType == nil {
-		if l.verbose {
-			fmt.Printf("        DEBUG: No type info for expression\n")
+// findImplementations возвращает позиции реализаций метода в типах-реализациях
+func findImplementations(implementers []implementer, methodKey string) []results.Implementation {
+	var implementations []results.Implementation
+	for _, typ := range implementers {
+		position := typ.methods[methodKey]
+		if position.File == "" {
+			continue // метод без позиции, например из данных экспорта
 		}
-		return false
+		implementations = append(implementations, results.Implementation{Type: typ.name, Position: position})
 	}
+	return implementations
+}
 
+// findMethodUsages возвращает использования метода как доказательства: все или только первое.
+// Первым выбирается использование вне тестов, использование в тестах - только если других нет.
+// Возможные вызовы через reflect собираются только вместе со всеми использованиями
+func (l *UnusedMethodLinter) findMethodUsages(method InterfaceMethod, all bool) []results.Evidence {
 	if l.verbose {
-		fmt.Printf("        DEBUG: Expression type: %v\n", exprType.String())
+		l.logf("    Checking usage of: %s.%s\n", method.InterfaceName, method.MethodName)
 	}
-
-	// Убираем именованные типы
-	if named, ok := exprType.(*types.Named); ok {
-		if l.verbose {
-			fmt.Printf("        DEBUG: Found named type: %s\n", named.Obj().Name())
-		}
-		// Проверяем, что это именно тот интерфейс, который мы ищем
-		if named.Obj().Name() == method.InterfaceName {
-			if l.verbose {
-				fmt.Printf("        DEBUG: Direct match with interface name\n")
-			}
-			return true
-		}
-		exprType = named.Underlying()
+	if method.InterfaceKey == "" {
+		method.setKeys()
 	}
 
-	// Проверяем, является ли тип интерфейсом
-	iface, ok := exprType.(*types.Interface)
-	if !ok {
-		if l.verbose {
-			fmt.Printf("        DEBUG: Not an interface type: %T\n", exprType)
+	var evidence, inTests []results.Evidence
+	for _, u := range l.liveUsages() {
+		if u.Kind == results.EvidenceReflection && !all {
+			continue
+		}
+		if !u.uses(method) {
+			continue
 		}
-		return false
-	}
-
-	if l.verbose {
-		fmt.Printf("        DEBUG: Found interface with %d methods\n", iface.NumMethods())
-	}
-
-	// Проверяем, что это именно тот интерфейс, который мы ищем
-	if types.Identical(method.Interface, iface) {
 		if l.verbose {
-			fmt.Printf("        DEBUG: Interface types are identical\n")
+			l.logf("      Found usage in %s\n", getRelativePath(u.Position.File))
 		}
-		return true
-	}
-
-	// Проверяем, содержит ли интерфейс нужный метод с той же сигнатурой
-	for i := 0; i < iface.NumMethods(); i++ {
-		ifaceMethod := iface.Method(i)
-		if ifaceMethod.Name() == method.MethodName {
-			// Находим метод в исходном интерфейсе
-			for j := 0; j < method.Interface.NumMethods(); j++ {
-				origMethod := method.Interface.Method(j)
-				if origMethod.Name() == method.MethodName {
-					// Сравниваем сигнатуры методов
-					if types.Identical(ifaceMethod.Type(), origMethod.Type()) {
-						if l.verbose {
-							fmt.Printf("        DEBUG: Found matching method with identical signature\n")
-						}
-						return true
-					}
-				}
+		e := results.Evidence{Kind: u.Kind, Position: u.Position}
+		if !all && isTestFile(u.Position.File) {
+			if len(inTests) == 0 {
+				inTests = append(inTests, e)
 			}
+			continue
 		}
+		evidence = append(evidence, e)
+		if !all {
+			break
+		}
+	}
+	if len(evidence) == 0 {
+		evidence = inTests
 	}
 
-	if l.verbose {
-		fmt.Printf("        DEBUG: Interface types are different\n")
+	if l.verbose && len(evidence) == 0 {
+		l.logf("      No usage found\n")
 	}
-	return false
+	return evidence
 }
 
-// isSameInterface проверяет, являются ли два интерфейса одинаковыми
-func (l *UnusedMethodLinter) isSameInterface(iface1, iface2 *types.Interface) bool {
-	// Если количество методов разное, интерфейсы точно разные
-	if iface1.NumMethods() != iface2.NumMethods() {
-		return false
+// liveUsages возвращает использования из файлов всех пакетов, не исключенных конфигурацией.
+// Тестовые файлы не исключаются: вызов из них не делает метод используемым,
+// а только отличает методы, которые нужны одним тестам
+func (l *UnusedMethodLinter) liveUsages() []usage {
+	if l.usages != nil {
+		return l.usages
 	}
-
-	// Проверяем каждый метод из первого интерфейса
-	for i := 0; i < iface1.NumMethods(); i++ {
-		method1 := iface1.Method(i)
-		found := false
-
-		// Ищем метод с таким же именем во втором интерфейсе
-		for j := 0; j < iface2.NumMethods(); j++ {
-			method2 := iface2.Method(j)
-			if method1.Name() == method2.Name() {
-				// Нашли метод с таким же именем, проверяем сигнатуру
-				sig1 := method1.Type().(*types.Signature)
-				sig2 := method2.Type().(*types.Signature)
-
-				// Сравниваем сигнатуры
-				if types.Identical(sig1, sig2) {
-					found = true
-					break
-				}
+	l.usages = []usage{}
+	skipped := make(map[string]bool)
+	for _, facts := range l.packageFacts() {
+		for _, u := range facts.Usages {
+			file := u.Position.File
+			if _, ok := skipped[file]; !ok {
+				skipped[file] = !isTestFile(file) && l.config.ShouldIgnore(file)
+			}
+			if !skipped[file] {
+				l.usages = append(l.usages, u)
 			}
-		}
-
-		// Если не нашли метод с таким же именем и сигнатурой, интерфейсы разные
-		if !found {
-			return false
 		}
 	}
-
-	return true
+	return l.usages
 }
 
 // getRelativePath преобразует абсолютный путь в относительный от текущей директории
@@ -684,14 +1070,13 @@ func getRelativePath(filePath string) string {
 	return rel
 }
 
-// shouldSkipFile проверяет, нужно ли пропустить файл при анализе
-func (l *UnusedMethodLinter) shouldSkipFile(pkg *packages.Package, file *ast.File) bool {
+// skipFile проверяет, нужно ли пропустить файл пакета pkgPath
+func (l *UnusedMethodLinter) skipFile(pkgPath, filename string) bool {
 	// Пропускаем тестовые пакеты
-	if strings.HasSuffix(pkg.PkgPath, "_test") {
+	if strings.HasSuffix(pkgPath, "_test") {
 		return true
 	}
 
 	// Пропускаем файлы по конфигурации
-	filename := pkg.Fset.Position(file.Pos()).Filename
 	return l.config.ShouldIgnore(filename)
 }
@@ -18,6 +18,8 @@ import (
 	"golang.org/x/tools/go/packages"
 
 	"github.com/comerc/unused-interface-methods/pkg/config"
+	"github.com/comerc/unused-interface-methods/pkg/report"
+	"github.com/comerc/unused-interface-methods/pkg/results"
 )
 
 func TestUnusedMethodLinter(t *testing.T) {
@@ -45,8 +47,8 @@ func TestUnusedMethodLinter(t *testing.T) {
 		processDirect := findMethod(directProcessor, "ProcessDirect")
 		assert.NotNil(t, processDirect, "ProcessDirect method not found in DirectProcessor")
 		if processDirect != nil {
-			isUsed := linter.isMethodUsed(*processDirect)
-			assert.True(t, isUsed, "DirectProcessor.ProcessDirect should be used")
+			evidence := linter.findMethodUsages(*processDirect, false)
+			assert.NotEmpty(t, evidence, "DirectProcessor.ProcessDirect should be used")
 		}
 	})
 }
@@ -77,13 +79,14 @@ func findMethod(iface *types.Interface, name string) *InterfaceMethod {
 	return nil
 }
 
-// TestIsSameInterface проверяет корректность сравнения интерфейсов
-func TestIsSameInterface(t *testing.T) {
-	linter := &UnusedMethodLinter{}
-
+// TestInterfaceKey проверяет, что ключи интерфейсов совпадают только у одинаковых интерфейсов
+func TestInterfaceKey(t *testing.T) {
 	// Создаем тестовые интерфейсы
 	makeMethod := func(name string) *types.Func {
-		return types.NewFunc(token.NoPos, nil, name, types.NewSignature(nil, nil, nil, false))
+		return types.NewFunc(token.NoPos, nil, name, types.NewSignatureType(nil, nil, nil, nil, nil, false))
+	}
+	makeInterface := func(methods ...*types.Func) *types.Interface {
+		return types.NewInterfaceType(methods, nil).Complete()
 	}
 
 	tests := []struct {
@@ -94,62 +97,39 @@ func TestIsSameInterface(t *testing.T) {
 	}{
 		{
 			name:     "empty interfaces",
-			iface1:   types.NewInterface(nil, nil),
-			iface2:   types.NewInterface(nil, nil),
+			iface1:   makeInterface(),
+			iface2:   makeInterface(),
 			expected: true,
 		},
 		{
-			name: "same methods",
-			iface1: types.NewInterface([]*types.Func{
-				makeMethod("Method1"),
-				makeMethod("Method2"),
-			}, nil),
-			iface2: types.NewInterface([]*types.Func{
-				makeMethod("Method1"),
-				makeMethod("Method2"),
-			}, nil),
+			name:     "same methods",
+			iface1:   makeInterface(makeMethod("Method1"), makeMethod("Method2")),
+			iface2:   makeInterface(makeMethod("Method1"), makeMethod("Method2")),
 			expected: true,
 		},
 		{
-			name: "different order",
-			iface1: types.NewInterface([]*types.Func{
-				makeMethod("Method1"),
-				makeMethod("Method2"),
-			}, nil),
-			iface2: types.NewInterface([]*types.Func{
-				makeMethod("Method2"),
-				makeMethod("Method1"),
-			}, nil),
+			name:     "different order",
+			iface1:   makeInterface(makeMethod("Method1"), makeMethod("Method2")),
+			iface2:   makeInterface(makeMethod("Method2"), makeMethod("Method1")),
 			expected: true,
 		},
 		{
-			name: "different methods",
-			iface1: types.NewInterface([]*types.Func{
-				makeMethod("Method1"),
-				makeMethod("Method2"),
-			}, nil),
-			iface2: types.NewInterface([]*types.Func{
-				makeMethod("Method1"),
-				makeMethod("Method3"),
-			}, nil),
+			name:     "different methods",
+			iface1:   makeInterface(makeMethod("Method1"), makeMethod("Method2")),
+			iface2:   makeInterface(makeMethod("Method1"), makeMethod("Method3")),
 			expected: false,
 		},
 		{
-			name: "different count",
-			iface1: types.NewInterface([]*types.Func{
-				makeMethod("Method1"),
-				makeMethod("Method2"),
-			}, nil),
-			iface2: types.NewInterface([]*types.Func{
-				makeMethod("Method1"),
-			}, nil),
+			name:     "different count",
+			iface1:   makeInterface(makeMethod("Method1"), makeMethod("Method2")),
+			iface2:   makeInterface(makeMethod("Method1")),
 			expected: false,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := linter.isSameInterface(tt.iface1, tt.iface2)
+			result := interfaceKey(tt.iface1) == interfaceKey(tt.iface2)
 			assert.Equal(t, tt.expected, result, "unexpected result for test case %s", tt.name)
 		})
 	}
@@ -169,7 +149,7 @@ func TestFindUnusedMethods(t *testing.T) {
 			expectedCode: 0,
 			shouldContain: []string{
 				"UNUSED:",
-				"\n  USED:",
+				"\nOK:",
 				"WARNING:",
 				"Analyzing:",
 				"Skipping generic interface",
@@ -184,7 +164,7 @@ func TestFindUnusedMethods(t *testing.T) {
 				"UNUSED:",
 			},
 			shouldNotContain: []string{
-				"\n  USED:",
+				"\nOK:",
 				"WARNING:",
 				"Analyzing:",
 				"Skipping generic interface",
@@ -233,8 +213,10 @@ func TestFindUnusedMethods(t *testing.T) {
 				config.OsExit = oldOsExit
 			}()
 
-			// Ищем неиспользуемые методы
-			linter.FindUnusedMethods()
+			// Ищем неиспользуемые методы и выводим отчет
+			res := linter.FindUnusedMethods()
+			err = report.Text{Verbose: tt.verbose}.Report(os.Stdout, res)
+			assert.NoError(t, err, "Report() failed")
 
 			// Закрываем файл для записи и открываем для чтения
 			tmpfile.Close()
@@ -260,7 +242,9 @@ func TestFindUnusedMethods(t *testing.T) {
 	}
 }
 
-func TestIsMethodCallOnInterface_PointerHandling(t *testing.T) {
+// TestUsesPointerHandling проверяет, что вызов через интерфейс использует метод,
+// а прямой вызов на указателе на структуру - нет
+func TestUsesPointerHandling(t *testing.T) {
 	// Загружаем тестовые данные
 	fset := token.NewFileSet()
 	f, err := parser.ParseFile(fset, "../../test/data/interfaces.go", nil, 0)
@@ -281,14 +265,6 @@ func TestIsMethodCallOnInterface_PointerHandling(t *testing.T) {
 	pkg, err := conf.Check("../../test/data", fset, []*ast.File{f}, info)
 	assert.NoError(t, err)
 
-	// Создаем линтер
-	linter := &UnusedMethodLinter{
-		methods:         make([]InterfaceMethod, 0),
-		genericWarnings: make([]GenericWarning, 0),
-		verbose:         true,
-		config:          config.DefaultConfig(),
-	}
-
 	// Создаем интерфейс для проверки
 	pointerIface := pkg.Scope().Lookup("PointerHandler").Type().Underlying().(*types.Interface)
 
@@ -297,6 +273,7 @@ func TestIsMethodCallOnInterface_PointerHandling(t *testing.T) {
 		MethodName:    "HandlePointer",
 		Interface:     pointerIface,
 	}
+	pointerMethod.setKeys()
 
 	// Находим все вызовы методов HandlePointer
 	var interfaceCalls []*ast.SelectorExpr
@@ -313,25 +290,29 @@ func TestIsMethodCallOnInterface_PointerHandling(t *testing.T) {
 		}
 		return true
 	})
-
-	// Создаем тестовый пакет для packages.Package
-	testPkg := &packages.Package{
-		Types:     pkg,
-		TypesInfo: info,
+	assert.NotEmpty(t, interfaceCalls)
+	assert.NotEmpty(t, directCalls)
+
+	// Использования собираются так же, как для кэша фактов
+	usages := collectUsages(&packages.Package{Fset: fset, Types: pkg, TypesInfo: info}, f)
+	usedAt := func(sel *ast.SelectorExpr) bool {
+		position := results.NewPosition(fset.Position(sel.Pos()))
+		for _, u := range usages {
+			if u.Position == position && u.uses(pointerMethod) {
+				return true
+			}
+		}
+		return false
 	}
 
 	// Проверяем вызовы через интерфейс
-	fmt.Printf("\nTesting interface HandlePointer calls:\n")
 	for i, sel := range interfaceCalls {
-		result := linter.isMethodCallOnInterface(testPkg, sel, pointerMethod)
-		assert.True(t, result, "HandlePointer call %d should be considered an interface call", i)
+		assert.True(t, usedAt(sel), "HandlePointer call %d should be considered an interface call", i)
 	}
 
 	// Проверяем прямые вызовы
-	fmt.Printf("\nTesting direct HandlePointer calls:\n")
 	for i, sel := range directCalls {
-		result := linter.isMethodCallOnInterface(testPkg, sel, pointerMethod)
-		assert.False(t, result, "Direct HandlePointer call %d should not be considered an interface call", i)
+		assert.False(t, usedAt(sel), "Direct HandlePointer call %d should not be considered an interface call", i)
 	}
 }
 
@@ -347,30 +328,12 @@ func TestGetTypeParamsString_Empty(t *testing.T) {
 	assert.Empty(t, linter.getTypeParamsString(emptyList), "should return empty string for empty list")
 }
 
-// TestIsMethodCallOnInterface_NoTypeInfo проверяет поведение функции
-// isMethodCallOnInterface когда TypesInfo не содержит информацию о типе
-func TestIsMethodCallOnInterface_NoTypeInfo(t *testing.T) {
-	// Перехватываем stdout для проверки отладочного сообщения
-	oldStdout := os.Stdout
-	r, w, _ := os.Pipe()
-	os.Stdout = w
-
-	// Создаем линтер
-	linter := &UnusedMethodLinter{
-		methods:         make([]InterfaceMethod, 0),
-		genericWarnings: make([]GenericWarning, 0),
-		verbose:         true,
-		config:          config.DefaultConfig(),
-	}
-
-	// Создаем пустой интерфейс для теста
-	iface := types.NewInterface(nil, nil)
-
-	method := InterfaceMethod{
-		InterfaceName: "TestInterface",
-		MethodName:    "TestMethod",
-		Interface:     iface,
-	}
+// TestCollectUsages_NoTypeInfo проверяет, что выражения без информации о типах
+// не становятся использованиями
+func TestCollectUsages_NoTypeInfo(t *testing.T) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "main.go", "package main\n\nfunc main() {\n\tundefinedVar.TestMethod()\n\t_ = undefinedVar.TestMethod\n}\n", 0)
+	assert.NoError(t, err)
 
 	// Создаем пустой TypesInfo (без информации о типах)
 	info := &types.Info{
@@ -380,72 +343,13 @@ func TestIsMethodCallOnInterface_NoTypeInfo(t *testing.T) {
 		Selections: make(map[*ast.SelectorExpr]*types.Selection),
 	}
 
-	// Создаем тестовый пакет
-	testPkg := &packages.Package{
-		TypesInfo: info,
-	}
-
-	// Создаем селектор с неразрешенным идентификатором
-	sel := &ast.SelectorExpr{
-		X: &ast.Ident{
-			Name: "undefinedVar", // Неразрешенный идентификатор
-		},
-		Sel: &ast.Ident{
-			Name: "TestMethod",
-		},
-	}
-
-	// Проверяем, что функция возвращает false и выводит отладочное сообщение
-	result := linter.isMethodCallOnInterface(testPkg, sel, method)
-
-	// Закрываем pipe и восстанавливаем stdout
-	w.Close()
-	output := make([]byte, 1024)
-	_, _ = r.Read(output)
-	os.Stdout = oldStdout
-
-	// Проверяем результат
-	assert.False(t, result, "Method call with no type info should return false")
+	usages := collectUsages(&packages.Package{Fset: fset, TypesInfo: info}, f)
+	assert.Empty(t, usages, "Method call with no type info should not be a usage")
+	assert.Empty(t, collectUsages(&packages.Package{Fset: fset}, f), "package without type info has no usages")
 }
 
-// TestShouldSkipFile проверяет корректность определения пропускаемых файлов
-func TestShouldSkipFile(t *testing.T) {
-	// Создаем FileSet для позиций
-	fset := token.NewFileSet()
-	mainFileNode := fset.AddFile("main.go", -1, 1000)
-	mainFileNode.SetLines([]int{0, 100})
-	testFileNode := fset.AddFile("main_test.go", -1, 1000)
-	testFileNode.SetLines([]int{0, 100})
-	mockFileNode := fset.AddFile("mock/mock.go", -1, 1000)
-	mockFileNode.SetLines([]int{0, 100})
-
-	// Создаем тестовые файлы
-	mainFile := &ast.File{
-		Name:    &ast.Ident{Name: "main.go"},
-		Package: mainFileNode.Pos(1),
-	}
-
-	testFile := &ast.File{
-		Name:    &ast.Ident{Name: "main_test.go"},
-		Package: testFileNode.Pos(1),
-	}
-
-	mockFile := &ast.File{
-		Name:    &ast.Ident{Name: "mock.go"},
-		Package: mockFileNode.Pos(1),
-	}
-
-	// Создаем тестовые пакеты
-	mainPkg := &packages.Package{
-		PkgPath: "example.com/myapp",
-		Fset:    fset,
-	}
-
-	testPkg := &packages.Package{
-		PkgPath: "example.com/myapp_test",
-		Fset:    fset,
-	}
-
+// TestSkipFile проверяет корректность определения пропускаемых файлов
+func TestSkipFile(t *testing.T) {
 	// Создаем линтер
 	linter := &UnusedMethodLinter{
 		verbose: true,
@@ -455,115 +359,64 @@ func TestShouldSkipFile(t *testing.T) {
 	// Проверяем различные случаи
 	tests := []struct {
 		name     string
-		pkg      *packages.Package
-		file     *ast.File
+		pkgPath  string
+		file     string
 		expected bool
 	}{
 		{
 			name:     "regular file",
-			pkg:      mainPkg,
-			file:     mainFile,
+			pkgPath:  "example.com/myapp",
+			file:     "main.go",
 			expected: false,
 		},
 		{
 			name:     "test package",
-			pkg:      testPkg,
-			file:     testFile,
+			pkgPath:  "example.com/myapp_test",
+			file:     "main_test.go",
 			expected: true,
 		},
 		{
 			name:     "file in ignored directory",
-			pkg:      mainPkg,
-			file:     mockFile,
+			pkgPath:  "example.com/myapp",
+			file:     "mock/mock.go",
 			expected: true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := linter.shouldSkipFile(tt.pkg, tt.file)
+			result := linter.skipFile(tt.pkgPath, tt.file)
 			assert.Equal(t, tt.expected, result, "неожиданный результат для случая %s", tt.name)
 		})
 	}
 }
 
-// TestIsMethodUsed_SkipFiles проверяет пропуск файлов в isMethodUsed
-func TestIsMethodUsed_SkipFiles(t *testing.T) {
-	// Создаем FileSet для позиций
-	fset := token.NewFileSet()
-	mainFileNode := fset.AddFile("main.go", -1, 1000)
-	mainFileNode.SetLines([]int{0, 100})
-	testFileNode := fset.AddFile("main_test.go", -1, 1000)
-	testFileNode.SetLines([]int{0, 100})
-	mockFileNode := fset.AddFile("mock/mock.go", -1, 1000)
-	mockFileNode.SetLines([]int{0, 100})
-
-	// Создаем тестовые файлы
-	mainFile := &ast.File{
-		Name:    &ast.Ident{Name: "main.go"},
-		Package: mainFileNode.Pos(1),
-		Decls: []ast.Decl{
-			&ast.FuncDecl{
-				Name: &ast.Ident{Name: "TestMethod"},
-				Type: &ast.FuncType{},
-			},
-		},
-	}
-
-	testFile := &ast.File{
-		Name:    &ast.Ident{Name: "main_test.go"},
-		Package: testFileNode.Pos(1),
-		Decls: []ast.Decl{
-			&ast.FuncDecl{
-				Name: &ast.Ident{Name: "TestMethod"},
-				Type: &ast.FuncType{},
-			},
-		},
-	}
-
-	mockFile := &ast.File{
-		Name:    &ast.Ident{Name: "mock.go"},
-		Package: mockFileNode.Pos(1),
-		Decls: []ast.Decl{
-			&ast.FuncDecl{
-				Name: &ast.Ident{Name: "TestMethod"},
-				Type: &ast.FuncType{},
-			},
-		},
-	}
-
-	// Создаем тестовые пакеты
-	mainPkg := &packages.Package{
-		PkgPath: "example.com/myapp",
-		Fset:    fset,
-		Syntax:  []*ast.File{mainFile},
-	}
-
-	testPkg := &packages.Package{
-		PkgPath: "example.com/myapp_test",
-		Fset:    fset,
-		Syntax:  []*ast.File{testFile},
-	}
-
-	mockPkg := &packages.Package{
-		PkgPath: "example.com/myapp/mock",
-		Fset:    fset,
-		Syntax:  []*ast.File{mockFile},
+// TestFindMethodUsages_SkipFiles проверяет, что использования из исключенных файлов
+// не учитываются, а из тестов - учитываются, но не доказывают использование в коде
+func TestFindMethodUsages_SkipFiles(t *testing.T) {
+	call := func(file string) usage {
+		return usage{
+			Kind:     results.EvidenceCall,
+			Method:   "TestMethod",
+			Named:    "TestInterface",
+			Position: results.Position{File: file, Line: 3},
+		}
 	}
 
-	// Создаем линтер
+	// Создаем линтер с фактами пакетов, как из кэша
 	linter := &UnusedMethodLinter{
 		verbose: true,
 		config:  config.DefaultConfig(),
-		packages: []*packages.Package{
-			mainPkg,
-			testPkg,
-			mockPkg,
+		facts: []*packageFacts{
+			{PkgPath: "example.com/myapp/mock", Files: []string{"mock/mock.go"}, Usages: []usage{call("mock/mock.go")}},
+			{PkgPath: "example.com/myapp_test", Usages: []usage{call("main_test.go")}},
 		},
 	}
 
 	// Создаем тестовый интерфейс и метод
-	iface := types.NewInterface(nil, nil)
+	iface := types.NewInterfaceType([]*types.Func{
+		types.NewFunc(token.NoPos, nil, "TestMethod", types.NewSignatureType(nil, nil, nil, nil, nil, false)),
+	}, nil).Complete()
 	method := InterfaceMethod{
 		InterfaceName: "TestInterface",
 		MethodName:    "TestMethod",
@@ -571,10 +424,13 @@ func TestIsMethodUsed_SkipFiles(t *testing.T) {
 	}
 
 	// Проверяем использование метода
-	result := linter.isMethodUsed(method)
+	evidence := linter.findMethodUsages(method, true)
 
-	// Метод не должен быть найден, так как все файлы должны быть пропущены
-	assert.False(t, result, "метод не должен быть найден в пропущенных файлах")
+	// Вызов из исключенного mock/mock.go пропущен, вызов из теста найден
+	if assert.Len(t, evidence, 1) {
+		assert.Equal(t, "main_test.go", evidence[0].Position.File)
+		assert.False(t, provesUseInCode(evidence[0]), "вызов из теста не доказывает использование в коде")
+	}
 }
 
 // mockConfig реализует интерфейс конфигурации для тестирования
@@ -809,3 +665,76 @@ type MockInterface interface {
 	}
 	assert.True(t, foundMainPackage, "основной пакет должен быть загружен")
 }
+
+// TestRemoveMethodFix проверяет построение исправления, удаляющего метод
+func TestRemoveMethodFix(t *testing.T) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "../../test/data/interfaces.go", nil, parser.ParseComments)
+	assert.NoError(t, err)
+
+	var eventHandler *ast.InterfaceType
+	ast.Inspect(f, func(n ast.Node) bool {
+		if ts, ok := n.(*ast.TypeSpec); ok && ts.Name.Name == "EventHandler" {
+			eventHandler = ts.Type.(*ast.InterfaceType)
+			return false
+		}
+		return true
+	})
+	assert.NotNil(t, eventHandler, "EventHandler interface not found")
+
+	// OnError занимает отдельную строку вместе с комментарием
+	fix := removeMethodFix(fset, eventHandler.Methods, 1)
+	assert.NotNil(t, fix)
+	assert.Len(t, fix.Edits, 1)
+	assert.Equal(t, 38, fix.Edits[0].Range.Start.Line)
+	assert.Equal(t, 1, fix.Edits[0].Range.Start.Column)
+	assert.Equal(t, 39, fix.Edits[0].Range.End.Line)
+	assert.Equal(t, 1, fix.Edits[0].Range.End.Column)
+
+	// Методы на одной строке построчно удалить нельзя
+	inline, err := parser.ParseFile(fset, "inline.go", "package p\ntype I interface{ A(); B() }\n", 0)
+	assert.NoError(t, err)
+	iface := inline.Decls[0].(*ast.GenDecl).Specs[0].(*ast.TypeSpec).Type.(*ast.InterfaceType)
+	assert.Nil(t, removeMethodFix(fset, iface.Methods, 0))
+}
+
+// TestFindUnusedMethods_Suppressions проверяет чтение директив подавления
+func TestFindUnusedMethods_Suppressions(t *testing.T) {
+	linter := New(config.DefaultConfig(), false)
+	linter.SetLogOutput(io.Discard)
+	assert.NoError(t, linter.LoadPackages("../suppress/testdata"))
+	linter.ExtractInterfaceMethods()
+	res := linter.FindUnusedMethods()
+
+	scopes := make(map[string]results.SuppressionScope)
+	for _, f := range res.Findings {
+		if f.Suppression != nil && f.Verdict == results.VerdictUnused {
+			scopes[f.Interface+"."+f.Method] = f.Suppression.Scope
+		}
+	}
+
+	assert.Equal(t, map[string]results.SuppressionScope{
+		"Storage.Store":           results.ScopeMethod,
+		"Storage.Purge":           results.ScopeMethod,
+		"Notifier.Notify":         results.ScopeInterface,
+		"Notifier.Close":          results.ScopeInterface,
+		"LegacyClient.Connect":    results.ScopeFile,
+		"LegacyClient.Disconnect": results.ScopeFile,
+	}, scopes)
+
+	s := res.Summary()
+	assert.Equal(t, 3, s.Unused) // Storage.Compact, Cache.Set, Cache.Delete
+	assert.Equal(t, 6, s.Suppressed)
+
+	// Директива на используемом методе и директива вне интерфейса устарели,
+	// директива на дженерик-интерфейсе не проверяется
+	var stale []string
+	for _, st := range res.Stale {
+		assert.Equal(t, results.StaleDirective, st.Kind)
+		stale = append(stale, fmt.Sprintf("%s:%d %s", filepath.Base(st.Position.File), st.Position.Line, st.Rule))
+	}
+	assert.Equal(t, []string{
+		"stale.go:5 unused-interface-methods:ignore",
+		"stale.go:15 nolint:unusedinterfacemethods",
+	}, stale)
+}
@@ -0,0 +1,525 @@
+package lsp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/comerc/unused-interface-methods/pkg/results"
+)
+
+// DefaultDebounce - пауза после последнего изменения перед анализом: анализ на каждое
+// нажатие клавиши не успевал бы за вводом
+const DefaultDebounce = 300 * time.Millisecond
+
+// source - источник диагностик в редакторе
+const source = "unused-interface-methods"
+
+// Analyzer анализирует дерево root; overlay - содержимое открытых в редакторе Go-файлов
+// по абсолютному пути, которое заменяет файлы на диске
+type Analyzer func(root string, overlay map[string][]byte) (*results.Result, error)
+
+// Options - настройки сервера
+type Options struct {
+	Root     string // корень анализа, если клиент не передал свой
+	Analyze  Analyzer
+	Debounce time.Duration // 0 - DefaultDebounce
+}
+
+// document - открытый в редакторе документ
+type document struct {
+	text    []byte
+	version int
+}
+
+// server хранит открытые документы и последний результат анализа
+type server struct {
+	opts Options
+	conn *conn
+
+	mu        sync.Mutex
+	root      string
+	docs      map[string]document // открытые документы по пути файла
+	result    *results.Result
+	analyzed  map[string]document // открытые документы, по которым получен result
+	published map[string]bool     // файлы, для которых опубликованы диагностики
+	shutdown  bool
+
+	trigger chan struct{}
+	done    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// Serve обслуживает клиента LSP через in и out, пока клиент не пришлет exit или не закроет in.
+// Анализ выполняется в фоне после открытия, изменения и сохранения документов, а его
+// результат публикуется диагностиками на методах интерфейсов
+func Serve(in io.Reader, out io.Writer, opts Options) error {
+	if opts.Debounce == 0 {
+		opts.Debounce = DefaultDebounce
+	}
+	s := &server{
+		opts:      opts,
+		conn:      newConn(in, out),
+		root:      opts.Root,
+		docs:      make(map[string]document),
+		published: make(map[string]bool),
+		trigger:   make(chan struct{}, 1),
+		done:      make(chan struct{}),
+	}
+	s.wg.Add(1)
+	go s.analyzeLoop()
+	defer func() {
+		close(s.done)
+		s.wg.Wait()
+	}()
+
+	for {
+		msg, err := s.conn.read()
+		var rerr *responseError
+		switch {
+		case errors.Is(err, io.EOF):
+			return nil
+		case errors.As(err, &rerr):
+			s.conn.write(&message{ID: json.RawMessage("null"), Error: rerr})
+			continue
+		case err != nil:
+			return err
+		}
+
+		if msg.Method == "exit" {
+			s.mu.Lock()
+			shutdown := s.shutdown
+			s.mu.Unlock()
+			if !shutdown {
+				return errors.New("exit without shutdown")
+			}
+			return nil
+		}
+
+		result, rerr := s.handle(msg)
+		if len(msg.ID) == 0 {
+			continue // на уведомления не отвечают
+		}
+		response := &message{ID: msg.ID, Error: rerr}
+		if rerr == nil {
+			if response.Result, err = json.Marshal(result); err != nil {
+				return err
+			}
+		}
+		if err := s.conn.write(response); err != nil {
+			return err
+		}
+	}
+}
+
+// handle выполняет запрос или уведомление и возвращает результат запроса
+func (s *server) handle(msg *message) (any, *responseError) {
+	switch msg.Method {
+	case "initialize":
+		var params initializeParams
+		if err := decode(msg.Params, &params); err != nil {
+			return nil, err
+		}
+		s.mu.Lock()
+		if root := uriToPath(params.RootURI); root != "" {
+			s.root = root
+		} else if len(params.WorkspaceFolders) > 0 {
+			s.root = uriToPath(params.WorkspaceFolders[0].URI)
+		}
+		s.mu.Unlock()
+		return map[string]any{
+			"capabilities": map[string]any{
+				"textDocumentSync": map[string]any{
+					"openClose": true,
+					"change":    2, // инкрементальные изменения
+					"save":      map[string]any{},
+				},
+				"hoverProvider":      true,
+				"codeActionProvider": map[string]any{"codeActionKinds": []string{"quickfix"}},
+			},
+			"serverInfo": map[string]string{"name": source},
+		}, nil
+
+	case "initialized":
+		s.schedule() // диагностики по всему дереву еще до открытия файлов
+
+	case "shutdown":
+		s.mu.Lock()
+		s.shutdown = true
+		s.mu.Unlock()
+		return nil, nil
+
+	case "textDocument/didOpen":
+		var params didOpenParams
+		if err := decode(msg.Params, &params); err != nil {
+			return nil, err
+		}
+		if path := uriToPath(params.TextDocument.URI); path != "" {
+			s.mu.Lock()
+			s.docs[path] = document{text: []byte(params.TextDocument.Text), version: params.TextDocument.Version}
+			s.mu.Unlock()
+			s.schedule()
+		}
+
+	case "textDocument/didChange":
+		var params didChangeParams
+		if err := decode(msg.Params, &params); err != nil {
+			return nil, err
+		}
+		path := uriToPath(params.TextDocument.URI)
+		s.mu.Lock()
+		doc, ok := s.docs[path]
+		if ok {
+			text := doc.text
+			for _, change := range params.ContentChanges {
+				if change.Range == nil {
+					text = []byte(change.Text)
+					continue
+				}
+				start, end := offsetOf(text, change.Range.Start), offsetOf(text, change.Range.End)
+				text = append(append(append([]byte{}, text[:start]...), change.Text...), text[max(start, end):]...)
+			}
+			s.docs[path] = document{text: text, version: params.TextDocument.Version}
+		}
+		s.mu.Unlock()
+		if ok {
+			s.schedule()
+		}
+
+	case "textDocument/didSave":
+		s.schedule()
+
+	case "textDocument/didClose":
+		var params textDocumentParams
+		if err := decode(msg.Params, &params); err != nil {
+			return nil, err
+		}
+		s.mu.Lock()
+		delete(s.docs, uriToPath(params.TextDocument.URI))
+		s.mu.Unlock()
+		s.schedule()
+
+	case "textDocument/codeAction":
+		var params codeActionParams
+		if err := decode(msg.Params, &params); err != nil {
+			return nil, err
+		}
+		return s.codeActions(params), nil
+
+	case "textDocument/hover":
+		var params positionParams
+		if err := decode(msg.Params, &params); err != nil {
+			return nil, err
+		}
+		return s.hover(params), nil
+
+	default:
+		if len(msg.ID) > 0 {
+			return nil, &responseError{Code: codeMethodNotFound, Message: "method not supported: " + msg.Method}
+		}
+	}
+	return nil, nil
+}
+
+// schedule запрашивает анализ; запросы до его начала объединяются
+func (s *server) schedule() {
+	select {
+	case s.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// analyzeLoop анализирует дерево после паузы в изменениях и публикует диагностики
+func (s *server) analyzeLoop() {
+	defer s.wg.Done()
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-s.trigger:
+		}
+		// Изменения во время паузы откладывают анализ
+		for wait := true; wait; {
+			select {
+			case <-s.done:
+				return
+			case <-s.trigger:
+			case <-time.After(s.opts.Debounce):
+				wait = false
+			}
+		}
+
+		s.mu.Lock()
+		root := s.root
+		docs := make(map[string]document, len(s.docs))
+		overlay := make(map[string][]byte)
+		for path, doc := range s.docs {
+			docs[path] = doc
+			if strings.HasSuffix(path, ".go") {
+				overlay[path] = doc.text
+			}
+		}
+		s.mu.Unlock()
+
+		res, err := s.opts.Analyze(root, overlay)
+		if err != nil {
+			s.notify("window/logMessage", logMessageParams{Type: messageError, Message: err.Error()})
+			continue
+		}
+		s.publish(res, docs)
+	}
+}
+
+// publish сохраняет результат анализа документов docs и публикует диагностики по файлам;
+// у файлов, в которых находок больше нет, диагностики очищаются. Документы, измененные
+// во время анализа, пропускаются: их диагностики опубликует следующий анализ
+func (s *server) publish(res *results.Result, docs map[string]document) {
+	s.mu.Lock()
+	s.result = res
+	s.analyzed = docs
+	byFile := make(map[string][]diagnostic)
+	texts := make(map[string][]byte)
+	for _, f := range res.Findings {
+		if !f.IsReported() {
+			continue
+		}
+		file := f.Range.Start.File
+		byFile[file] = append(byFile[file], s.diagnostic(f, texts))
+	}
+	files := make([]string, 0, len(byFile)+len(s.published))
+	for file := range byFile {
+		files = append(files, file)
+	}
+	for file := range s.published {
+		if _, ok := byFile[file]; !ok {
+			files = append(files, file)
+		}
+	}
+	sort.Strings(files)
+	published := make(map[string]bool, len(byFile))
+	var params []publishDiagnosticsParams
+	for _, file := range files {
+		_, found := byFile[file]
+		if !s.current(file) {
+			published[file] = found || s.published[file]
+			continue
+		}
+		published[file] = found
+		p := publishDiagnosticsParams{URI: pathToURI(file), Version: s.version(file), Diagnostics: byFile[file]}
+		if p.Diagnostics == nil {
+			p.Diagnostics = []diagnostic{}
+		}
+		params = append(params, p)
+	}
+	s.published = published
+	s.mu.Unlock()
+
+	for _, p := range params {
+		s.notify("textDocument/publishDiagnostics", p)
+	}
+}
+
+// current сообщает, что файл не менялся в редакторе после анализа: позиции находок
+// соответствуют его тексту
+func (s *server) current(path string) bool {
+	doc, open := s.docs[path]
+	old, analyzed := s.analyzed[path]
+	return open == analyzed && doc.version == old.version
+}
+
+// version возвращает версию документа, по которой выполнен анализ; nil - файл не был открыт
+func (s *server) version(path string) *int {
+	doc, ok := s.analyzed[path]
+	if !ok {
+		return nil
+	}
+	return &doc.version
+}
+
+// diagnostic описывает находку на имени метода в объявлении интерфейса
+func (s *server) diagnostic(f results.Finding, texts map[string][]byte) diagnostic {
+	severity := severityError
+	switch f.Level() {
+	case results.SeverityWarning:
+		severity = severityWarning
+	case results.SeverityInfo:
+		severity = severityInformation
+	}
+	return diagnostic{
+		Range:    s.lspRange(f.Range, texts),
+		Severity: severity,
+		Code:     string(f.RuleID()),
+		Source:   source,
+		Message:  fmt.Sprintf("Interface method %s.%s%s is not used", f.Interface, f.Method, f.Signature),
+	}
+}
+
+// codeActions предлагает исправления находок, диагностики которых пересекают диапазон.
+// Для документа, измененного после анализа, исправлений нет: позиции устарели
+func (s *server) codeActions(params codeActionParams) []codeAction {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	actions := []codeAction{}
+	path := uriToPath(params.TextDocument.URI)
+	if s.result == nil || !s.current(path) {
+		return actions
+	}
+	texts := make(map[string][]byte)
+	for _, f := range s.result.Findings {
+		if !f.IsReported() || f.Range.Start.File != path {
+			continue
+		}
+		d := s.diagnostic(f, texts)
+		if d.Range.End.Line < params.Range.Start.Line || d.Range.Start.Line > params.Range.End.Line {
+			continue
+		}
+	fixes:
+		for _, fix := range f.Fixes {
+			action := codeAction{Title: fix.Description, Kind: "quickfix", Diagnostics: []diagnostic{d}}
+			changes := make(map[string]int) // файл -> индекс в DocumentChanges
+			for _, edit := range fix.Edits {
+				file := edit.Range.Start.File
+				if !s.current(file) {
+					continue fixes
+				}
+				i, ok := changes[file]
+				if !ok {
+					i = len(action.Edit.DocumentChanges)
+					changes[file] = i
+					action.Edit.DocumentChanges = append(action.Edit.DocumentChanges, textDocumentEdit{
+						TextDocument: optionalVersionedTextDocumentIdentifier{URI: pathToURI(file), Version: s.version(file)},
+					})
+				}
+				action.Edit.DocumentChanges[i].Edits = append(action.Edit.DocumentChanges[i].Edits, textEdit{
+					Range:   s.lspRange(edit.Range, texts),
+					NewText: edit.NewText,
+				})
+			}
+			actions = append(actions, action)
+		}
+	}
+	return actions
+}
+
+// hover перечисляет доказательства использования метода под курсором: места вызовов
+// и другие обращения, из-за которых метод считается используемым. Для документа,
+// измененного после анализа, подсказки нет
+func (s *server) hover(params positionParams) *hover {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	path := uriToPath(params.TextDocument.URI)
+	if s.result == nil || !s.current(path) {
+		return nil
+	}
+	texts := make(map[string][]byte)
+	line := params.Position.Line + 1
+	column := byteColumn(lineOf(s.text(path, texts), line), params.Position.Character)
+	for _, f := range s.result.Findings {
+		r := f.Range
+		if r.Start.File != path || r.Start.Line != line || column < r.Start.Column || column > r.End.Column {
+			continue
+		}
+		return &hover{
+			Contents: markupContent{Kind: "markdown", Value: s.describe(f)},
+			Range:    s.lspRange(r, texts),
+		}
+	}
+	return nil
+}
+
+// describe описывает вердикт по методу в markdown
+func (s *server) describe(f results.Finding) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "**%s%s**\n\n", f.ID(), f.Signature)
+	switch {
+	case f.Verdict == results.VerdictUsed && len(f.Evidence) > 0:
+		b.WriteString("Used by:\n")
+		for _, e := range f.Evidence {
+			fmt.Fprintf(&b, "- %s", e.Kind)
+			if e.Position.File != "" {
+				fmt.Fprintf(&b, " at `%s:%d`", s.relative(e.Position.File), e.Position.Line)
+			}
+			if e.Detail != "" {
+				fmt.Fprintf(&b, " - %s", e.Detail)
+			}
+			b.WriteString("\n")
+		}
+	case f.Verdict == results.VerdictUsed:
+		b.WriteString("Used\n")
+	case f.Suppression != nil:
+		fmt.Fprintf(&b, "Not used, suppressed by `%s`", f.Suppression.Directive)
+		if f.Suppression.Reason != "" {
+			fmt.Fprintf(&b, " - %s", f.Suppression.Reason)
+		}
+		b.WriteString("\n")
+	case f.Verdict == results.VerdictUnused:
+		b.WriteString("Not used: no call sites keep this method alive\n")
+	default:
+		fmt.Fprintf(&b, "Verdict: %s\n", f.Verdict)
+	}
+	return b.String()
+}
+
+// lspRange переводит диапазон в байтовых столбцах в диапазон LSP по тексту файла
+func (s *server) lspRange(r results.Range, texts map[string][]byte) lspRange {
+	convert := func(p results.Position) position {
+		if p.Line < 1 {
+			return position{}
+		}
+		return position{Line: p.Line - 1, Character: utf16Column(lineOf(s.text(p.File, texts), p.Line), p.Column)}
+	}
+	end := r.End
+	if end.Line == 0 {
+		end = r.Start
+	}
+	return lspRange{Start: convert(r.Start), End: convert(end)}
+}
+
+// text возвращает текст файла, по которому выполнен анализ: открытый документ или файл
+// на диске; texts кэширует прочитанные файлы на время одного ответа
+func (s *server) text(path string, texts map[string][]byte) []byte {
+	if doc, ok := s.analyzed[path]; ok {
+		return doc.text
+	}
+	if text, ok := texts[path]; ok {
+		return text
+	}
+	text, _ := os.ReadFile(path)
+	texts[path] = text
+	return text
+}
+
+// relative возвращает путь относительно корня анализа
+func (s *server) relative(path string) string {
+	if rel, err := filepath.Rel(s.root, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel)
+	}
+	return path
+}
+
+// notify отправляет уведомление клиенту
+func (s *server) notify(method string, params any) {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return
+	}
+	s.conn.write(&message{Method: method, Params: data})
+}
+
+// decode разбирает параметры запроса
+func decode(raw json.RawMessage, v any) *responseError {
+	if len(raw) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return &responseError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
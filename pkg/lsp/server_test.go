@@ -0,0 +1,330 @@
+package lsp
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/comerc/unused-interface-methods/pkg/results"
+)
+
+// client - клиент LSP для тестов: общается с сервером через pipe,
+// разделяя ответы и уведомления
+type client struct {
+	t         *testing.T
+	conn      *conn
+	responses chan *message
+	notes     chan *message
+	id        int
+	done      chan error
+}
+
+// startServer запускает сервер с анализатором analyze
+func startServer(t *testing.T, root string, analyze Analyzer) *client {
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	c := &client{
+		t:         t,
+		conn:      newConn(outR, inW),
+		responses: make(chan *message, 16),
+		notes:     make(chan *message, 16),
+		done:      make(chan error, 1),
+	}
+	go func() {
+		c.done <- Serve(inR, outW, Options{Root: root, Analyze: analyze, Debounce: 10 * time.Millisecond})
+		outW.Close()
+	}()
+	go func() {
+		for {
+			msg, err := c.conn.read()
+			if err != nil {
+				close(c.notes)
+				return
+			}
+			if msg.Method != "" {
+				c.notes <- msg
+			} else {
+				c.responses <- msg
+			}
+		}
+	}()
+	t.Cleanup(func() { inW.Close() })
+	return c
+}
+
+// request отправляет запрос и возвращает ответ
+func (c *client) request(method string, params any, result any) *responseError {
+	c.t.Helper()
+	c.id++
+	c.send(&message{ID: json.RawMessage(strconv.Itoa(c.id)), Method: method}, params)
+	select {
+	case msg := <-c.responses:
+		if msg.Error != nil {
+			return msg.Error
+		}
+		assert.NoError(c.t, json.Unmarshal(msg.Result, result))
+	case <-time.After(5 * time.Second):
+		c.t.Fatalf("нет ответа на %s", method)
+	}
+	return nil
+}
+
+// notify отправляет уведомление
+func (c *client) notify(method string, params any) {
+	c.t.Helper()
+	c.send(&message{Method: method}, params)
+}
+
+func (c *client) send(msg *message, params any) {
+	c.t.Helper()
+	data, err := json.Marshal(params)
+	assert.NoError(c.t, err)
+	msg.Params = data
+	assert.NoError(c.t, c.conn.write(msg))
+}
+
+// diagnostics ждет публикации диагностик
+func (c *client) diagnostics() publishDiagnosticsParams {
+	c.t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case msg, ok := <-c.notes:
+			if !ok {
+				c.t.Fatal("сервер закрыл соединение")
+			}
+			if msg.Method != "textDocument/publishDiagnostics" {
+				continue
+			}
+			var params publishDiagnosticsParams
+			assert.NoError(c.t, json.Unmarshal(msg.Params, &params))
+			return params
+		case <-timeout:
+			c.t.Fatal("нет диагностик")
+		}
+	}
+}
+
+const loggerSource = `package app
+
+type Logger interface {
+	Info(msg string)
+	Debug(msg string)
+}
+`
+
+func TestServer(t *testing.T) {
+	root := t.TempDir()
+	logger := filepath.Join(root, "logger.go")
+	app := filepath.Join(root, "app.go")
+	assert.NoError(t, os.WriteFile(logger, []byte(loggerSource), 0644))
+	assert.NoError(t, os.WriteFile(app, []byte("package app\n\nfunc Run(l Logger) { l.Info(\"x\") }\n"), 0644))
+
+	// Анализатор для теста: Debug используется, если вызов есть в тексте app.go
+	var (
+		mu       sync.Mutex
+		overlays []map[string][]byte
+	)
+	analyze := func(dir string, overlay map[string][]byte) (*results.Result, error) {
+		mu.Lock()
+		overlays = append(overlays, overlay)
+		mu.Unlock()
+		assert.Equal(t, root, dir)
+		text, ok := overlay[app]
+		if !ok {
+			text, _ = os.ReadFile(app)
+		}
+		method := func(name string, line int) results.Finding {
+			return results.Finding{
+				PkgPath: "example.com/app", Interface: "Logger", Method: name, Signature: "(msg string)",
+				Range: results.Range{
+					Start: results.Position{File: logger, Line: line, Column: 2},
+					End:   results.Position{File: logger, Line: line, Column: 2 + len(name)},
+				},
+			}
+		}
+		info := method("Info", 4)
+		info.Verdict = results.VerdictUsed
+		info.Evidence = []results.Evidence{{Kind: results.EvidenceCall, Position: results.Position{File: app, Line: 3}}}
+		debug := method("Debug", 5)
+		debug.Verdict = results.VerdictUnused
+		debug.Fixes = []results.Fix{{
+			Description: "Remove method Debug from the interface",
+			Edits: []results.Edit{{Range: results.Range{
+				Start: results.Position{File: logger, Line: 5, Column: 1},
+				End:   results.Position{File: logger, Line: 6, Column: 1},
+			}}},
+		}}
+		if strings.Contains(string(text), "l.Debug(") {
+			debug.Verdict, debug.Fixes = results.VerdictUsed, nil
+		}
+		return &results.Result{Findings: []results.Finding{info, debug}}, nil
+	}
+
+	c := startServer(t, "", analyze)
+	var init struct {
+		Capabilities struct {
+			HoverProvider bool `json:"hoverProvider"`
+		} `json:"capabilities"`
+	}
+	assert.Nil(t, c.request("initialize", map[string]any{"rootUri": pathToURI(root)}, &init))
+	assert.True(t, init.Capabilities.HoverProvider)
+	c.notify("initialized", struct{}{})
+
+	// Диагностика на имени неиспользуемого метода
+	d := c.diagnostics()
+	assert.Equal(t, pathToURI(logger), d.URI)
+	if assert.Len(t, d.Diagnostics, 1) {
+		assert.Equal(t, lspRange{Start: position{4, 1}, End: position{4, 6}}, d.Diagnostics[0].Range)
+		assert.Equal(t, "unused-method", d.Diagnostics[0].Code)
+		assert.Contains(t, d.Diagnostics[0].Message, "Logger.Debug(msg string) is not used")
+	}
+
+	// Исправление удаляет строку метода
+	var actions []codeAction
+	assert.Nil(t, c.request("textDocument/codeAction", codeActionParams{
+		TextDocument: textDocumentIdentifier{URI: pathToURI(logger)},
+		Range:        lspRange{Start: position{4, 3}, End: position{4, 3}},
+	}, &actions))
+	if assert.Len(t, actions, 1) {
+		assert.Equal(t, "Remove method Debug from the interface", actions[0].Title)
+		assert.Equal(t, []textDocumentEdit{{
+			TextDocument: optionalVersionedTextDocumentIdentifier{URI: pathToURI(logger)},
+			Edits:        []textEdit{{Range: lspRange{Start: position{4, 0}, End: position{5, 0}}}},
+		}}, actions[0].Edit.DocumentChanges)
+	}
+
+	// Подсказка перечисляет места вызовов
+	var h hover
+	assert.Nil(t, c.request("textDocument/hover", positionParams{
+		TextDocument: textDocumentIdentifier{URI: pathToURI(logger)},
+		Position:     position{3, 2},
+	}, &h))
+	assert.Contains(t, h.Contents.Value, "call at `app.go:3`")
+
+	// Несохраненный вызов из редактора делает метод используемым
+	c.notify("textDocument/didOpen", map[string]any{"textDocument": map[string]any{
+		"uri": pathToURI(app), "languageId": "go", "version": 1,
+		"text": "package app\n\nfunc Run(l Logger) { l.Info(\"x\") }\n",
+	}})
+	c.notify("textDocument/didChange", map[string]any{
+		"textDocument": map[string]any{"uri": pathToURI(app), "version": 2},
+		"contentChanges": []map[string]any{{
+			"range": lspRange{Start: position{2, 32}, End: position{2, 32}},
+			"text":  "; l.Debug(\"y\")",
+		}},
+	})
+	for d = c.diagnostics(); len(d.Diagnostics) > 0; d = c.diagnostics() {
+	}
+	mu.Lock()
+	assert.Equal(t, "package app\n\nfunc Run(l Logger) { l.Info(\"x\"); l.Debug(\"y\") }\n", string(overlays[len(overlays)-1][app]))
+	mu.Unlock()
+
+	// Закрытие документа возвращает содержимое с диска
+	c.notify("textDocument/didClose", map[string]any{"textDocument": map[string]any{"uri": pathToURI(app)}})
+	assert.Len(t, c.diagnostics().Diagnostics, 1)
+
+	var unknown any
+	assert.Equal(t, codeMethodNotFound, c.request("textDocument/rename", struct{}{}, &unknown).Code)
+
+	var shutdown any
+	assert.Nil(t, c.request("shutdown", nil, &shutdown))
+	c.notify("exit", nil)
+	select {
+	case err := <-c.done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("сервер не завершился")
+	}
+}
+
+func TestStaleDocument(t *testing.T) {
+	root := t.TempDir()
+	logger := filepath.Join(root, "logger.go")
+	assert.NoError(t, os.WriteFile(logger, []byte(loggerSource), 0644))
+
+	// Анализатор ждет release, пока документ версии 2 не проанализирован
+	release := make(chan struct{})
+	analyze := func(dir string, overlay map[string][]byte) (*results.Result, error) {
+		if strings.Contains(string(overlay[logger]), "// v2") {
+			<-release
+		}
+		debug := results.Finding{
+			PkgPath: "example.com/app", Interface: "Logger", Method: "Debug", Verdict: results.VerdictUnused,
+			Range: results.Range{
+				Start: results.Position{File: logger, Line: 5, Column: 2},
+				End:   results.Position{File: logger, Line: 5, Column: 7},
+			},
+			Fixes: []results.Fix{{Description: "Remove method Debug from the interface", Edits: []results.Edit{{Range: results.Range{
+				Start: results.Position{File: logger, Line: 5, Column: 1},
+				End:   results.Position{File: logger, Line: 6, Column: 1},
+			}}}}},
+		}
+		return &results.Result{Findings: []results.Finding{debug}}, nil
+	}
+
+	c := startServer(t, root, analyze)
+	var init any
+	assert.Nil(t, c.request("initialize", map[string]any{}, &init))
+	c.notify("textDocument/didOpen", map[string]any{"textDocument": map[string]any{
+		"uri": pathToURI(logger), "languageId": "go", "version": 1, "text": loggerSource,
+	}})
+	d := c.diagnostics()
+	if assert.NotNil(t, d.Version) {
+		assert.Equal(t, 1, *d.Version)
+	}
+
+	actions := func() []codeAction {
+		var actions []codeAction
+		assert.Nil(t, c.request("textDocument/codeAction", codeActionParams{
+			TextDocument: textDocumentIdentifier{URI: pathToURI(logger)},
+			Range:        lspRange{Start: position{4, 3}, End: position{4, 3}},
+		}, &actions))
+		return actions
+	}
+	if a := actions(); assert.Len(t, a, 1) && assert.Len(t, a[0].Edit.DocumentChanges, 1) {
+		assert.Equal(t, 1, *a[0].Edit.DocumentChanges[0].TextDocument.Version)
+	}
+
+	// Строка вставлена перед методами: пока анализ не завершен, позиции устарели
+	c.notify("textDocument/didChange", map[string]any{
+		"textDocument":   map[string]any{"uri": pathToURI(logger), "version": 2},
+		"contentChanges": []map[string]any{{"range": lspRange{Start: position{3, 0}, End: position{3, 0}}, "text": "\t// v2\n"}},
+	})
+	assert.Empty(t, actions())
+	var h *hover
+	assert.Nil(t, c.request("textDocument/hover", positionParams{
+		TextDocument: textDocumentIdentifier{URI: pathToURI(logger)},
+		Position:     position{4, 2},
+	}, &h))
+	assert.Nil(t, h)
+
+	close(release)
+	d = c.diagnostics()
+	if assert.NotNil(t, d.Version) {
+		assert.Equal(t, 2, *d.Version)
+	}
+	assert.Len(t, actions(), 1)
+}
+
+func TestPositions(t *testing.T) {
+	line := "\tПривет(msg string) // 😀 x"
+	// Кириллица занимает два байта и одну единицу UTF-16, эмодзи - четыре байта и две
+	assert.Equal(t, 1, utf16Column(line, 2))
+	assert.Equal(t, 7, utf16Column(line, 14))
+	assert.Equal(t, 14, byteColumn(line, 7))
+	assert.Equal(t, utf16Len(line), utf16Column(line, 100))
+
+	text := []byte("package a\n\n" + line + "\n")
+	assert.Equal(t, len("package a\n\n")+13, offsetOf(text, position{2, 7}))
+	assert.Equal(t, len(text), offsetOf(text, position{10, 0}))
+	assert.Equal(t, line, lineOf(text, 3))
+}
@@ -0,0 +1,58 @@
+package postprocess
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/comerc/unused-interface-methods/pkg/config"
+	"github.com/comerc/unused-interface-methods/pkg/results"
+	"github.com/comerc/unused-interface-methods/pkg/rules"
+	"github.com/comerc/unused-interface-methods/pkg/suppress"
+)
+
+// Apply применяет конфигурацию к находкам анализа одинаково для всех движков:
+// отмечает устаревшие директивы, применяет правила, записи exclude и серьезность.
+// Предупреждения об истекших записях exclude пишутся в warn.
+// Возвращает находки, отброшенные правилами, для explain
+func Apply(res *results.Result, cfg *config.Config, directives []results.Suppression, lookup *rules.Lookup, now time.Time, warn io.Writer) ([]results.Finding, error) {
+	res.Stale = suppress.Stale(directives, res.Findings)
+
+	// Правила конфигурации применяются до подавлений: ignore и api убирают находки
+	dropped := rules.Apply(res, cfg, lookup)
+
+	// Записи exclude с истекшим сроком больше не подавляют находки
+	for _, rule := range cfg.ExpiredExcludes(now) {
+		fmt.Fprintf(warn, "Warning: exclude entry %s (%s:%d) expired on %s%s\n",
+			rule, config.GetRelativePath(rule.File), rule.Line, rule.Until, excludeDetails(rule))
+	}
+	res.Stale = append(res.Stale, suppress.ApplyExcludes(res, cfg, now)...)
+
+	// Правила ignore проверяются после анализа, когда известны все просмотренные пути
+	staleRules, err := cfg.StaleIgnoreRules()
+	if err != nil {
+		return nil, err
+	}
+	res.Stale = append(res.Stale, suppress.StaleIgnoreRules(staleRules)...)
+
+	// Серьезность по видам находок; правила warn и error уже задали свою
+	dropped = append(dropped, rules.ApplySeverities(res, cfg)...)
+	res.Sort()
+	return dropped, nil
+}
+
+// excludeDetails описывает владельца и причину записи exclude для предупреждения
+func excludeDetails(rule *config.ExcludeRule) string {
+	var parts []string
+	if rule.Owner != "" {
+		parts = append(parts, "owner: "+rule.Owner)
+	}
+	if rule.Reason != "" {
+		parts = append(parts, "reason: "+rule.Reason)
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(parts, ", ") + ")"
+}
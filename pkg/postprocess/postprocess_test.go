@@ -0,0 +1,74 @@
+package postprocess
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/comerc/unused-interface-methods/pkg/config"
+	"github.com/comerc/unused-interface-methods/pkg/results"
+	"github.com/comerc/unused-interface-methods/pkg/rules"
+)
+
+func TestApply(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), ".unused-interface-methods.yml")
+	assert.NoError(t, os.WriteFile(configPath, []byte(`ignore:
+  - "legacy/**"
+exclude:
+  - interface: Storage
+    method: Compact
+    reason: removed in v2
+    owner: "@storage-team"
+    until: 2020-01-01
+  - interface: Cache
+    method: Set
+severity:
+  unused-method: warning
+`), 0o644))
+	cfg, err := config.LoadConfig(configPath)
+	assert.NoError(t, err)
+
+	used := results.Suppression{Scope: results.ScopeMethod, Directive: "nolint", Position: results.Position{File: "repo.go", Line: 3}}
+	unused := results.Suppression{Scope: results.ScopeMethod, Directive: "nolint", Position: results.Position{File: "repo.go", Line: 9}}
+	res := &results.Result{Findings: []results.Finding{
+		{PkgPath: "app", Interface: "Storage", Method: "Compact", Verdict: results.VerdictUnused},
+		{PkgPath: "app", Interface: "Cache", Method: "Set", Verdict: results.VerdictUnused},
+		{PkgPath: "app", Interface: "Repository", Method: "Save", Verdict: results.VerdictUnused, Suppression: &used},
+	}}
+
+	var warn bytes.Buffer
+	dropped, err := Apply(res, cfg, []results.Suppression{used, unused}, rules.NewLookup(nil), time.Now(), &warn)
+	assert.NoError(t, err)
+	assert.Empty(t, dropped)
+
+	// Истекшая запись exclude не подавляет находку, а только предупреждает
+	assert.Contains(t, warn.String(), "Warning: exclude entry interface=Storage, method=Compact (")
+	assert.Contains(t, warn.String(), ":4) expired on 2020-01-01 (owner: @storage-team, reason: removed in v2)\n")
+
+	findings := make(map[string]results.Finding)
+	for _, f := range res.Findings {
+		findings[f.Interface+"."+f.Method] = f
+	}
+	assert.Nil(t, findings["Storage.Compact"].Suppression)
+	assert.Equal(t, results.SeverityWarning, findings["Storage.Compact"].Level())
+	if assert.NotNil(t, findings["Cache.Set"].Suppression) {
+		assert.Equal(t, results.ScopeConfig, findings["Cache.Set"].Suppression.Scope)
+	}
+
+	// Устаревшими считаются директива без находки и паттерн ignore без совпадений
+	var stale []string
+	for _, s := range res.Stale {
+		stale = append(stale, string(s.Kind)+" "+s.Rule)
+	}
+	assert.ElementsMatch(t, []string{"directive nolint", "ignore-rule legacy/**"}, stale)
+}
+
+func TestExcludeDetails(t *testing.T) {
+	assert.Equal(t, "", excludeDetails(&config.ExcludeRule{}))
+	assert.Equal(t, " (owner: @team)", excludeDetails(&config.ExcludeRule{Owner: "@team"}))
+	assert.Equal(t, " (owner: @team, reason: legacy)", excludeDetails(&config.ExcludeRule{Owner: "@team", Reason: "legacy"}))
+}
@@ -0,0 +1,127 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/comerc/unused-interface-methods/pkg/config"
+	"github.com/comerc/unused-interface-methods/pkg/results"
+)
+
+// checkstyleVersion - версия формата Checkstyle, которую понимают CI-системы
+const checkstyleVersion = "4.3"
+
+// Checkstyle выводит неиспользуемые методы в формате Checkstyle XML
+type Checkstyle struct{}
+
+type checkstyleOutput struct {
+	XMLName xml.Name         `xml:"checkstyle"`
+	Version string           `xml:"version,attr"`
+	Files   []checkstyleFile `xml:"file"`
+}
+
+type checkstyleFile struct {
+	Name   string            `xml:"name,attr"`
+	Errors []checkstyleError `xml:"error"`
+}
+
+type checkstyleError struct {
+	Line     int    `xml:"line,attr"`
+	Column   int    `xml:"column,attr,omitempty"`
+	Severity string `xml:"severity,attr"`
+	Message  string `xml:"message,attr"`
+	Source   string `xml:"source,attr"`
+}
+
+// Report реализует Reporter
+func (Checkstyle) Report(w io.Writer, res *results.Result) error {
+	out := checkstyleOutput{Version: checkstyleVersion}
+	index := make(map[string]int) // имя файла -> индекс в out.Files
+
+	add := func(file string, e checkstyleError) {
+		name := filepath.ToSlash(config.GetRelativePath(file))
+		i, ok := index[name]
+		if !ok {
+			i = len(out.Files)
+			index[name] = i
+			out.Files = append(out.Files, checkstyleFile{Name: name})
+		}
+		out.Files[i].Errors = append(out.Files[i].Errors, e)
+	}
+
+	for _, f := range res.Findings {
+		if !f.IsReported() || f.Range.Start.File == "" {
+			continue
+		}
+		add(f.Range.Start.File, checkstyleError{
+			Line:     f.Range.Start.Line,
+			Column:   f.Range.Start.Column,
+			Severity: string(f.Level()),
+			Message:  fmt.Sprintf("Interface method %s.%s%s is not used", f.Interface, f.Method, f.Signature),
+			Source:   checkstyleSource(f.RuleID()),
+		})
+	}
+
+	for _, warning := range res.GenericWarnings {
+		if warning.Position.File == "" {
+			continue
+		}
+		add(warning.Position.File, checkstyleError{
+			Line:     warning.Position.Line,
+			Column:   warning.Position.Column,
+			Severity: string(warning.Level()),
+			Message: fmt.Sprintf("Generic interface %s%s is not analyzed (%d methods skipped)",
+				warning.Interface, warning.TypeParams, warning.MethodCount),
+			Source: checkstyleSource(results.RuleGenericSkipped),
+		})
+	}
+
+	for _, stale := range res.Stale {
+		if stale.Position.File == "" {
+			continue
+		}
+		add(stale.Position.File, checkstyleError{
+			Line:     stale.Position.Line,
+			Column:   stale.Position.Column,
+			Severity: string(stale.Level()),
+			Message:  staleMessage(stale),
+			Source:   checkstyleSource(results.RuleStale),
+		})
+	}
+
+	for _, loadErr := range res.LoadErrors {
+		if loadErr.Position.File == "" {
+			continue
+		}
+		add(loadErr.Position.File, checkstyleError{
+			Line:     loadErr.Position.Line,
+			Column:   loadErr.Position.Column,
+			Severity: string(loadErr.Level()),
+			Message:  loadErrorMessage(loadErr),
+			Source:   checkstyleSource(results.RuleLoadError),
+		})
+	}
+
+	return writeXML(w, out)
+}
+
+// checkstyleSource возвращает идентификатор источника для правила
+func checkstyleSource(rule results.RuleID) string {
+	return sarifToolName + "." + string(rule)
+}
+
+// writeXML записывает документ с XML-заголовком и отступами
+func writeXML(w io.Writer, v any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
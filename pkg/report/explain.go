@@ -0,0 +1,100 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/comerc/unused-interface-methods/pkg/config"
+	"github.com/comerc/unused-interface-methods/pkg/results"
+)
+
+// Explain выводит, почему метод признан используемым или неиспользуемым: все
+// доказательства, реализации, правила конфигурации и подавления, повлиявшие на вердикт
+func Explain(w io.Writer, f results.Finding) error {
+	fmt.Fprintf(w, "Method: %s\n", formatFinding(f))
+	verdict := fmt.Sprintf("Verdict: %s", f.Verdict)
+	if f.Engine != "" {
+		verdict += fmt.Sprintf(" (engine %s)", f.Engine)
+	}
+	fmt.Fprintln(w, verdict)
+
+	if len(f.Evidence) == 0 {
+		fmt.Fprintln(w, "Evidence: none")
+	} else {
+		fmt.Fprintln(w, "Evidence:")
+	}
+	for _, e := range f.Evidence {
+		fmt.Fprintf(w, "  - %s%s\n", evidenceLabel(e), formatAt(e.Position))
+		if e.Kind == results.EvidenceVerifier {
+			// Первая строка - итог проверки, остальные - вывод верификатора
+			lines := strings.Split(e.Detail, "\n")
+			for _, line := range lines[1:] {
+				fmt.Fprintf(w, "      %s\n", line)
+			}
+		}
+	}
+
+	if len(f.Implementations) > 0 {
+		fmt.Fprintln(w, "Implementations:")
+		for _, impl := range f.Implementations {
+			fmt.Fprintf(w, "  - %s%s\n", impl.Type, formatAt(impl.Position))
+		}
+	}
+
+	if f.Rule != nil {
+		s := fmt.Sprintf("Rule: %s (action %s)%s", f.Rule.Rule, f.Rule.Action, formatPosition(f.Rule.Position))
+		if f.Rule.Reason != "" {
+			s += " - " + f.Rule.Reason
+		}
+		fmt.Fprintln(w, s)
+	}
+	if f.Suppression != nil {
+		fmt.Fprintf(w, "Suppression: %s\n", formatSuppression(f.Suppression))
+	}
+
+	fmt.Fprintf(w, "Reported: %s\n", reportStatus(f))
+	return nil
+}
+
+// evidenceLabel описывает доказательство использования для explain
+func evidenceLabel(e results.Evidence) string {
+	switch e.Kind {
+	case results.EvidenceCall:
+		return "call"
+	case results.EvidenceMethodValue:
+		return "method value"
+	case results.EvidenceField:
+		return "interface escapes into a field or parameter"
+	case results.EvidenceReflection:
+		return "possible reflection call (MethodByName), not counted as a use"
+	case results.EvidenceAPI:
+		return fmt.Sprintf("api rule %q", e.Detail)
+	case results.EvidenceVerifier:
+		return "verifier: " + strings.SplitN(e.Detail, "\n", 2)[0]
+	}
+	return string(e.Kind)
+}
+
+// formatAt форматирует позицию как " at file:line" или пустую строку без файла
+func formatAt(p results.Position) string {
+	if p.File == "" {
+		return ""
+	}
+	return fmt.Sprintf(" at %s:%d", config.GetRelativePath(p.File), p.Line)
+}
+
+// reportStatus сообщает, выводится ли находка в отчете, и если нет - почему
+func reportStatus(f results.Finding) string {
+	switch {
+	case f.Rule != nil && f.Rule.Action == string(config.ActionIgnore):
+		return "no, removed by an ignore rule"
+	case f.Verdict == results.VerdictUsed:
+		return "no, the method is used"
+	case f.Suppression != nil:
+		return "no, suppressed"
+	case f.Level() == results.SeverityOff:
+		return fmt.Sprintf("no, severity of %s is off", f.RuleID())
+	}
+	return fmt.Sprintf("yes, %s as %s", f.RuleID(), f.Level())
+}
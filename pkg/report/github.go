@@ -0,0 +1,148 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/comerc/unused-interface-methods/pkg/config"
+	"github.com/comerc/unused-interface-methods/pkg/results"
+)
+
+// githubStepSummaryEnv - переменная окружения с путем к файлу итогов шага GitHub Actions
+const githubStepSummaryEnv = "GITHUB_STEP_SUMMARY"
+
+// GitHub выводит результаты в виде workflow-команд GitHub Actions,
+// которые отображаются как аннотации в пулл-реквесте
+type GitHub struct{}
+
+// Report реализует Reporter
+func (GitHub) Report(w io.Writer, res *results.Result) error {
+	for _, f := range res.Findings {
+		if !f.IsReported() {
+			continue
+		}
+		_, rule := sarifRuleByID(f.RuleID())
+		rule = withSeverity(rule, f.Level())
+		message := fmt.Sprintf("Interface method %s.%s%s is not used", f.Interface, f.Method, f.Signature)
+		if err := writeGitHubCommand(w, rule, f.Range.Start, message); err != nil {
+			return err
+		}
+	}
+
+	for _, warning := range res.GenericWarnings {
+		_, rule := sarifRuleByID(results.RuleGenericSkipped)
+		rule = withSeverity(rule, warning.Level())
+		message := fmt.Sprintf("Generic interface %s%s is not analyzed (%d methods skipped)",
+			warning.Interface, warning.TypeParams, warning.MethodCount)
+		if err := writeGitHubCommand(w, rule, warning.Position, message); err != nil {
+			return err
+		}
+	}
+
+	for _, stale := range res.Stale {
+		_, rule := sarifRuleByID(results.RuleStale)
+		rule = withSeverity(rule, stale.Level())
+		if err := writeGitHubCommand(w, rule, stale.Position, staleMessage(stale)); err != nil {
+			return err
+		}
+	}
+
+	for _, loadErr := range res.LoadErrors {
+		_, rule := sarifRuleByID(results.RuleLoadError)
+		rule = withSeverity(rule, loadErr.Level())
+		if err := writeGitHubCommand(w, rule, loadErr.Position, loadErrorMessage(loadErr)); err != nil {
+			return err
+		}
+	}
+
+	if path := os.Getenv(githubStepSummaryEnv); path != "" {
+		return writeGitHubStepSummary(path, res)
+	}
+	return nil
+}
+
+// writeGitHubCommand выводит одну аннотацию ::level file=...,line=...::message
+func writeGitHubCommand(w io.Writer, rule sarifRuleInfo, pos results.Position, message string) error {
+	var props []string
+	if pos.File != "" {
+		props = append(props, "file="+escapeGitHubProperty(filepath.ToSlash(config.GetRelativePath(pos.File))))
+		if pos.Line > 0 {
+			props = append(props, fmt.Sprintf("line=%d", pos.Line))
+		}
+		if pos.Column > 0 {
+			props = append(props, fmt.Sprintf("col=%d", pos.Column))
+		}
+	}
+	props = append(props, "title="+escapeGitHubProperty(rule.short))
+
+	_, err := fmt.Fprintf(w, "::%s %s::%s\n", githubLevel(rule.level), strings.Join(props, ","), escapeGitHubData(message))
+	return err
+}
+
+// githubLevel преобразует уровень SARIF в команду GitHub Actions
+func githubLevel(level string) string {
+	switch level {
+	case "error":
+		return "error"
+	case "warning":
+		return "warning"
+	default:
+		return "notice"
+	}
+}
+
+// writeGitHubStepSummary дописывает итоги запуска в Markdown-файл шага
+func writeGitHubStepSummary(path string, res *results.Result) error {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", githubStepSummaryEnv, err)
+	}
+	defer file.Close()
+
+	var b strings.Builder
+	s := res.Summary()
+	fmt.Fprintf(&b, "### %s\n\n", sarifToolName)
+	b.WriteString("| Used | Unused | Total | Skipped generic interfaces |\n")
+	b.WriteString("| ---: | ---: | ---: | ---: |\n")
+	fmt.Fprintf(&b, "| %d | %d | %d | %d (%d methods) |\n", s.Used, s.Unused, s.Total, s.SkippedInterfaces, s.SkippedMethods)
+
+	if s.Unused > 0 {
+		b.WriteString("\n<details><summary>Unused methods</summary>\n\n")
+		for _, f := range res.Findings {
+			if f.IsReported() {
+				fmt.Fprintf(&b, "- `%s`\n", formatFinding(f))
+			}
+		}
+		b.WriteString("\n</details>\n")
+	}
+	if s.Stale > 0 {
+		b.WriteString("\n<details><summary>Stale suppressions</summary>\n\n")
+		for _, stale := range res.Stale {
+			fmt.Fprintf(&b, "- %s (`%s:%d`)\n", staleMessage(stale), config.GetRelativePath(stale.Position.File), stale.Position.Line)
+		}
+		b.WriteString("\n</details>\n")
+	}
+	b.WriteString("\n")
+
+	if _, err := file.WriteString(b.String()); err != nil {
+		return fmt.Errorf("failed to write %s: %w", githubStepSummaryEnv, err)
+	}
+	return file.Close()
+}
+
+// escapeGitHubData экранирует текст сообщения workflow-команды
+func escapeGitHubData(s string) string {
+	s = strings.ReplaceAll(s, "%", "%25")
+	s = strings.ReplaceAll(s, "\r", "%0D")
+	return strings.ReplaceAll(s, "\n", "%0A")
+}
+
+// escapeGitHubProperty экранирует значение свойства workflow-команды
+func escapeGitHubProperty(s string) string {
+	s = escapeGitHubData(s)
+	s = strings.ReplaceAll(s, ":", "%3A")
+	return strings.ReplaceAll(s, ",", "%2C")
+}
@@ -0,0 +1,262 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"path/filepath"
+
+	"github.com/comerc/unused-interface-methods/pkg/config"
+	"github.com/comerc/unused-interface-methods/pkg/results"
+)
+
+// JSONVersion - версия формата JSON-отчета.
+// Увеличивается при любом несовместимом изменении структуры документа
+const JSONVersion = 1
+
+// JSON выводит результаты в виде стабильного версионированного JSON-документа
+type JSON struct{}
+
+// jsonDocument - корень JSON-отчета
+type jsonDocument struct {
+	Version         int                  `json:"version"`
+	Findings        []jsonFinding        `json:"findings"`
+	GenericWarnings []jsonGenericWarning `json:"generic_warnings"`
+	Stale           []jsonStale          `json:"stale_suppressions"`
+	LoadErrors      []jsonLoadError      `json:"load_errors"`
+	Summary         jsonSummary          `json:"summary"`
+	Baseline        *jsonBaseline        `json:"baseline,omitempty"`
+}
+
+type jsonPosition struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+}
+
+type jsonRange struct {
+	Start jsonPosition `json:"start"`
+	End   jsonPosition `json:"end"`
+}
+
+type jsonEvidence struct {
+	Kind     string       `json:"kind"`
+	Position jsonPosition `json:"position"`
+	Detail   string       `json:"detail,omitempty"`
+}
+
+type jsonImplementation struct {
+	Type     string       `json:"type"`
+	Position jsonPosition `json:"position"`
+}
+
+type jsonFinding struct {
+	Package         string               `json:"package"`
+	Interface       string               `json:"interface"`
+	Method          string               `json:"method"`
+	Signature       string               `json:"signature"`
+	Range           jsonRange            `json:"range"`
+	Verdict         string               `json:"verdict"`
+	Engine          string               `json:"engine"`
+	Evidence        []jsonEvidence       `json:"evidence"`
+	Implementations []jsonImplementation `json:"implementations"`
+	Suppression     *jsonSuppression     `json:"suppression,omitempty"`
+	Kind            string               `json:"kind"`
+	Severity        string               `json:"severity"`
+	Rule            *jsonRule            `json:"rule,omitempty"`
+}
+
+type jsonRule struct {
+	Rule     string       `json:"rule"`
+	Action   string       `json:"action"`
+	Reason   string       `json:"reason"`
+	Position jsonPosition `json:"position"`
+}
+
+type jsonSuppression struct {
+	Scope     string       `json:"scope"`
+	Directive string       `json:"directive"`
+	Reason    string       `json:"reason"`
+	Position  jsonPosition `json:"position"`
+}
+
+type jsonGenericWarning struct {
+	Package     string       `json:"package"`
+	Interface   string       `json:"interface"`
+	TypeParams  string       `json:"type_params"`
+	Position    jsonPosition `json:"position"`
+	MethodCount int          `json:"method_count"`
+	Severity    string       `json:"severity"`
+}
+
+// jsonBaseline - сведения о применении baseline-файла
+type jsonBaseline struct {
+	Suppressed int                 `json:"suppressed"`
+	Fixed      []jsonBaselineEntry `json:"fixed"`
+}
+
+type jsonBaselineEntry struct {
+	Package   string `json:"package"`
+	Interface string `json:"interface"`
+	Method    string `json:"method"`
+	Signature string `json:"signature"`
+}
+
+type jsonStale struct {
+	Kind     string       `json:"kind"`
+	Rule     string       `json:"rule"`
+	Position jsonPosition `json:"position"`
+	Severity string       `json:"severity"`
+}
+
+type jsonLoadError struct {
+	Package  string       `json:"package"`
+	Message  string       `json:"message"`
+	Position jsonPosition `json:"position"`
+	Severity string       `json:"severity"`
+}
+
+type jsonSummary struct {
+	Used              int `json:"used"`
+	Unused            int `json:"unused"`
+	Warnings          int `json:"warnings"`
+	Total             int `json:"total"`
+	SkippedInterfaces int `json:"skipped_interfaces"`
+	SkippedMethods    int `json:"skipped_methods"`
+	Suppressed        int `json:"suppressed"`
+	Stale             int `json:"stale_suppressions"`
+	LoadErrors        int `json:"load_errors"`
+}
+
+// Report реализует Reporter
+func (JSON) Report(w io.Writer, res *results.Result) error {
+	doc := jsonDocument{
+		Version:         JSONVersion,
+		Findings:        make([]jsonFinding, 0, len(res.Findings)),
+		GenericWarnings: make([]jsonGenericWarning, 0, len(res.GenericWarnings)),
+		Stale:           make([]jsonStale, 0, len(res.Stale)),
+		LoadErrors:      make([]jsonLoadError, 0, len(res.LoadErrors)),
+	}
+
+	for _, f := range res.Findings {
+		finding := jsonFinding{
+			Package:   f.PkgPath,
+			Interface: f.Interface,
+			Method:    f.Method,
+			Signature: f.Signature,
+			Range: jsonRange{
+				Start: newJSONPosition(f.Range.Start),
+				End:   newJSONPosition(f.Range.End),
+			},
+			Verdict:         string(f.Verdict),
+			Engine:          string(f.Engine),
+			Evidence:        make([]jsonEvidence, 0, len(f.Evidence)),
+			Implementations: make([]jsonImplementation, 0, len(f.Implementations)),
+			Kind:            string(f.RuleID()),
+			Severity:        string(f.Level()),
+		}
+		if f.Suppression != nil {
+			finding.Suppression = &jsonSuppression{
+				Scope:     string(f.Suppression.Scope),
+				Directive: f.Suppression.Directive,
+				Reason:    f.Suppression.Reason,
+				Position:  newJSONPosition(f.Suppression.Position),
+			}
+		}
+		if f.Rule != nil {
+			finding.Rule = &jsonRule{
+				Rule:     f.Rule.Rule,
+				Action:   f.Rule.Action,
+				Reason:   f.Rule.Reason,
+				Position: newJSONPosition(f.Rule.Position),
+			}
+		}
+		for _, e := range f.Evidence {
+			finding.Evidence = append(finding.Evidence, jsonEvidence{
+				Kind:     string(e.Kind),
+				Position: newJSONPosition(e.Position),
+				Detail:   e.Detail,
+			})
+		}
+		for _, impl := range f.Implementations {
+			finding.Implementations = append(finding.Implementations, jsonImplementation{
+				Type:     impl.Type,
+				Position: newJSONPosition(impl.Position),
+			})
+		}
+		doc.Findings = append(doc.Findings, finding)
+	}
+
+	for _, warning := range res.GenericWarnings {
+		doc.GenericWarnings = append(doc.GenericWarnings, jsonGenericWarning{
+			Package:     warning.PkgPath,
+			Interface:   warning.Interface,
+			TypeParams:  warning.TypeParams,
+			Position:    newJSONPosition(warning.Position),
+			MethodCount: warning.MethodCount,
+			Severity:    string(warning.Level()),
+		})
+	}
+
+	for _, stale := range res.Stale {
+		doc.Stale = append(doc.Stale, jsonStale{
+			Kind:     string(stale.Kind),
+			Rule:     stale.Rule,
+			Position: newJSONPosition(stale.Position),
+			Severity: string(stale.Level()),
+		})
+	}
+
+	for _, loadErr := range res.LoadErrors {
+		doc.LoadErrors = append(doc.LoadErrors, jsonLoadError{
+			Package:  loadErr.PkgPath,
+			Message:  loadErr.Message,
+			Position: newJSONPosition(loadErr.Position),
+			Severity: string(loadErr.Level()),
+		})
+	}
+
+	s := res.Summary()
+	doc.Summary = jsonSummary{
+		Used:              s.Used,
+		Unused:            s.Unused,
+		Warnings:          s.Warnings,
+		Total:             s.Total,
+		SkippedInterfaces: s.SkippedInterfaces,
+		SkippedMethods:    s.SkippedMethods,
+		Suppressed:        s.Suppressed,
+		Stale:             s.Stale,
+		LoadErrors:        s.LoadErrors,
+	}
+
+	if len(res.Baselined) > 0 || len(res.FixedBaseline) > 0 {
+		doc.Baseline = &jsonBaseline{
+			Suppressed: len(res.Baselined),
+			Fixed:      make([]jsonBaselineEntry, 0, len(res.FixedBaseline)),
+		}
+		for _, fp := range res.FixedBaseline {
+			doc.Baseline.Fixed = append(doc.Baseline.Fixed, jsonBaselineEntry{
+				Package:   fp.PkgPath,
+				Interface: fp.Interface,
+				Method:    fp.Method,
+				Signature: fp.Signature,
+			})
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(doc)
+}
+
+// newJSONPosition преобразует позицию, делая путь относительным и переносимым
+func newJSONPosition(p results.Position) jsonPosition {
+	file := p.File
+	if file != "" {
+		file = filepath.ToSlash(config.GetRelativePath(file))
+	}
+	return jsonPosition{
+		File:   file,
+		Line:   p.Line,
+		Column: p.Column,
+	}
+}
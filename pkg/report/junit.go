@@ -0,0 +1,225 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/comerc/unused-interface-methods/pkg/config"
+	"github.com/comerc/unused-interface-methods/pkg/results"
+)
+
+// JUnit выводит результаты в формате JUnit XML: пакет - набор тестов, интерфейс - тест,
+// неиспользуемые методы с серьезностью error - провал теста. Находки warning и info
+// выводятся в system-out и тест не проваливают, ошибки загрузки - ошибки тестов
+type JUnit struct{}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Errors   int             `xml:"errors,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr,omitempty"`
+	Line      int           `xml:"line,attr,omitempty"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Error     *junitFailure `xml:"error,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+// junitFailure - провал или ошибка теста
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// junitInterface накапливает методы одного интерфейса
+type junitInterface struct {
+	pkg        string
+	name       string
+	file       string
+	line       int
+	used       []results.Finding
+	unused     []results.Finding // серьезность error
+	warnings   []results.Finding // серьезность warning и info
+	suppressed []results.Finding
+}
+
+// Report реализует Reporter
+func (JUnit) Report(w io.Writer, res *results.Result) error {
+	var order []string
+	ifaces := make(map[string]*junitInterface)
+	for _, f := range res.Findings {
+		key := f.PkgPath + "." + f.Interface
+		iface, ok := ifaces[key]
+		if !ok {
+			iface = &junitInterface{pkg: f.PkgPath, name: f.Interface}
+			ifaces[key] = iface
+			order = append(order, key)
+		}
+		// Положение интерфейса - первый метод с известной позицией
+		if iface.file == "" && f.Range.Start.File != "" {
+			iface.file = f.Range.Start.File
+			iface.line = f.Range.Start.Line
+		}
+		switch {
+		case f.IsReported() && f.Level() == results.SeverityError:
+			iface.unused = append(iface.unused, f)
+		case f.IsReported():
+			iface.warnings = append(iface.warnings, f)
+		case f.Verdict == results.VerdictUnused:
+			iface.suppressed = append(iface.suppressed, f)
+		default:
+			iface.used = append(iface.used, f)
+		}
+	}
+
+	out := junitTestSuites{Name: sarifToolName}
+	suites := make(map[string]int) // пакет -> индекс в out.Suites
+	suite := func(pkg string) *junitTestSuite {
+		i, ok := suites[pkg]
+		if !ok {
+			i = len(out.Suites)
+			suites[pkg] = i
+			out.Suites = append(out.Suites, junitTestSuite{Name: pkg})
+		}
+		return &out.Suites[i]
+	}
+
+	for _, key := range order {
+		iface := ifaces[key]
+		tc := junitTestCase{
+			Name:      iface.name,
+			ClassName: iface.pkg,
+			File:      junitPath(iface.file),
+			Line:      iface.line,
+		}
+		if len(iface.used) > 0 || len(iface.warnings) > 0 || len(iface.suppressed) > 0 {
+			var text strings.Builder
+			for _, f := range iface.warnings {
+				fmt.Fprintf(&text, "%s: %s\n", reportedLabel(f), formatFinding(f))
+			}
+			for _, f := range iface.used {
+				fmt.Fprintf(&text, "OK: %s\n", formatFinding(f))
+			}
+			for _, f := range iface.suppressed {
+				fmt.Fprintf(&text, "%s\n", formatSuppressed(f))
+			}
+			tc.SystemOut = text.String()
+		}
+		if len(iface.unused) > 0 {
+			total := len(iface.used) + len(iface.unused) + len(iface.warnings) + len(iface.suppressed)
+			var text strings.Builder
+			for _, f := range iface.unused {
+				fmt.Fprintf(&text, "UNUSED: %s\n", formatFinding(f))
+			}
+			tc.Failure = &junitFailure{
+				Message: fmt.Sprintf("%d of %d methods are not used", len(iface.unused), total),
+				Type:    string(results.RuleUnusedMethod),
+				Text:    text.String(),
+			}
+		}
+		s := suite(iface.pkg)
+		s.Cases = append(s.Cases, tc)
+	}
+
+	// Дженерик-интерфейсы не анализируются - помечаем их тесты пропущенными
+	for _, warning := range res.GenericWarnings {
+		s := suite(warning.PkgPath)
+		s.Cases = append(s.Cases, junitTestCase{
+			Name:      warning.Interface + warning.TypeParams,
+			ClassName: warning.PkgPath,
+			File:      junitPath(warning.Position.File),
+			Line:      warning.Position.Line,
+			Skipped: &junitSkipped{
+				Message: fmt.Sprintf("generic interface is not analyzed (%d methods skipped)", warning.MethodCount),
+			},
+		})
+	}
+
+	// Устаревшие подавления - отдельный набор; с серьезностью error подавление - проваленный тест
+	for _, stale := range res.Stale {
+		s := suite(string(results.RuleStale))
+		tc := junitTestCase{
+			Name:      stale.Rule,
+			ClassName: junitPath(stale.Position.File),
+			File:      junitPath(stale.Position.File),
+			Line:      stale.Position.Line,
+		}
+		if stale.Level() == results.SeverityError {
+			tc.Failure = &junitFailure{Message: staleMessage(stale), Type: string(results.RuleStale)}
+		} else {
+			tc.SystemOut = fmt.Sprintf("STALE (%s): %s\n", stale.Level(), staleMessage(stale))
+		}
+		s.Cases = append(s.Cases, tc)
+	}
+
+	// Ошибки загрузки - ошибки тестов в отдельном наборе: пакет проверен не полностью
+	for _, loadErr := range res.LoadErrors {
+		s := suite(string(results.RuleLoadError))
+		s.Cases = append(s.Cases, junitTestCase{
+			Name:      loadErr.PkgPath,
+			ClassName: loadErr.PkgPath,
+			File:      junitPath(loadErr.Position.File),
+			Line:      loadErr.Position.Line,
+			Error: &junitFailure{
+				Message: loadErrorMessage(loadErr),
+				Type:    string(results.RuleLoadError),
+			},
+		})
+	}
+
+	for i := range out.Suites {
+		s := &out.Suites[i]
+		s.Tests = len(s.Cases)
+		for _, tc := range s.Cases {
+			if tc.Failure != nil {
+				s.Failures++
+			}
+			if tc.Error != nil {
+				s.Errors++
+			}
+			if tc.Skipped != nil {
+				s.Skipped++
+			}
+		}
+		out.Tests += s.Tests
+		out.Failures += s.Failures
+		out.Errors += s.Errors
+		out.Skipped += s.Skipped
+	}
+
+	return writeXML(w, out)
+}
+
+// junitPath возвращает относительный путь к файлу
+func junitPath(file string) string {
+	if file == "" {
+		return ""
+	}
+	return filepath.ToSlash(config.GetRelativePath(file))
+}
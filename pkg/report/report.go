@@ -0,0 +1,243 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/comerc/unused-interface-methods/pkg/config"
+	"github.com/comerc/unused-interface-methods/pkg/results"
+)
+
+// Reporter выводит результаты анализа в заданном формате
+type Reporter interface {
+	Report(w io.Writer, res *results.Result) error
+}
+
+// Formats - поддерживаемые форматы вывода
+var Formats = []string{"text", "json", "sarif", "checkstyle", "junit", "github"}
+
+// Options содержит общие настройки вывода
+type Options struct {
+	Verbose        bool // выводить используемые методы и статистику
+	ShowSuppressed bool // выводить находки, подавленные директивами
+}
+
+// New возвращает Reporter для указанного формата
+func New(format string, opts Options) (Reporter, error) {
+	switch format {
+	case "", "text":
+		return Text{Verbose: opts.Verbose, ShowSuppressed: opts.ShowSuppressed}, nil
+	case "json":
+		return JSON{}, nil
+	case "sarif":
+		return SARIF{}, nil
+	case "checkstyle":
+		return Checkstyle{}, nil
+	case "junit":
+		return JUnit{}, nil
+	case "github":
+		return GitHub{}, nil
+	default:
+		return nil, fmt.Errorf("unknown format %q (supported: %s)", format, strings.Join(Formats, ", "))
+	}
+}
+
+// Text выводит результаты в человекочитаемом виде
+type Text struct {
+	Verbose        bool
+	ShowSuppressed bool
+}
+
+// Report реализует Reporter
+func (t Text) Report(w io.Writer, res *results.Result) error {
+	for _, f := range res.Findings {
+		switch {
+		case f.IsReported():
+			fmt.Fprintf(w, "%s: %s%s\n", reportedLabel(f), formatFinding(f), formatKind(f))
+		case f.Verdict == results.VerdictUnused:
+			if t.ShowSuppressed {
+				fmt.Fprintf(w, "%s\n", formatSuppressed(f))
+			}
+		case f.Verdict == results.VerdictUsed:
+			if t.Verbose {
+				fmt.Fprintf(w, "OK: %s\n", formatFinding(f))
+			}
+		}
+	}
+
+	for _, stale := range res.Stale {
+		fmt.Fprintf(w, "STALE: %s (%s:%d)\n", staleMessage(stale), config.GetRelativePath(stale.Position.File), stale.Position.Line)
+	}
+
+	for _, loadErr := range res.LoadErrors {
+		fmt.Fprintf(w, "LOAD %s: %s%s\n", strings.ToUpper(string(loadErr.Level())), loadErrorMessage(loadErr), formatPosition(loadErr.Position))
+	}
+
+	for _, fp := range res.FixedBaseline {
+		fmt.Fprintf(w, "FIXED: %s (remove it from the baseline)\n", fp)
+	}
+
+	t.reportGenericWarnings(w, res.GenericWarnings)
+
+	if t.Verbose {
+		s := res.Summary()
+		fmt.Fprintf(w, "\nStats: %d used, %d unused, %d total", s.Used, s.Unused, s.Total)
+		if s.SkippedMethods > 0 {
+			fmt.Fprintf(w, " (%d methods skipped due to generics)", s.SkippedMethods)
+		}
+		if s.Warnings > 0 {
+			fmt.Fprintf(w, ", %d unused as warnings", s.Warnings)
+		}
+		if s.Stale > 0 {
+			fmt.Fprintf(w, ", %d stale suppressions", s.Stale)
+		}
+		if s.LoadErrors > 0 {
+			fmt.Fprintf(w, ", %d load errors", s.LoadErrors)
+		}
+		if s.Suppressed > 0 {
+			fmt.Fprintf(w, ", %d unused suppressed", s.Suppressed)
+		}
+		if s.Baselined > 0 {
+			fmt.Fprintf(w, ", %d unused in baseline", s.Baselined)
+		}
+		fmt.Fprintln(w)
+	}
+
+	return nil
+}
+
+// ReportDelta выводит изменение находок между двумя запусками в режиме наблюдения
+func (t Text) ReportDelta(w io.Writer, d results.Delta) error {
+	if d.Empty() {
+		fmt.Fprintln(w, "No changes in unused methods")
+		return nil
+	}
+	for _, f := range d.Unused {
+		fmt.Fprintf(w, "%s: %s%s\n", reportedLabel(f), formatFinding(f), formatKind(f))
+	}
+	for _, f := range d.Used {
+		if f.Suppression != nil {
+			fmt.Fprintf(w, "%s\n", formatSuppressed(f))
+			continue
+		}
+		fmt.Fprintf(w, "USED: %s%s\n", formatFinding(f), formatEvidence(f.Evidence))
+	}
+	for _, f := range d.Removed {
+		fmt.Fprintf(w, "REMOVED: %s\n", formatFinding(f))
+	}
+	return nil
+}
+
+// reportGenericWarnings выводит предупреждения о дженериках
+func (t Text) reportGenericWarnings(w io.Writer, warnings []results.GenericWarning) {
+	if len(warnings) == 0 {
+		return
+	}
+
+	if !t.Verbose {
+		// Краткий режим - только общая статистика
+		totalSkipped := 0
+		for _, warning := range warnings {
+			totalSkipped += warning.MethodCount
+		}
+
+		if totalSkipped > 0 {
+			suffix := "s"
+			if len(warnings) == 1 {
+				suffix = ""
+			}
+			fmt.Fprintf(w, "\n⚠️  %d generic interface%s skipped (%d methods not analyzed)\n",
+				len(warnings), suffix, totalSkipped)
+		}
+		return
+	}
+
+	// Подробный режим - детальные предупреждения
+	fmt.Fprintln(w, "\n⚠️  Generic Interface Warnings:")
+	for _, warning := range warnings {
+		fmt.Fprintf(w, "  - '%s%s' at %s:%d (%d methods skipped)\n",
+			warning.Interface, warning.TypeParams, config.GetRelativePath(warning.Position.File),
+			warning.Position.Line, warning.MethodCount)
+	}
+}
+
+// formatFinding форматирует метод как pkg.Interface.Method(sig) (file:line)
+func formatFinding(f results.Finding) string {
+	s := f.ID() + f.Signature
+	if f.Range.Start.File != "" {
+		s += fmt.Sprintf(" (%s:%d)", config.GetRelativePath(f.Range.Start.File), f.Range.Start.Line)
+	}
+	return s
+}
+
+// formatKind описывает вид находки как " [test-only]"; неиспользуемый метод - вид по умолчанию
+func formatKind(f results.Finding) string {
+	if f.RuleID() == results.RuleUnusedMethod {
+		return ""
+	}
+	return " [" + string(f.RuleID()) + "]"
+}
+
+// reportedLabel возвращает метку выводимой находки по ее серьезности
+func reportedLabel(f results.Finding) string {
+	switch {
+	case f.IsWarning():
+		return "WARNING"
+	case f.Level() == results.SeverityInfo:
+		return "INFO"
+	}
+	return "UNUSED"
+}
+
+// formatEvidence описывает первое доказательство использования как " by call at file:line"
+func formatEvidence(evidence []results.Evidence) string {
+	if len(evidence) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" by %s%s", evidence[0].Kind, formatAt(evidence[0].Position))
+}
+
+// formatSuppressed форматирует подавленный метод вместе с директивой и причиной
+func formatSuppressed(f results.Finding) string {
+	return fmt.Sprintf("SUPPRESSED: %s by %s", formatFinding(f), formatSuppression(f.Suppression))
+}
+
+// formatSuppression описывает подавление как "//директива" или "exclude entry ..." с причиной
+func formatSuppression(s *results.Suppression) string {
+	text := "//" + s.Directive
+	if s.Scope == results.ScopeConfig {
+		text = "exclude entry " + s.Directive
+	}
+	if s.Reason != "" {
+		text += " - " + s.Reason
+	}
+	return text
+}
+
+// staleMessage описывает подавление, которое ничего не подавило
+func staleMessage(s results.StaleSuppression) string {
+	switch s.Kind {
+	case results.StaleIgnoreRule:
+		return fmt.Sprintf("Ignore rule %q matches no files", s.Rule)
+	case results.StaleExcludeRule:
+		return fmt.Sprintf("Exclude entry %s matches no unused methods", s.Rule)
+	}
+	return fmt.Sprintf("Directive //%s suppresses nothing", s.Rule)
+}
+
+// formatPosition форматирует позицию как " (file:line)" или пустую строку без файла
+func formatPosition(p results.Position) string {
+	if p.File == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (%s:%d)", config.GetRelativePath(p.File), p.Line)
+}
+
+// loadErrorMessage описывает ошибку загрузки пакета
+func loadErrorMessage(e results.LoadError) string {
+	if e.PkgPath == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("Package %s: %s", e.PkgPath, e.Message)
+}
@@ -0,0 +1,625 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/comerc/unused-interface-methods/pkg/results"
+)
+
+func TestText(t *testing.T) {
+	res := &results.Result{
+		Findings: []results.Finding{
+			{
+				PkgPath:   "example.com/app",
+				Interface: "Logger",
+				Method:    "Log",
+				Signature: "(msg string)",
+				Verdict:   results.VerdictUsed,
+			},
+			{
+				PkgPath:   "example.com/app",
+				Interface: "Logger",
+				Method:    "Debug",
+				Signature: "(args ...string)",
+				Range:     results.Range{Start: results.Position{File: "logger.go", Line: 7}},
+				Verdict:   results.VerdictUnused,
+			},
+		},
+		GenericWarnings: []results.GenericWarning{
+			{Interface: "Repository", TypeParams: "[T any]", MethodCount: 2},
+		},
+	}
+
+	t.Run("non-verbose", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, Text{}.Report(&buf, res))
+		output := buf.String()
+		assert.Contains(t, output, "UNUSED: example.com/app.Logger.Debug(args ...string) (logger.go:7)\n")
+		assert.NotContains(t, output, "OK:")
+		assert.Contains(t, output, "1 generic interface skipped (2 methods not analyzed)")
+		assert.NotContains(t, output, "Stats:")
+	})
+
+	t.Run("verbose", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, Text{Verbose: true}.Report(&buf, res))
+		output := buf.String()
+		assert.Contains(t, output, "OK: example.com/app.Logger.Log(msg string)\n")
+		assert.Contains(t, output, "Generic Interface Warnings:")
+		assert.Contains(t, output, "Stats: 1 used, 1 unused, 2 total (2 methods skipped due to generics)")
+	})
+
+	t.Run("suppressed", func(t *testing.T) {
+		res := *res
+		res.Findings = append([]results.Finding{}, res.Findings...)
+		res.Findings[1].Suppression = &results.Suppression{
+			Scope:     results.ScopeMethod,
+			Directive: "unused-interface-methods:ignore",
+			Reason:    "used by plugins",
+		}
+
+		var buf bytes.Buffer
+		assert.NoError(t, Text{}.Report(&buf, &res))
+		assert.NotContains(t, buf.String(), "Logger.Debug")
+
+		buf.Reset()
+		assert.NoError(t, Text{ShowSuppressed: true}.Report(&buf, &res))
+		assert.Contains(t, buf.String(),
+			"SUPPRESSED: example.com/app.Logger.Debug(args ...string) (logger.go:7) by //unused-interface-methods:ignore - used by plugins\n")
+		assert.NotContains(t, buf.String(), "UNUSED:")
+
+		res.Findings[1].Suppression = &results.Suppression{
+			Scope:     results.ScopeConfig,
+			Directive: "interface=Logger",
+			Reason:    "legacy logger (owner: @platform)",
+		}
+		buf.Reset()
+		assert.NoError(t, Text{ShowSuppressed: true}.Report(&buf, &res))
+		assert.Contains(t, buf.String(),
+			"SUPPRESSED: example.com/app.Logger.Debug(args ...string) (logger.go:7) by exclude entry interface=Logger - legacy logger (owner: @platform)\n")
+	})
+
+	t.Run("warning", func(t *testing.T) {
+		res := *res
+		res.Findings = append([]results.Finding{}, res.Findings...)
+		res.Findings[1].Severity = results.SeverityWarning
+
+		var buf bytes.Buffer
+		assert.NoError(t, Text{Verbose: true}.Report(&buf, &res))
+		output := buf.String()
+		assert.Contains(t, output, "WARNING: example.com/app.Logger.Debug(args ...string) (logger.go:7)\n")
+		assert.NotContains(t, output, "UNUSED:")
+		assert.Contains(t, output, ", 1 unused as warnings")
+	})
+
+	t.Run("stale", func(t *testing.T) {
+		res := *res
+		res.Stale = []results.StaleSuppression{
+			{Kind: results.StaleDirective, Rule: "nolint:unusedinterfacemethods", Position: results.Position{File: "logger.go", Line: 9}},
+			{Kind: results.StaleIgnoreRule, Rule: "vendor/**", Position: results.Position{File: ".unused-interface-methods.yml", Line: 3}},
+			{Kind: results.StaleExcludeRule, Rule: "method=Close", Position: results.Position{File: ".unused-interface-methods.yml", Line: 6}},
+		}
+
+		var buf bytes.Buffer
+		assert.NoError(t, Text{Verbose: true}.Report(&buf, &res))
+		output := buf.String()
+		assert.Contains(t, output, "STALE: Directive //nolint:unusedinterfacemethods suppresses nothing (logger.go:9)\n")
+		assert.Contains(t, output, "STALE: Ignore rule \"vendor/**\" matches no files (.unused-interface-methods.yml:3)\n")
+		assert.Contains(t, output, "STALE: Exclude entry method=Close matches no unused methods (.unused-interface-methods.yml:6)\n")
+		assert.Contains(t, output, ", 3 stale suppressions")
+	})
+
+	t.Run("baseline", func(t *testing.T) {
+		res := *res
+		res.Baselined = []results.Finding{{Interface: "Logger", Method: "Trace", Verdict: results.VerdictUnused}}
+		res.FixedBaseline = []results.Fingerprint{{PkgPath: "example.com/app", Interface: "Logger", Method: "Warn", Signature: "()"}}
+
+		var buf bytes.Buffer
+		assert.NoError(t, Text{Verbose: true}.Report(&buf, &res))
+		output := buf.String()
+		assert.Contains(t, output, "FIXED: example.com/app.Logger.Warn() (remove it from the baseline)\n")
+		assert.Contains(t, output, ", 1 unused in baseline")
+	})
+}
+
+func TestTextDelta(t *testing.T) {
+	d := results.Delta{
+		Unused: []results.Finding{{PkgPath: "example.com/app", Interface: "Logger", Method: "Debug", Signature: "()", Verdict: results.VerdictUnused}},
+		Used: []results.Finding{{
+			PkgPath: "example.com/app", Interface: "Logger", Method: "Log", Signature: "(msg string)", Verdict: results.VerdictUsed,
+			Evidence: []results.Evidence{{Kind: results.EvidenceCall, Position: results.Position{File: "main.go", Line: 9}}},
+		}},
+		Removed: []results.Finding{{PkgPath: "example.com/app", Interface: "Logger", Method: "Trace", Signature: "()"}},
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, Text{}.ReportDelta(&buf, d))
+	assert.Equal(t, "UNUSED: example.com/app.Logger.Debug()\n"+
+		"USED: example.com/app.Logger.Log(msg string) by call at main.go:9\n"+
+		"REMOVED: example.com/app.Logger.Trace()\n", buf.String())
+
+	buf.Reset()
+	assert.NoError(t, Text{}.ReportDelta(&buf, results.Delta{}))
+	assert.Equal(t, "No changes in unused methods\n", buf.String())
+}
+
+func TestExplain(t *testing.T) {
+	f := results.Finding{
+		PkgPath: "example.com/app", Interface: "Plugin", Method: "Start", Signature: "()",
+		Range:   results.Range{Start: results.Position{File: "plugin.go", Line: 5}},
+		Verdict: results.VerdictUsed,
+		Engine:  results.EngineLinter,
+		Evidence: []results.Evidence{
+			{Kind: results.EvidenceCall, Position: results.Position{File: "main.go", Line: 9}},
+			{Kind: results.EvidenceReflection, Position: results.Position{File: "run.go", Line: 14}},
+			{Kind: results.EvidenceVerifier, Detail: "build failed without the method:\nrun.go:3:2: p.Start undefined (compile)"},
+		},
+		Implementations: []results.Implementation{{Type: "*example.com/app.server", Position: results.Position{File: "server.go", Line: 20}}},
+		Rule:            &results.RuleMatch{Rule: "plugins", Action: "warn", Reason: "legacy", Position: results.Position{File: "lint.yml", Line: 3}},
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, Explain(&buf, f))
+	assert.Equal(t, "Method: example.com/app.Plugin.Start() (plugin.go:5)\n"+
+		"Verdict: used (engine linter)\n"+
+		"Evidence:\n"+
+		"  - call at main.go:9\n"+
+		"  - possible reflection call (MethodByName), not counted as a use at run.go:14\n"+
+		"  - verifier: build failed without the method:\n"+
+		"      run.go:3:2: p.Start undefined (compile)\n"+
+		"Implementations:\n"+
+		"  - *example.com/app.server at server.go:20\n"+
+		"Rule: plugins (action warn) (lint.yml:3) - legacy\n"+
+		"Reported: no, the method is used\n", buf.String())
+
+	// Неиспользуемый метод, убранный правилом ignore, и подавленный метод
+	f = results.Finding{PkgPath: "example.com/app", Interface: "Plugin", Method: "Stop", Verdict: results.VerdictUnused,
+		Rule: &results.RuleMatch{Rule: "name=Stop", Action: "ignore"}}
+	buf.Reset()
+	assert.NoError(t, Explain(&buf, f))
+	assert.Contains(t, buf.String(), "Evidence: none\n")
+	assert.Contains(t, buf.String(), "Reported: no, removed by an ignore rule\n")
+
+	f.Rule = nil
+	f.Suppression = &results.Suppression{Scope: results.ScopeMethod, Directive: "nolint:unusedinterfacemethods", Reason: "plugin API"}
+	buf.Reset()
+	assert.NoError(t, Explain(&buf, f))
+	assert.Contains(t, buf.String(), "Suppression: //nolint:unusedinterfacemethods - plugin API\n")
+	assert.Contains(t, buf.String(), "Reported: no, suppressed\n")
+
+	f.Suppression = nil
+	buf.Reset()
+	assert.NoError(t, Explain(&buf, f))
+	assert.Contains(t, buf.String(), "Reported: yes, unused-method as error\n")
+}
+
+func TestNew(t *testing.T) {
+	reporter, err := New("json", Options{})
+	assert.NoError(t, err)
+	assert.IsType(t, JSON{}, reporter)
+
+	reporter, err = New("", Options{Verbose: true})
+	assert.NoError(t, err)
+	assert.Equal(t, Text{Verbose: true}, reporter)
+
+	reporter, err = New("junit", Options{})
+	assert.NoError(t, err)
+	assert.IsType(t, JUnit{}, reporter)
+
+	_, err = New("xml", Options{})
+	assert.Error(t, err)
+}
+
+func TestJSON(t *testing.T) {
+	res := &results.Result{
+		Findings: []results.Finding{
+			{
+				PkgPath:   "example.com/app",
+				Interface: "Logger",
+				Method:    "Log",
+				Signature: "(msg string)",
+				Range: results.Range{
+					Start: results.Position{File: "logger.go", Line: 6, Column: 2},
+					End:   results.Position{File: "logger.go", Line: 6, Column: 5},
+				},
+				Verdict: results.VerdictUsed,
+				Engine:  results.EngineLinter,
+				Evidence: []results.Evidence{
+					{Kind: results.EvidenceCall, Position: results.Position{File: "main.go", Line: 12, Column: 3}},
+				},
+			},
+			{
+				PkgPath:   "example.com/app",
+				Interface: "Logger",
+				Method:    "Debug",
+				Verdict:   results.VerdictUnused,
+				Engine:    results.EngineLinter,
+			},
+		},
+		GenericWarnings: []results.GenericWarning{
+			{PkgPath: "example.com/app", Interface: "Repository", TypeParams: "[T any]", MethodCount: 2},
+		},
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, JSON{}.Report(&buf, res))
+
+	var doc map[string]interface{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+	assert.EqualValues(t, JSONVersion, doc["version"])
+
+	findings := doc["findings"].([]interface{})
+	assert.Len(t, findings, 2)
+	first := findings[0].(map[string]interface{})
+	assert.Equal(t, "example.com/app", first["package"])
+	assert.Equal(t, "(msg string)", first["signature"])
+	assert.Equal(t, "used", first["verdict"])
+	assert.Equal(t, "linter", first["engine"])
+	assert.Len(t, first["evidence"], 1)
+
+	// Пустые списки выводятся как [], а не null
+	second := findings[1].(map[string]interface{})
+	assert.Equal(t, []interface{}{}, second["evidence"])
+
+	// Серьезность выводится всегда: без конфигурации - по умолчанию для вида
+	assert.Equal(t, "error", second["severity"])
+	assert.Equal(t, "unused-method", second["kind"])
+	if assert.Len(t, doc["generic_warnings"], 1) {
+		assert.Equal(t, "info", doc["generic_warnings"].([]interface{})[0].(map[string]interface{})["severity"])
+	}
+	assert.Equal(t, map[string]interface{}{
+		"used":               1.0,
+		"unused":             1.0,
+		"warnings":           0.0,
+		"total":              2.0,
+		"skipped_interfaces": 1.0,
+		"skipped_methods":    2.0,
+		"suppressed":         0.0,
+		"stale_suppressions": 0.0,
+		"load_errors":        0.0,
+	}, doc["summary"])
+	assert.Equal(t, []interface{}{}, doc["stale_suppressions"])
+	assert.Equal(t, []interface{}{}, doc["load_errors"])
+
+	assert.NotContains(t, first, "suppression")
+
+	// Без baseline блок не выводится
+	assert.NotContains(t, doc, "baseline")
+
+	res.FixedBaseline = []results.Fingerprint{{PkgPath: "example.com/app", Interface: "Logger", Method: "Warn", Signature: "()"}}
+	buf.Reset()
+	assert.NoError(t, JSON{}.Report(&buf, res))
+	doc = nil
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+	assert.Equal(t, map[string]interface{}{
+		"suppressed": 0.0,
+		"fixed": []interface{}{map[string]interface{}{
+			"package":   "example.com/app",
+			"interface": "Logger",
+			"method":    "Warn",
+			"signature": "()",
+		}},
+	}, doc["baseline"])
+	// Подавленная находка остается в findings вместе с причиной
+	res.Findings[1].Suppression = &results.Suppression{
+		Scope:     results.ScopeInterface,
+		Directive: "unused-interface-methods:ignore",
+		Reason:    "public API",
+		Position:  results.Position{File: "logger.go", Line: 4, Column: 1},
+	}
+	buf.Reset()
+	assert.NoError(t, JSON{}.Report(&buf, res))
+	doc = nil
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+	second = doc["findings"].([]interface{})[1].(map[string]interface{})
+	assert.Equal(t, "unused", second["verdict"])
+	assert.Equal(t, map[string]interface{}{
+		"scope":     "interface",
+		"directive": "unused-interface-methods:ignore",
+		"reason":    "public API",
+		"position":  map[string]interface{}{"file": "logger.go", "line": 4.0, "column": 1.0},
+	}, second["suppression"])
+	summary := doc["summary"].(map[string]interface{})
+	assert.Equal(t, 0.0, summary["unused"])
+	assert.Equal(t, 1.0, summary["suppressed"])
+}
+
+func TestSARIF(t *testing.T) {
+	res := &results.Result{
+		Findings: []results.Finding{
+			{
+				PkgPath:   "example.com/app",
+				Interface: "Logger",
+				Method:    "Log",
+				Verdict:   results.VerdictUsed,
+			},
+			{
+				PkgPath:   "example.com/app",
+				Interface: "Logger",
+				Method:    "Debug",
+				Signature: "(args ...string)",
+				Range: results.Range{
+					Start: results.Position{File: "logger.go", Line: 7, Column: 2},
+					End:   results.Position{File: "logger.go", Line: 7, Column: 7},
+				},
+				Verdict: results.VerdictUnused,
+				Implementations: []results.Implementation{
+					{Type: "*example.com/app.fileLogger", Position: results.Position{File: "file.go", Line: 20, Column: 22}},
+				},
+				Fixes: []results.Fix{{
+					Description: "Remove method Debug from the interface",
+					Edits: []results.Edit{{
+						Range: results.Range{
+							Start: results.Position{File: "logger.go", Line: 7, Column: 1},
+							End:   results.Position{File: "logger.go", Line: 8, Column: 1},
+						},
+					}},
+				}},
+			},
+			{
+				PkgPath:   "example.com/app",
+				Interface: "Logger",
+				Method:    "Trace",
+				Signature: "()",
+				Range:     results.Range{Start: results.Position{File: "logger.go", Line: 9, Column: 2}},
+				Verdict:   results.VerdictUnused,
+				Suppression: &results.Suppression{
+					Scope:     results.ScopeMethod,
+					Directive: "nolint:unusedinterfacemethods",
+					Reason:    "public API",
+					Position:  results.Position{File: "logger.go", Line: 9, Column: 10},
+				},
+			},
+		},
+		GenericWarnings: []results.GenericWarning{
+			{
+				PkgPath:     "example.com/app",
+				Interface:   "Repository",
+				TypeParams:  "[T any]",
+				Position:    results.Position{File: "repo.go", Line: 3},
+				MethodCount: 2,
+			},
+		},
+		Stale: []results.StaleSuppression{
+			{Kind: results.StaleDirective, Rule: "unused-interface-methods:ignore", Position: results.Position{File: "logger.go", Line: 12, Column: 2}},
+		},
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, SARIF{}.Report(&buf, res))
+
+	// Проверяем соответствие схеме SARIF 2.1.0
+	schemaFile, err := os.Open("testdata/sarif-schema-2.1.0.json")
+	assert.NoError(t, err)
+	defer schemaFile.Close()
+	schemaDoc, err := jsonschema.UnmarshalJSON(schemaFile)
+	assert.NoError(t, err)
+	compiler := jsonschema.NewCompiler()
+	assert.NoError(t, compiler.AddResource(sarifSchemaURI, schemaDoc))
+	schema, err := compiler.Compile(sarifSchemaURI)
+	assert.NoError(t, err)
+
+	instance, err := jsonschema.UnmarshalJSON(bytes.NewReader(buf.Bytes()))
+	assert.NoError(t, err)
+	assert.NoError(t, schema.Validate(instance))
+
+	var log sarifLog
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &log))
+	run := log.Runs[0]
+	assert.Len(t, run.Tool.Driver.Rules, len(sarifRules))
+	for _, rule := range sarifRules {
+		assert.Equal(t, withSeverity(rule, results.DefaultSeverity(rule.id)).level, rule.level, "уровень %s совпадает с серьезностью по умолчанию", rule.id)
+	}
+
+	// Используемые методы в отчет не попадают, подавленные помечаются suppressions
+	assert.Len(t, run.Results, 4)
+	unused := run.Results[0]
+	assert.Equal(t, "unused-method", unused.RuleID)
+	assert.Equal(t, "error", unused.Level, "серьезность по умолчанию проваливает запуск")
+	assert.Equal(t, "logger.go", unused.Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	assert.Equal(t, 7, unused.Locations[0].PhysicalLocation.Region.StartLine)
+	assert.Len(t, unused.RelatedLocations, 1)
+	assert.Len(t, unused.Fixes, 1)
+	assert.Equal(t, 8, unused.Fixes[0].ArtifactChanges[0].Replacements[0].DeletedRegion.EndLine)
+
+	assert.Empty(t, unused.Suppressions)
+
+	suppressed := run.Results[1]
+	if assert.Len(t, suppressed.Suppressions, 1) {
+		assert.Equal(t, "inSource", suppressed.Suppressions[0].Kind)
+		assert.Equal(t, "public API", suppressed.Suppressions[0].Justification)
+		assert.Equal(t, 9, suppressed.Suppressions[0].Location.PhysicalLocation.Region.StartLine)
+	}
+
+	generic := run.Results[2]
+	assert.Equal(t, "generic-skipped", generic.RuleID)
+	assert.Equal(t, "note", generic.Level)
+
+	stale := run.Results[3]
+	assert.Equal(t, "stale-suppression", stale.RuleID)
+	assert.Equal(t, "Directive //unused-interface-methods:ignore suppresses nothing", stale.Message.Text)
+	assert.Equal(t, 12, stale.Locations[0].PhysicalLocation.Region.StartLine)
+	assert.Equal(t, "warning", stale.Level)
+}
+
+// xmlTestResult - общий набор результатов для XML-форматов
+func xmlTestResult() *results.Result {
+	return &results.Result{
+		Findings: []results.Finding{
+			{
+				PkgPath:   "example.com/app",
+				Interface: "Logger",
+				Method:    "Log",
+				Signature: "(msg string)",
+				Range:     results.Range{Start: results.Position{File: "logger.go", Line: 6, Column: 2}},
+				Verdict:   results.VerdictUsed,
+			},
+			{
+				PkgPath:   "example.com/app",
+				Interface: "Logger",
+				Method:    "Debug",
+				Signature: "(args ...string)",
+				Range:     results.Range{Start: results.Position{File: "logger.go", Line: 7, Column: 2}},
+				Verdict:   results.VerdictUnused,
+			},
+			{
+				PkgPath:   "example.com/app",
+				Interface: "Closer",
+				Method:    "Close",
+				Signature: "() error",
+				Range:     results.Range{Start: results.Position{File: "closer.go", Line: 4, Column: 2}},
+				Verdict:   results.VerdictUsed,
+			},
+		},
+		GenericWarnings: []results.GenericWarning{
+			{
+				PkgPath:     "example.com/app",
+				Interface:   "Repository",
+				TypeParams:  "[T any]",
+				Position:    results.Position{File: "repo.go", Line: 3},
+				MethodCount: 2,
+			},
+		},
+	}
+}
+
+func TestCheckstyle(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, Checkstyle{}.Report(&buf, xmlTestResult()))
+	assert.True(t, strings.HasPrefix(buf.String(), xml.Header))
+
+	var out checkstyleOutput
+	assert.NoError(t, xml.Unmarshal(buf.Bytes(), &out))
+	assert.Len(t, out.Files, 2)
+
+	logger := out.Files[0]
+	assert.Equal(t, "logger.go", logger.Name)
+	assert.Equal(t, []checkstyleError{{
+		Line:     7,
+		Column:   2,
+		Severity: "error",
+		Message:  "Interface method Logger.Debug(args ...string) is not used",
+		Source:   "unused-interface-methods.unused-method",
+	}}, logger.Errors)
+
+	repo := out.Files[1]
+	assert.Equal(t, "repo.go", repo.Name)
+	assert.Equal(t, "info", repo.Errors[0].Severity)
+}
+
+func TestJUnit(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, JUnit{}.Report(&buf, xmlTestResult()))
+
+	var out junitTestSuites
+	assert.NoError(t, xml.Unmarshal(buf.Bytes(), &out))
+	assert.Equal(t, 3, out.Tests)
+	assert.Equal(t, 1, out.Failures)
+	assert.Equal(t, 1, out.Skipped)
+	assert.Len(t, out.Suites, 1)
+
+	suite := out.Suites[0]
+	assert.Equal(t, "example.com/app", suite.Name)
+	assert.Len(t, suite.Cases, 3)
+
+	// Интерфейс с неиспользуемым методом - провал со списком методов
+	logger := suite.Cases[0]
+	assert.Equal(t, "Logger", logger.Name)
+	assert.Equal(t, "logger.go", logger.File)
+	assert.Equal(t, 6, logger.Line)
+	if assert.NotNil(t, logger.Failure) {
+		assert.Equal(t, "1 of 2 methods are not used", logger.Failure.Message)
+		assert.Equal(t, "UNUSED: example.com/app.Logger.Debug(args ...string) (logger.go:7)\n", logger.Failure.Text)
+	}
+
+	// Все методы используются - тест пройден
+	closer := suite.Cases[1]
+	assert.Equal(t, "Closer", closer.Name)
+	assert.Nil(t, closer.Failure)
+	assert.Nil(t, closer.Skipped)
+
+	repo := suite.Cases[2]
+	assert.Equal(t, "Repository[T any]", repo.Name)
+	assert.NotNil(t, repo.Skipped)
+}
+
+func TestJUnitSeverities(t *testing.T) {
+	res := xmlTestResult()
+	res.Findings[1].Severity = results.SeverityWarning
+	res.Stale = []results.StaleSuppression{
+		{Kind: results.StaleDirective, Rule: "unused:ignore", Position: results.Position{File: "closer.go", Line: 3}},
+		{Kind: results.StaleDirective, Rule: "unused:ignore", Position: results.Position{File: "closer.go", Line: 9}, Severity: results.SeverityError},
+	}
+	res.LoadErrors = []results.LoadError{{PkgPath: "example.com/broken", Message: "undefined: x", Position: results.Position{File: "broken.go", Line: 2}}}
+
+	var buf bytes.Buffer
+	assert.NoError(t, JUnit{}.Report(&buf, res))
+	var out junitTestSuites
+	assert.NoError(t, xml.Unmarshal(buf.Bytes(), &out))
+	assert.Equal(t, 1, out.Failures, "только подавление с серьезностью error")
+	assert.Equal(t, 1, out.Errors)
+	if !assert.Len(t, out.Suites, 3) {
+		return
+	}
+
+	// Предупреждение не проваливает тест интерфейса
+	logger := out.Suites[0].Cases[0]
+	assert.Nil(t, logger.Failure)
+	assert.Contains(t, logger.SystemOut, "WARNING: example.com/app.Logger.Debug(args ...string) (logger.go:7)")
+
+	stale := out.Suites[1].Cases
+	if assert.Len(t, stale, 2) {
+		assert.Nil(t, stale[0].Failure)
+		assert.Equal(t, "STALE (warning): Directive //unused:ignore suppresses nothing\n", stale[0].SystemOut)
+		assert.NotNil(t, stale[1].Failure)
+	}
+
+	loadErrors := out.Suites[2]
+	assert.Equal(t, "load-error", loadErrors.Name)
+	if assert.Len(t, loadErrors.Cases, 1) && assert.NotNil(t, loadErrors.Cases[0].Error) {
+		assert.Equal(t, "Package example.com/broken: undefined: x", loadErrors.Cases[0].Error.Message)
+		assert.Equal(t, "broken.go", loadErrors.Cases[0].File)
+	}
+}
+
+func TestGitHub(t *testing.T) {
+	summary := filepath.Join(t.TempDir(), "summary.md")
+	t.Setenv("GITHUB_STEP_SUMMARY", summary)
+
+	res := xmlTestResult()
+	res.Findings[1].Signature = "(format string, args ...any)"
+
+	var buf bytes.Buffer
+	assert.NoError(t, GitHub{}.Report(&buf, res))
+	assert.Equal(t,
+		"::error file=logger.go,line=7,col=2,title=Unused interface method::Interface method Logger.Debug(format string, args ...any) is not used\n"+
+			"::notice file=repo.go,line=3,title=Generic interface not analyzed::Generic interface Repository[T any] is not analyzed (2 methods skipped)\n",
+		buf.String())
+
+	data, err := os.ReadFile(summary)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "| 2 | 1 | 3 | 1 (2 methods) |")
+	assert.Contains(t, string(data), "- `example.com/app.Logger.Debug(format string, args ...any) (logger.go:7)`")
+
+	// Без переменной окружения файл итогов не пишется
+	t.Setenv("GITHUB_STEP_SUMMARY", "")
+	buf.Reset()
+	assert.NoError(t, GitHub{}.Report(&buf, res))
+	after, err := os.ReadFile(summary)
+	assert.NoError(t, err)
+	assert.Equal(t, data, after)
+}
+
+func TestEscapeGitHub(t *testing.T) {
+	assert.Equal(t, "100%25%0Adone", escapeGitHubData("100%\ndone"))
+	assert.Equal(t, "C%3A/dir%2Cfile", escapeGitHubProperty("C:/dir,file"))
+}
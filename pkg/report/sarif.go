@@ -0,0 +1,452 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/comerc/unused-interface-methods/pkg/config"
+	"github.com/comerc/unused-interface-methods/pkg/results"
+)
+
+const (
+	sarifVersion   = "2.1.0"
+	sarifSchemaURI = "https://docs.oasis-open.org/sarif/sarif/v2.1.0/errata01/os/schemas/sarif-schema-2.1.0.json"
+	sarifToolName  = "unused-interface-methods"
+	sarifToolURI   = "https://github.com/comerc/unused-interface-methods"
+	sarifSrcRoot   = "%SRCROOT%"
+)
+
+// sarifRuleInfo описывает правило в метаданных SARIF
+type sarifRuleInfo struct {
+	id    results.RuleID
+	name  string
+	short string
+	full  string
+	level string
+}
+
+// sarifRules - все правила линтера в порядке их индексов в отчете
+var sarifRules = []sarifRuleInfo{
+	{
+		id:    results.RuleUnusedMethod,
+		name:  "UnusedInterfaceMethod",
+		short: "Unused interface method",
+		full:  "The interface method is never called, referenced as a method value or otherwise required by the code. It can be removed from the interface together with its implementations.",
+		level: "error",
+	},
+	{
+		id:    results.RuleUnusedEmbed,
+		name:  "UnusedEmbeddedInterface",
+		short: "Unused embedded interface",
+		full:  "None of the methods contributed by the embedded interface are used through the embedding interface.",
+		level: "warning",
+	},
+	{
+		id:    results.RuleTestOnly,
+		name:  "TestOnlyInterfaceMethod",
+		short: "Interface method used only in tests",
+		full:  "The interface method is called only from test files, production code does not need it.",
+		level: "warning",
+	},
+	{
+		id:    results.RuleGenericSkipped,
+		name:  "GenericInterfaceSkipped",
+		short: "Generic interface not analyzed",
+		full:  "Methods of generic interfaces are not analyzed, their usage is unknown.",
+		level: "note",
+	},
+	{
+		id:    results.RuleStale,
+		name:  "StaleSuppression",
+		short: "Stale suppression",
+		full:  "The suppression directive or config ignore rule did not suppress anything in this run and can be removed.",
+		level: "warning",
+	},
+	{
+		id:    results.RuleExportedUnused,
+		name:  "ExportedUnusedInterfaceMethod",
+		short: "Exported interface method unused in the module",
+		full:  "The method of an exported interface is not used inside the module, only external code might need it.",
+		level: "error",
+	},
+	{
+		id:    results.RuleLoadError,
+		name:  "PackageLoadError",
+		short: "Package load error",
+		full:  "The package could not be parsed or type-checked completely, results for it may be incomplete.",
+		level: "warning",
+	},
+}
+
+// SARIF выводит результаты в формате SARIF 2.1.0
+type SARIF struct{}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool               sarifTool                        `json:"tool"`
+	OriginalURIBaseIDs map[string]sarifArtifactLocation `json:"originalUriBaseIds,omitempty"`
+	Results            []sarifResult                    `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string              `json:"id"`
+	Name                 string              `json:"name"`
+	ShortDescription     sarifMessage        `json:"shortDescription"`
+	FullDescription      sarifMessage        `json:"fullDescription"`
+	DefaultConfiguration sarifRuleDefaultCfg `json:"defaultConfiguration"`
+}
+
+type sarifRuleDefaultCfg struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID           string             `json:"ruleId"`
+	RuleIndex        int                `json:"ruleIndex"`
+	Level            string             `json:"level"`
+	Message          sarifMessage       `json:"message"`
+	Locations        []sarifLocation    `json:"locations,omitempty"`
+	RelatedLocations []sarifLocation    `json:"relatedLocations,omitempty"`
+	Fixes            []sarifFix         `json:"fixes,omitempty"`
+	Fingerprints     map[string]string  `json:"partialFingerprints,omitempty"`
+	Suppressions     []sarifSuppression `json:"suppressions,omitempty"`
+}
+
+type sarifSuppression struct {
+	Kind          string         `json:"kind"`
+	Justification string         `json:"justification,omitempty"`
+	Location      *sarifLocation `json:"location,omitempty"`
+}
+
+type sarifLocation struct {
+	ID               *int                   `json:"id,omitempty"`
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+	Message          *sarifMessage          `json:"message,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+	EndLine     int `json:"endLine,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
+}
+
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+type sarifFix struct {
+	Description     sarifMessage          `json:"description"`
+	ArtifactChanges []sarifArtifactChange `json:"artifactChanges"`
+}
+
+type sarifArtifactChange struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Replacements     []sarifReplacement    `json:"replacements"`
+}
+
+type sarifReplacement struct {
+	DeletedRegion   sarifRegion        `json:"deletedRegion"`
+	InsertedContent *sarifArtifactText `json:"insertedContent,omitempty"`
+}
+
+type sarifArtifactText struct {
+	Text string `json:"text"`
+}
+
+// Report реализует Reporter
+func (SARIF) Report(w io.Writer, res *results.Result) error {
+	run := sarifRun{
+		Tool: sarifTool{
+			Driver: sarifDriver{
+				Name:           sarifToolName,
+				InformationURI: sarifToolURI,
+				Rules:          make([]sarifRule, 0, len(sarifRules)),
+			},
+		},
+		Results: make([]sarifResult, 0),
+	}
+
+	for _, rule := range sarifRules {
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+			ID:                   string(rule.id),
+			Name:                 rule.name,
+			ShortDescription:     sarifMessage{Text: rule.short},
+			FullDescription:      sarifMessage{Text: rule.full},
+			DefaultConfiguration: sarifRuleDefaultCfg{Level: rule.level},
+		})
+	}
+
+	for _, f := range res.Findings {
+		// Подавленные находки остаются в отчете с пометкой suppressions
+		if f.Verdict != results.VerdictUnused {
+			continue
+		}
+		run.Results = append(run.Results, newSARIFFindingResult(f))
+	}
+
+	for _, warning := range res.GenericWarnings {
+		ruleIndex, rule := sarifRuleByID(results.RuleGenericSkipped)
+		rule = withSeverity(rule, warning.Level())
+		name := warning.Interface
+		if warning.PkgPath != "" {
+			name = warning.PkgPath + "." + warning.Interface
+		}
+		result := sarifResult{
+			RuleID:    string(rule.id),
+			RuleIndex: ruleIndex,
+			Level:     rule.level,
+			Message: sarifMessage{
+				Text: fmt.Sprintf("Generic interface %s%s is not analyzed (%d methods skipped)",
+					warning.Interface, warning.TypeParams, warning.MethodCount),
+			},
+		}
+		location := sarifLocation{
+			PhysicalLocation: newSARIFPhysicalLocation(results.Range{Start: warning.Position}),
+			LogicalLocations: []sarifLogicalLocation{{FullyQualifiedName: name, Kind: "type"}},
+		}
+		result.Locations = []sarifLocation{location}
+		run.Results = append(run.Results, result)
+	}
+
+	for _, stale := range res.Stale {
+		ruleIndex, rule := sarifRuleByID(results.RuleStale)
+		rule = withSeverity(rule, stale.Level())
+		result := sarifResult{
+			RuleID:    string(rule.id),
+			RuleIndex: ruleIndex,
+			Level:     rule.level,
+			Message:   sarifMessage{Text: staleMessage(stale)},
+		}
+		if physical := newSARIFPhysicalLocation(results.Range{Start: stale.Position}); physical != nil {
+			result.Locations = []sarifLocation{{PhysicalLocation: physical}}
+		}
+		run.Results = append(run.Results, result)
+	}
+
+	for _, loadErr := range res.LoadErrors {
+		ruleIndex, rule := sarifRuleByID(results.RuleLoadError)
+		rule = withSeverity(rule, loadErr.Level())
+		result := sarifResult{
+			RuleID:    string(rule.id),
+			RuleIndex: ruleIndex,
+			Level:     rule.level,
+			Message:   sarifMessage{Text: loadErrorMessage(loadErr)},
+		}
+		if physical := newSARIFPhysicalLocation(results.Range{Start: loadErr.Position}); physical != nil {
+			result.Locations = []sarifLocation{{PhysicalLocation: physical}}
+		}
+		run.Results = append(run.Results, result)
+	}
+
+	run.OriginalURIBaseIDs = map[string]sarifArtifactLocation{
+		sarifSrcRoot: {URI: srcRootURI()},
+	}
+
+	log := sarifLog{
+		Schema:  sarifSchemaURI,
+		Version: sarifVersion,
+		Runs:    []sarifRun{run},
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(log)
+}
+
+// newSARIFFindingResult преобразует неиспользуемый метод в результат SARIF
+func newSARIFFindingResult(f results.Finding) sarifResult {
+	ruleIndex, rule := sarifRuleByID(f.RuleID())
+	rule = withSeverity(rule, f.Level()) // уровень из конфигурации или по умолчанию
+	result := sarifResult{
+		RuleID:    string(rule.id),
+		RuleIndex: ruleIndex,
+		Level:     rule.level,
+		Message: sarifMessage{
+			Text: fmt.Sprintf("Interface method %s.%s%s is not used", f.Interface, f.Method, f.Signature),
+		},
+		Fingerprints: map[string]string{
+			"interfaceMethod/v1": f.Fingerprint().String(),
+		},
+	}
+
+	location := sarifLocation{
+		PhysicalLocation: newSARIFPhysicalLocation(f.Range),
+		LogicalLocations: []sarifLogicalLocation{{FullyQualifiedName: f.ID(), Kind: "member"}},
+	}
+	result.Locations = []sarifLocation{location}
+
+	if f.Suppression != nil {
+		suppression := sarifSuppression{
+			Kind:          "inSource",
+			Justification: f.Suppression.Reason,
+		}
+		if f.Suppression.Scope == results.ScopeConfig {
+			suppression.Kind = "external"
+		}
+		if physical := newSARIFPhysicalLocation(results.Range{Start: f.Suppression.Position}); physical != nil {
+			suppression.Location = &sarifLocation{PhysicalLocation: physical}
+		}
+		result.Suppressions = []sarifSuppression{suppression}
+	}
+
+	// Связанные места: реализации метода и найденные обращения к нему
+	for _, impl := range f.Implementations {
+		if impl.Position.File == "" {
+			continue
+		}
+		id := len(result.RelatedLocations)
+		result.RelatedLocations = append(result.RelatedLocations, sarifLocation{
+			ID:               &id,
+			PhysicalLocation: newSARIFPhysicalLocation(results.Range{Start: impl.Position}),
+			Message:          &sarifMessage{Text: fmt.Sprintf("Implementation by %s", impl.Type)},
+		})
+	}
+	for _, e := range f.Evidence {
+		if e.Position.File == "" {
+			continue
+		}
+		id := len(result.RelatedLocations)
+		result.RelatedLocations = append(result.RelatedLocations, sarifLocation{
+			ID:               &id,
+			PhysicalLocation: newSARIFPhysicalLocation(results.Range{Start: e.Position}),
+			Message:          &sarifMessage{Text: fmt.Sprintf("Reference (%s)", e.Kind)},
+		})
+	}
+
+	for _, fix := range f.Fixes {
+		sf := sarifFix{Description: sarifMessage{Text: fix.Description}}
+		for _, edit := range fix.Edits {
+			region := newSARIFRegion(edit.Range)
+			if region == nil {
+				continue
+			}
+			replacement := sarifReplacement{DeletedRegion: *region}
+			if edit.NewText != "" {
+				replacement.InsertedContent = &sarifArtifactText{Text: edit.NewText}
+			}
+			sf.ArtifactChanges = append(sf.ArtifactChanges, sarifArtifactChange{
+				ArtifactLocation: newSARIFArtifactLocation(edit.Range.Start.File),
+				Replacements:     []sarifReplacement{replacement},
+			})
+		}
+		if len(sf.ArtifactChanges) > 0 {
+			result.Fixes = append(result.Fixes, sf)
+		}
+	}
+
+	return result
+}
+
+// sarifRuleByID возвращает индекс и описание правила
+func sarifRuleByID(id results.RuleID) (int, sarifRuleInfo) {
+	for i, rule := range sarifRules {
+		if rule.id == id {
+			return i, rule
+		}
+	}
+	panic("unknown SARIF rule: " + string(id))
+}
+
+// withSeverity заменяет уровень правила действующей серьезностью находки
+func withSeverity(rule sarifRuleInfo, severity results.Severity) sarifRuleInfo {
+	switch severity {
+	case results.SeverityError, results.SeverityWarning:
+		rule.level = string(severity)
+	case results.SeverityInfo:
+		rule.level = "note"
+	}
+	return rule
+}
+
+// newSARIFPhysicalLocation строит физическое расположение по диапазону
+func newSARIFPhysicalLocation(r results.Range) *sarifPhysicalLocation {
+	if r.Start.File == "" {
+		return nil
+	}
+	return &sarifPhysicalLocation{
+		ArtifactLocation: newSARIFArtifactLocation(r.Start.File),
+		Region:           newSARIFRegion(r),
+	}
+}
+
+// newSARIFArtifactLocation строит ссылку на файл относительно %SRCROOT%
+func newSARIFArtifactLocation(file string) sarifArtifactLocation {
+	rel := config.GetRelativePath(file)
+	if filepath.IsAbs(rel) {
+		return sarifArtifactLocation{URI: fileURI(rel)}
+	}
+	return sarifArtifactLocation{URI: filepath.ToSlash(rel), URIBaseID: sarifSrcRoot}
+}
+
+// newSARIFRegion строит регион; отсутствующие значения опускаются
+func newSARIFRegion(r results.Range) *sarifRegion {
+	if r.Start.Line <= 0 {
+		return nil
+	}
+	region := &sarifRegion{
+		StartLine:   r.Start.Line,
+		StartColumn: r.Start.Column,
+	}
+	if r.End.Line > 0 {
+		region.EndLine = r.End.Line
+		region.EndColumn = r.End.Column
+	}
+	return region
+}
+
+// srcRootURI возвращает URI текущей директории, относительно которой строятся пути
+func srcRootURI() string {
+	wd, err := filepath.Abs(".")
+	if err != nil {
+		return "file:///"
+	}
+	uri := fileURI(wd)
+	if !strings.HasSuffix(uri, "/") {
+		uri += "/"
+	}
+	return uri
+}
+
+// fileURI преобразует абсолютный путь в file:// URI
+func fileURI(path string) string {
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path // C:/dir -> /C:/dir
+	}
+	return "file://" + path
+}
@@ -0,0 +1,582 @@
+package results
+
+import (
+	"go/token"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Verdict определяет итог проверки метода интерфейса
+type Verdict string
+
+const (
+	VerdictUsed   Verdict = "used"   // найдено использование метода
+	VerdictUnused Verdict = "unused" // метод не используется
+)
+
+// Engine определяет движок, который вынес вердикт
+type Engine string
+
+const (
+	EngineLinter Engine = "linter"
+	EngineStage1 Engine = "stage1"
+	EngineStage2 Engine = "stage2"
+)
+
+// EvidenceKind определяет вид доказательства использования метода
+type EvidenceKind string
+
+const (
+	EvidenceCall        EvidenceKind = "call"         // прямой вызов obj.Method()
+	EvidenceMethodValue EvidenceKind = "method-value" // обращение без вызова: obj.Method
+	EvidenceField       EvidenceKind = "field"        // поле структуры с типом интерфейса
+	EvidenceReflection  EvidenceKind = "reflection"   // возможный вызов по имени через reflect: MethodByName("Method")
+	EvidenceVerifier    EvidenceKind = "verifier"     // результат внешней проверки (staticcheck)
+	EvidenceAPI         EvidenceKind = "api"          // правило конфигурации объявило метод публичным API
+)
+
+// RuleID определяет вид проблемы, о которой сообщает линтер
+type RuleID string
+
+const (
+	RuleUnusedMethod   RuleID = "unused-method"     // метод интерфейса нигде не используется
+	RuleUnusedEmbed    RuleID = "unused-embed"      // встроенный интерфейс нигде не используется
+	RuleTestOnly       RuleID = "test-only"         // метод используется только в тестах
+	RuleGenericSkipped RuleID = "generic-skipped"   // дженерик-интерфейс не проанализирован
+	RuleStale          RuleID = "stale-suppression" // директива или правило ignore ничего не подавили
+	RuleExportedUnused RuleID = "exported-unused"   // метод, доступный другим модулям, не используется внутри модуля
+	RuleLoadError      RuleID = "load-error"        // пакет не загрузился или не прошел проверку типов
+)
+
+// Severity определяет серьезность находки
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+	SeverityOff     Severity = "off" // находки этого вида не выводятся; как порог fail-on - не проваливать запуск
+)
+
+// Severities - допустимые значения серьезности в порядке убывания
+var Severities = []Severity{SeverityError, SeverityWarning, SeverityInfo, SeverityOff}
+
+// Kinds - виды находок, для которых настраивается серьезность
+var Kinds = []RuleID{RuleUnusedMethod, RuleTestOnly, RuleUnusedEmbed, RuleExportedUnused, RuleGenericSkipped, RuleStale, RuleLoadError}
+
+// defaultSeverities - серьезность видов находок, если она не задана в конфигурации.
+// Ошибки загрузки по умолчанию не проваливают запуск: анализ остальных пакетов продолжается.
+// Устаревшее подавление тоже: лишняя директива не мешает коду, ее достаточно показать.
+// Неиспользуемый метод публичного API проваливает запуск, как и раньше, пока его не понизят
+var defaultSeverities = map[RuleID]Severity{
+	RuleUnusedMethod:   SeverityError,
+	RuleTestOnly:       SeverityWarning,
+	RuleUnusedEmbed:    SeverityWarning,
+	RuleExportedUnused: SeverityError,
+	RuleGenericSkipped: SeverityInfo,
+	RuleStale:          SeverityWarning,
+	RuleLoadError:      SeverityWarning,
+}
+
+// DefaultSeverity возвращает серьезность вида находок по умолчанию
+func DefaultSeverity(kind RuleID) Severity {
+	return defaultSeverities[kind]
+}
+
+// rank упорядочивает серьезность: чем выше, тем серьезнее
+func (s Severity) rank() int {
+	switch s {
+	case SeverityError:
+		return 3
+	case SeverityWarning:
+		return 2
+	case SeverityInfo:
+		return 1
+	}
+	return 0
+}
+
+// Reaches сообщает, достигает ли серьезность порога fail-on. Порог off не достигается никогда
+func (s Severity) Reaches(threshold Severity) bool {
+	return threshold.rank() > 0 && s.rank() >= threshold.rank()
+}
+
+// SuppressionScope определяет область действия директивы подавления
+type SuppressionScope string
+
+const (
+	ScopeMethod    SuppressionScope = "method"    // директива на методе интерфейса
+	ScopeInterface SuppressionScope = "interface" // директива на объявлении интерфейса
+	ScopeFile      SuppressionScope = "file"      // директива до package
+	ScopeConfig    SuppressionScope = "config"    // запись exclude в конфигурации
+)
+
+// StaleKind определяет вид устаревшего подавления
+type StaleKind string
+
+const (
+	StaleDirective   StaleKind = "directive"    // директива в исходном коде
+	StaleIgnoreRule  StaleKind = "ignore-rule"  // паттерн ignore в конфигурации
+	StaleExcludeRule StaleKind = "exclude-rule" // запись exclude в конфигурации
+)
+
+// Position представляет позицию в исходном файле
+type Position struct {
+	File   string
+	Line   int
+	Column int
+}
+
+// Range представляет диапазон позиций в исходном файле
+type Range struct {
+	Start Position
+	End   Position
+}
+
+// Evidence представляет одно доказательство, повлиявшее на вердикт
+type Evidence struct {
+	Kind     EvidenceKind
+	Position Position
+	Detail   string
+}
+
+// ProvesUse сообщает, доказывает ли доказательство использование метода. Поиск
+// по имени через reflect не доказывает: имя может принадлежать методу любого типа
+func (e Evidence) ProvesUse() bool {
+	return e.Kind != EvidenceReflection
+}
+
+// Edit представляет замену диапазона текста в файле
+type Edit struct {
+	Range   Range
+	NewText string
+}
+
+// Fix представляет предлагаемое исправление
+type Fix struct {
+	Description string
+	Edits       []Edit
+}
+
+// Implementation представляет реализацию метода интерфейса конкретным типом
+type Implementation struct {
+	Type     string // полное имя типа
+	Position Position
+}
+
+// Suppression представляет директиву, подавившую находку
+type Suppression struct {
+	Scope     SuppressionScope
+	Directive string // текст директивы без причины, например "nolint:unusedinterfacemethods"
+	Reason    string
+	Position  Position
+}
+
+// RuleMatch представляет правило конфигурации, определившее решение по методу
+type RuleMatch struct {
+	Rule     string // имя правила или описание его сопоставителей
+	Action   string
+	Reason   string
+	Position Position
+}
+
+// StaleSuppression представляет директиву или правило ignore, которые ничего не подавили
+type StaleSuppression struct {
+	Kind     StaleKind
+	Rule     string // директива без причины или паттерн ignore
+	Position Position
+	Severity Severity // серьезность из конфигурации; пустое значение - по умолчанию
+}
+
+// Level возвращает серьезность устаревшего подавления с учетом значения по умолчанию
+func (s StaleSuppression) Level() Severity {
+	return level(s.Severity, RuleStale)
+}
+
+// Finding представляет результат проверки одного метода интерфейса
+type Finding struct {
+	PkgPath         string  // путь к пакету с объявлением интерфейса
+	Interface       string  // имя интерфейса
+	Method          string  // имя метода
+	Signature       string  // сигнатура метода
+	Range           Range   // положение имени метода в объявлении интерфейса
+	Verdict         Verdict // итог проверки
+	Evidence        []Evidence
+	Implementations []Implementation
+	Fixes           []Fix
+	Engine          Engine       // движок, который вынес вердикт
+	Suppression     *Suppression // директива подавления, если она есть
+	Severity        Severity     // серьезность из правила или конфигурации; пустое значение - по умолчанию
+	Rule            *RuleMatch   // правило конфигурации, если оно подошло
+	Kind            RuleID       // вид находки; пустое значение - неиспользуемый метод
+}
+
+// IsReported сообщает, нужно ли выводить находку как проблему:
+// метод не используется и не подавлен директивой
+func (f Finding) IsReported() bool {
+	return f.Verdict == VerdictUnused && f.Suppression == nil
+}
+
+// IsWarning сообщает, выводится ли находка как предупреждение
+func (f Finding) IsWarning() bool {
+	return f.Level() == SeverityWarning
+}
+
+// RuleID возвращает вид находки
+func (f Finding) RuleID() RuleID {
+	if f.Kind == "" {
+		return RuleUnusedMethod
+	}
+	return f.Kind
+}
+
+// Level возвращает серьезность находки с учетом значения по умолчанию для ее вида
+func (f Finding) Level() Severity {
+	return level(f.Severity, f.RuleID())
+}
+
+// ID возвращает полное имя метода в виде pkg.Interface.Method
+func (f Finding) ID() string {
+	if f.PkgPath == "" {
+		return f.Interface + "." + f.Method
+	}
+	return f.PkgPath + "." + f.Interface + "." + f.Method
+}
+
+// Fingerprint возвращает отпечаток метода, не зависящий от номеров строк
+func (f Finding) Fingerprint() Fingerprint {
+	return Fingerprint{
+		PkgPath:   f.PkgPath,
+		Interface: f.Interface,
+		Method:    f.Method,
+		Signature: f.Signature,
+	}
+}
+
+// Lookup ищет находки по полному имени метода pkg.Interface.Method. Путь пакета
+// можно сократить до последних элементов или опустить; точное совпадение важнее
+func Lookup(findings []Finding, id string) []Finding {
+	var exact, partial []Finding
+	for _, f := range findings {
+		switch full := f.ID(); {
+		case full == id:
+			exact = append(exact, f)
+		case strings.HasSuffix(full, "/"+id), f.Interface+"."+f.Method == id:
+			partial = append(partial, f)
+		}
+	}
+	if len(exact) > 0 {
+		return exact
+	}
+	return partial
+}
+
+// Fingerprint - стабильный отпечаток метода интерфейса:
+// пакет, интерфейс, метод и сигнатура, без позиции в файле
+type Fingerprint struct {
+	PkgPath   string
+	Interface string
+	Method    string
+	Signature string
+}
+
+// String возвращает отпечаток в виде pkg.Interface.Method(sig)
+func (fp Fingerprint) String() string {
+	return Finding{PkgPath: fp.PkgPath, Interface: fp.Interface, Method: fp.Method}.ID() + fp.Signature
+}
+
+// GenericWarning представляет дженерик-интерфейс, пропущенный при анализе
+type GenericWarning struct {
+	PkgPath     string
+	Interface   string
+	TypeParams  string
+	Position    Position
+	MethodCount int
+	Severity    Severity // серьезность из конфигурации; пустое значение - по умолчанию
+}
+
+// Level возвращает серьезность предупреждения с учетом значения по умолчанию
+func (w GenericWarning) Level() Severity {
+	return level(w.Severity, RuleGenericSkipped)
+}
+
+// LoadError - ошибка загрузки или проверки типов пакета.
+// Пакет анализируется по тем данным, которые удалось получить
+type LoadError struct {
+	PkgPath  string
+	Message  string
+	Position Position // пустое значение - ошибка не привязана к файлу
+	Severity Severity // серьезность из конфигурации; пустое значение - по умолчанию
+}
+
+// Level возвращает серьезность ошибки загрузки с учетом значения по умолчанию
+func (e LoadError) Level() Severity {
+	return level(e.Severity, RuleLoadError)
+}
+
+// level возвращает заданную серьезность или серьезность вида по умолчанию
+func level(s Severity, kind RuleID) Severity {
+	if s != "" {
+		return s
+	}
+	return DefaultSeverity(kind)
+}
+
+// Summary содержит итоговую статистику запуска
+type Summary struct {
+	Used              int
+	Unused            int // неиспользуемые методы без директив подавления
+	Warnings          int // из них выводимые как предупреждения
+	Total             int
+	SkippedInterfaces int
+	SkippedMethods    int
+	Suppressed        int // неиспользуемые методы, подавленные директивами
+	Stale             int // устаревшие директивы и правила ignore
+	LoadErrors        int // ошибки загрузки и проверки типов пакетов
+	Baselined         int // неиспользуемые методы, скрытые baseline-файлом
+	FixedBaseline     int // записи baseline, которые больше не воспроизводятся
+}
+
+// Result содержит все результаты одного запуска
+type Result struct {
+	Findings        []Finding
+	GenericWarnings []GenericWarning
+	Stale           []StaleSuppression // подавления, которые ничего не подавили
+	LoadErrors      []LoadError        // ошибки загрузки пакетов
+	Baselined       []Finding          // неиспользуемые методы, уже записанные в baseline
+	FixedBaseline   []Fingerprint      // записи baseline, для которых больше нет находок
+}
+
+// Summary подсчитывает итоговую статистику по результатам
+func (r *Result) Summary() Summary {
+	var s Summary
+	for _, f := range r.Findings {
+		switch f.Verdict {
+		case VerdictUsed:
+			s.Used++
+		case VerdictUnused:
+			if f.Suppression != nil {
+				s.Suppressed++
+			} else {
+				s.Unused++
+				if f.IsWarning() {
+					s.Warnings++
+				}
+			}
+		}
+	}
+	s.Total = len(r.Findings)
+	s.SkippedInterfaces = len(r.GenericWarnings)
+	for _, w := range r.GenericWarnings {
+		s.SkippedMethods += w.MethodCount
+	}
+	s.Stale = len(r.Stale)
+	s.LoadErrors = len(r.LoadErrors)
+	s.Baselined = len(r.Baselined)
+	s.FixedBaseline = len(r.FixedBaseline)
+	return s
+}
+
+// Fails сообщает, есть ли среди находок, предупреждений о дженериках и устаревших
+// подавлений проблемы с серьезностью не ниже порога fail-on
+func (r *Result) Fails(threshold Severity) bool {
+	for _, f := range r.Findings {
+		if f.IsReported() && f.Level().Reaches(threshold) {
+			return true
+		}
+	}
+	for _, w := range r.GenericWarnings {
+		if w.Level().Reaches(threshold) {
+			return true
+		}
+	}
+	for _, s := range r.Stale {
+		if s.Level().Reaches(threshold) {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadFails сообщает, есть ли ошибки загрузки с серьезностью не ниже порога fail-on
+func (r *Result) LoadFails(threshold Severity) bool {
+	for _, e := range r.LoadErrors {
+		if e.Level().Reaches(threshold) {
+			return true
+		}
+	}
+	return false
+}
+
+// Sort упорядочивает результаты по позиции, а затем по имени
+func (r *Result) Sort() {
+	sort.SliceStable(r.Findings, func(i, j int) bool {
+		a, b := r.Findings[i], r.Findings[j]
+		if a.Range.Start.File != b.Range.Start.File {
+			return a.Range.Start.File < b.Range.Start.File
+		}
+		if a.Range.Start.Line != b.Range.Start.Line {
+			return a.Range.Start.Line < b.Range.Start.Line
+		}
+		if a.Range.Start.Column != b.Range.Start.Column {
+			return a.Range.Start.Column < b.Range.Start.Column
+		}
+		return a.ID() < b.ID()
+	})
+	sort.SliceStable(r.GenericWarnings, func(i, j int) bool {
+		a, b := r.GenericWarnings[i], r.GenericWarnings[j]
+		if a.Position.File != b.Position.File {
+			return a.Position.File < b.Position.File
+		}
+		return a.Position.Line < b.Position.Line
+	})
+	sort.SliceStable(r.Stale, func(i, j int) bool {
+		a, b := r.Stale[i], r.Stale[j]
+		if a.Position.File != b.Position.File {
+			return a.Position.File < b.Position.File
+		}
+		return a.Position.Line < b.Position.Line
+	})
+	sort.SliceStable(r.LoadErrors, func(i, j int) bool {
+		a, b := r.LoadErrors[i], r.LoadErrors[j]
+		if a.Position.File != b.Position.File {
+			return a.Position.File < b.Position.File
+		}
+		if a.Position.Line != b.Position.Line {
+			return a.Position.Line < b.Position.Line
+		}
+		return a.PkgPath < b.PkgPath
+	})
+}
+
+// Merge объединяет результаты анализа нескольких конфигураций сборки. Метод используется,
+// если он используется хотя бы в одной из них; метод из файла, который входит только
+// в часть конфигураций, проверяется в них. Устаревшие подавления не объединяются:
+// их нужно заново вычислить по объединенным находкам
+func Merge(runs ...*Result) *Result {
+	type key struct {
+		pos Position
+		id  string
+	}
+	merged := &Result{}
+	findings := make(map[key]int)
+	warnings := make(map[Position]bool)
+	loadErrors := make(map[LoadError]bool)
+
+	for _, run := range runs {
+		for _, f := range run.Findings {
+			k := key{f.Range.Start, f.ID()}
+			i, ok := findings[k]
+			if !ok {
+				findings[k] = len(merged.Findings)
+				merged.Findings = append(merged.Findings, f)
+				continue
+			}
+			existing := &merged.Findings[i]
+			if existing.Verdict == VerdictUnused && f.Verdict == VerdictUsed {
+				existing.Verdict = VerdictUsed
+				existing.Evidence = f.Evidence
+				existing.Fixes = nil
+			}
+			for _, impl := range f.Implementations {
+				if !slices.Contains(existing.Implementations, impl) {
+					existing.Implementations = append(existing.Implementations, impl)
+				}
+			}
+		}
+		for _, w := range run.GenericWarnings {
+			if !warnings[w.Position] {
+				warnings[w.Position] = true
+				merged.GenericWarnings = append(merged.GenericWarnings, w)
+			}
+		}
+		for _, e := range run.LoadErrors {
+			if !loadErrors[e] {
+				loadErrors[e] = true
+				merged.LoadErrors = append(merged.LoadErrors, e)
+			}
+		}
+	}
+
+	merged.Sort()
+	return merged
+}
+
+// Delta - изменение выводимых находок между двумя запусками
+type Delta struct {
+	Unused  []Finding // находки, которых раньше не было среди выводимых
+	Used    []Finding // бывшие находки, которые теперь используются или подавлены
+	Removed []Finding // бывшие находки, методы которых больше не объявлены
+}
+
+// Empty сообщает, что выводимые находки не изменились
+func (d Delta) Empty() bool {
+	return len(d.Unused) == 0 && len(d.Used) == 0 && len(d.Removed) == 0
+}
+
+// Diff сравнивает выводимые находки двух запусков по виду и полному имени метода:
+// перенос метода в другую строку не считается изменением. prev = nil - пустой запуск
+func Diff(prev, next *Result) Delta {
+	// Вид находки метода меняется вместе с вердиктом (exported-unused у неиспользуемых),
+	// поэтому ключ отличает только встроенные интерфейсы от методов
+	key := func(f Finding) string {
+		if f.Kind == RuleUnusedEmbed {
+			return string(RuleUnusedEmbed) + " " + f.ID()
+		}
+		return f.ID()
+	}
+	before := make(map[string]bool)
+	if prev != nil {
+		for _, f := range prev.Findings {
+			if f.IsReported() {
+				before[key(f)] = true
+			}
+		}
+	}
+
+	var d Delta
+	after := make(map[string]bool, len(next.Findings)+len(next.Baselined))
+	for _, f := range next.Findings {
+		k := key(f)
+		after[k] = true
+		switch {
+		case f.IsReported() && !before[k]:
+			d.Unused = append(d.Unused, f)
+		case !f.IsReported() && before[k]:
+			d.Used = append(d.Used, f)
+		}
+	}
+	for _, f := range next.Baselined {
+		after[key(f)] = true
+	}
+	if prev != nil {
+		for _, f := range prev.Findings {
+			if f.IsReported() && !after[key(f)] {
+				d.Removed = append(d.Removed, f)
+			}
+		}
+	}
+	return d
+}
+
+// NewPosition преобразует token.Position в Position
+func NewPosition(p token.Position) Position {
+	return Position{
+		File:   p.Filename,
+		Line:   p.Line,
+		Column: p.Column,
+	}
+}
+
+// NewRange строит Range по паре позиций из FileSet
+func NewRange(fset *token.FileSet, start, end token.Pos) Range {
+	var r Range
+	if start.IsValid() {
+		r.Start = NewPosition(fset.Position(start))
+	}
+	if end.IsValid() {
+		r.End = NewPosition(fset.Position(end))
+	}
+	return r
+}
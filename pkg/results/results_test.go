@@ -0,0 +1,182 @@
+package results
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindingID(t *testing.T) {
+	f := Finding{PkgPath: "example.com/app", Interface: "Reader", Method: "Read"}
+	assert.Equal(t, "example.com/app.Reader.Read", f.ID())
+
+	f.PkgPath = ""
+	assert.Equal(t, "Reader.Read", f.ID())
+}
+
+func TestFindingFingerprint(t *testing.T) {
+	a := Finding{
+		PkgPath:   "example.com/app",
+		Interface: "Reader",
+		Method:    "Read",
+		Signature: "(p []byte) (int, error)",
+		Range:     Range{Start: Position{File: "reader.go", Line: 3}},
+	}
+	b := a
+	b.Range.Start.Line = 10 // сдвиг строк не меняет отпечаток
+	assert.Equal(t, a.Fingerprint(), b.Fingerprint())
+	assert.Equal(t, "example.com/app.Reader.Read(p []byte) (int, error)", a.Fingerprint().String())
+
+	b.Signature = "(p []byte) error"
+	assert.NotEqual(t, a.Fingerprint(), b.Fingerprint())
+}
+
+func TestLookup(t *testing.T) {
+	findings := []Finding{
+		{PkgPath: "example.com/app/store", Interface: "Store", Method: "Get"},
+		{PkgPath: "example.com/app/cache", Interface: "Store", Method: "Get"},
+		{PkgPath: "example.com/app/cache", Interface: "Store", Method: "Put"},
+		{PkgPath: "example.com/app/store", Interface: "Store", Method: "Getter"},
+	}
+	ids := func(found []Finding) []string {
+		var ids []string
+		for _, f := range found {
+			ids = append(ids, f.ID())
+		}
+		return ids
+	}
+	assert.Equal(t, []string{"example.com/app/store.Store.Get"}, ids(Lookup(findings, "example.com/app/store.Store.Get")))
+	assert.Equal(t, []string{"example.com/app/cache.Store.Put"}, ids(Lookup(findings, "cache.Store.Put")))
+	assert.Equal(t, []string{"example.com/app/cache.Store.Put"}, ids(Lookup(findings, "Store.Put")))
+	// Без пути пакета имя может быть неоднозначным
+	assert.Len(t, Lookup(findings, "Store.Get"), 2)
+	assert.Empty(t, Lookup(findings, "ore.Store.Get"))
+}
+
+func TestResultSummaryAndSort(t *testing.T) {
+	res := &Result{
+		Findings: []Finding{
+			{Interface: "B", Method: "M", Verdict: VerdictUnused, Range: Range{Start: Position{File: "b.go", Line: 1}}},
+			{Interface: "A", Method: "M", Verdict: VerdictUsed, Range: Range{Start: Position{File: "a.go", Line: 5}}},
+			{Interface: "A", Method: "N", Verdict: VerdictUnused, Range: Range{Start: Position{File: "a.go", Line: 2}}},
+		},
+		GenericWarnings: []GenericWarning{
+			{Interface: "G", MethodCount: 3},
+		},
+	}
+
+	res.Sort()
+	assert.Equal(t, "A.N", res.Findings[0].ID())
+	assert.Equal(t, "A.M", res.Findings[1].ID())
+	assert.Equal(t, "B.M", res.Findings[2].ID())
+
+	assert.Equal(t, Summary{
+		Used:              1,
+		Unused:            2,
+		Total:             3,
+		SkippedInterfaces: 1,
+		SkippedMethods:    3,
+	}, res.Summary())
+}
+
+func TestSeverityThreshold(t *testing.T) {
+	assert.True(t, SeverityError.Reaches(SeverityWarning))
+	assert.True(t, SeverityWarning.Reaches(SeverityWarning))
+	assert.False(t, SeverityInfo.Reaches(SeverityWarning))
+	assert.False(t, SeverityError.Reaches(SeverityOff))
+
+	res := &Result{
+		Findings: []Finding{
+			{Interface: "A", Method: "M", Verdict: VerdictUnused, Severity: SeverityWarning},
+			{Interface: "A", Method: "N", Verdict: VerdictUnused, Suppression: &Suppression{}},
+		},
+		GenericWarnings: []GenericWarning{{Interface: "G", MethodCount: 1}},
+		LoadErrors:      []LoadError{{PkgPath: "example.com/app", Message: "syntax error"}},
+	}
+	assert.False(t, res.Fails(SeverityError))
+	assert.True(t, res.Fails(SeverityWarning))
+	assert.False(t, res.LoadFails(SeverityError))
+	assert.True(t, res.LoadFails(SeverityWarning))
+
+	// Серьезность по умолчанию зависит от вида находки
+	assert.Equal(t, SeverityError, Finding{}.Level())
+	assert.Equal(t, SeverityWarning, Finding{Kind: RuleTestOnly}.Level())
+	assert.Equal(t, SeverityInfo, res.GenericWarnings[0].Level())
+	assert.Equal(t, 1, res.Summary().LoadErrors)
+}
+
+func TestMerge(t *testing.T) {
+	start := func(line int) Range { return Range{Start: Position{File: "sink.go", Line: line}} }
+	linux := &Result{
+		Findings: []Finding{
+			{Interface: "Sink", Method: "Notify", Range: start(3), Verdict: VerdictUsed, Evidence: []Evidence{{Kind: EvidenceCall}}},
+			{Interface: "Sink", Method: "Reset", Range: start(4), Verdict: VerdictUnused},
+		},
+		LoadErrors: []LoadError{{PkgPath: "example.com/sink", Message: "syntax error"}},
+	}
+	windows := &Result{
+		Findings: []Finding{
+			{Interface: "Sink", Method: "Notify", Range: start(3), Verdict: VerdictUnused, Fixes: []Fix{{Description: "remove"}}},
+			{Interface: "Sink", Method: "Reset", Range: start(4), Verdict: VerdictUnused},
+			{Interface: "Service", Method: "Stop", Range: Range{Start: Position{File: "service_windows.go", Line: 5}}, Verdict: VerdictUnused},
+		},
+		LoadErrors: []LoadError{{PkgPath: "example.com/sink", Message: "syntax error"}},
+	}
+
+	res := Merge(linux, windows)
+	assert.Len(t, res.Findings, 3)
+	assert.Len(t, res.LoadErrors, 1)
+
+	verdicts := make(map[string]Verdict)
+	for _, f := range res.Findings {
+		verdicts[f.ID()] = f.Verdict
+		if f.Method == "Notify" {
+			assert.Empty(t, f.Fixes)
+			assert.Len(t, f.Evidence, 1)
+		}
+	}
+	assert.Equal(t, map[string]Verdict{
+		"Sink.Notify":  VerdictUsed,
+		"Sink.Reset":   VerdictUnused,
+		"Service.Stop": VerdictUnused,
+	}, verdicts)
+}
+
+func TestDiff(t *testing.T) {
+	finding := func(method string, line int, verdict Verdict) Finding {
+		return Finding{Interface: "Store", Method: method, Range: Range{Start: Position{File: "store.go", Line: line}}, Verdict: verdict}
+	}
+	prev := &Result{Findings: []Finding{
+		finding("Get", 3, VerdictUsed),
+		finding("Put", 4, VerdictUnused),
+		finding("Delete", 5, VerdictUnused),
+		finding("Close", 6, VerdictUnused),
+	}}
+	next := &Result{Findings: []Finding{
+		finding("Get", 3, VerdictUnused),
+		finding("Put", 4, VerdictUsed),
+		finding("Close", 8, VerdictUnused), // сдвиг строки - не изменение
+	}}
+
+	ids := func(findings []Finding) []string {
+		var names []string
+		for _, f := range findings {
+			names = append(names, f.ID())
+		}
+		return names
+	}
+	d := Diff(prev, next)
+	assert.Equal(t, []string{"Store.Get"}, ids(d.Unused))
+	assert.Equal(t, []string{"Store.Put"}, ids(d.Used))
+	assert.Equal(t, []string{"Store.Delete"}, ids(d.Removed))
+
+	assert.True(t, Diff(next, next).Empty())
+
+	// Метод, который начал использоваться, теряет вид exported-unused, но остается тем же методом
+	exported := finding("Get", 3, VerdictUnused)
+	exported.Kind = RuleExportedUnused
+	d = Diff(&Result{Findings: []Finding{exported}}, &Result{Findings: []Finding{finding("Get", 3, VerdictUsed)}})
+	assert.Equal(t, []string{"Store.Get"}, ids(d.Used))
+	assert.Empty(t, d.Removed)
+	assert.Equal(t, []string{"Store.Get", "Store.Close"}, ids(Diff(nil, next).Unused))
+}
@@ -0,0 +1,179 @@
+package rules
+
+import (
+	"go/types"
+	"strings"
+
+	"github.com/comerc/unused-interface-methods/pkg/config"
+	"github.com/comerc/unused-interface-methods/pkg/results"
+)
+
+// Lookup находит интерфейсы в проанализированных пакетах и их зависимостях
+type Lookup struct {
+	packages map[string]*types.Package
+}
+
+// NewLookup строит Lookup по пакетам и всем пакетам, которые они импортируют
+func NewLookup(pkgs []*types.Package) *Lookup {
+	l := &Lookup{packages: make(map[string]*types.Package)}
+	var walk func(pkg *types.Package)
+	walk = func(pkg *types.Package) {
+		if pkg == nil || l.packages[pkg.Path()] != nil {
+			return
+		}
+		l.packages[pkg.Path()] = pkg
+		for _, imp := range pkg.Imports() {
+			walk(imp)
+		}
+	}
+	for _, pkg := range pkgs {
+		walk(pkg)
+	}
+	return l
+}
+
+// Interface возвращает интерфейс по пути пакета и имени или nil, если он не найден
+func (l *Lookup) Interface(pkgPath, name string) *types.Interface {
+	if l == nil || l.packages[pkgPath] == nil {
+		return nil
+	}
+	obj, ok := l.packages[pkgPath].Scope().Lookup(name).(*types.TypeName)
+	if !ok {
+		return nil
+	}
+	iface, _ := obj.Type().Underlying().(*types.Interface)
+	return iface
+}
+
+// implements возвращает проверку для Subject.Implements: реализует ли интерфейс
+// pkgPath.name внешний интерфейс, заданный как "pkg/path.Name"
+func (l *Lookup) implements(pkgPath, name string) func(string) bool {
+	iface := l.Interface(pkgPath, name)
+	if iface == nil {
+		return nil
+	}
+	return func(external string) bool {
+		i := strings.LastIndex(external, ".")
+		target := l.Interface(external[:i], external[i+1:])
+		return target != nil && types.Implements(iface, target)
+	}
+}
+
+// Apply применяет правила конфигурации к результатам анализа:
+// ignore убирает метод из результатов, api делает его используемым,
+// warn и error задают серьезность находки. Возвращает убранные находки с правилом,
+// которое их убрало
+func Apply(res *results.Result, cfg *config.Config, lookup *Lookup) []results.Finding {
+	var ignored []results.Finding
+	findings := res.Findings[:0]
+	for _, f := range res.Findings {
+		decision := cfg.Decide(config.Subject{
+			File:       f.Range.Start.File,
+			PkgPath:    f.PkgPath,
+			Interface:  f.Interface,
+			Method:     f.Method,
+			Implements: lookup.implements(f.PkgPath, f.Interface),
+		})
+		if decision.Rule != nil {
+			f.Rule = newRuleMatch(decision)
+		}
+
+		switch decision.Action {
+		case config.ActionIgnore:
+			ignored = append(ignored, f)
+			continue
+		case config.ActionAPI:
+			if f.Verdict == results.VerdictUnused {
+				f.Verdict = results.VerdictUsed
+				f.Fixes = nil
+				f.Evidence = append(f.Evidence, results.Evidence{
+					Kind:     results.EvidenceAPI,
+					Position: f.Rule.Position,
+					Detail:   f.Rule.Rule,
+				})
+			}
+		case config.ActionWarn:
+			f.Severity = results.SeverityWarning
+		case config.ActionError:
+			f.Severity = results.SeverityError
+		}
+		findings = append(findings, f)
+	}
+	res.Findings = findings
+
+	// Дженерик-интерфейсы не анализируются: правила ignore и api только снимают предупреждение
+	warnings := res.GenericWarnings[:0]
+	for _, w := range res.GenericWarnings {
+		decision := cfg.Decide(config.Subject{
+			File:      w.Position.File,
+			PkgPath:   w.PkgPath,
+			Interface: w.Interface,
+			Generic:   true,
+		})
+		if decision.Action == config.ActionIgnore || decision.Action == config.ActionAPI {
+			continue
+		}
+		warnings = append(warnings, w)
+	}
+	res.GenericWarnings = warnings
+	return ignored
+}
+
+// ApplySeverities задает серьезность находкам, предупреждениям о дженериках, устаревшим
+// подавлениям и ошибкам загрузки по ключу severity конфигурации. Серьезность из правил
+// warn и error точнее и сохраняется. Находки с серьезностью off убираются из результатов
+// и возвращаются
+func ApplySeverities(res *results.Result, cfg *config.Config) []results.Finding {
+	var off []results.Finding
+	findings := res.Findings[:0]
+	for _, f := range res.Findings {
+		if f.Verdict == results.VerdictUnused && f.Severity == "" {
+			f.Severity, _ = cfg.SeverityFor(f.RuleID(), f.Range.Start.File)
+		}
+		if f.Verdict == results.VerdictUnused && f.Severity == results.SeverityOff {
+			off = append(off, f)
+			continue
+		}
+		findings = append(findings, f)
+	}
+	res.Findings = findings
+
+	warnings := res.GenericWarnings[:0]
+	for _, w := range res.GenericWarnings {
+		if w.Severity, _ = cfg.SeverityFor(results.RuleGenericSkipped, w.Position.File); w.Severity != results.SeverityOff {
+			warnings = append(warnings, w)
+		}
+	}
+	res.GenericWarnings = warnings
+
+	stale := res.Stale[:0]
+	for _, s := range res.Stale {
+		if s.Severity, _ = cfg.SeverityFor(results.RuleStale, s.Position.File); s.Severity != results.SeverityOff {
+			stale = append(stale, s)
+		}
+	}
+	res.Stale = stale
+
+	loadErrors := res.LoadErrors[:0]
+	for _, e := range res.LoadErrors {
+		if e.Severity, _ = cfg.SeverityFor(results.RuleLoadError, e.Position.File); e.Severity != results.SeverityOff {
+			loadErrors = append(loadErrors, e)
+		}
+	}
+	res.LoadErrors = loadErrors
+	return off
+}
+
+// newRuleMatch описывает сработавшее правило для объяснения решения.
+// Паттерны ignore не имеют номера строки в правилах, поэтому позиция для них пустая
+func newRuleMatch(d config.Decision) *results.RuleMatch {
+	match := &results.RuleMatch{
+		Rule:   d.Rule.String(),
+		Action: string(d.Action),
+		Reason: d.Rule.Reason,
+	}
+	if d.Rule.Line > 0 {
+		match.Position = results.Position{File: d.Rule.File, Line: d.Rule.Line}
+	}
+	return match
+}
@@ -0,0 +1,140 @@
+package rules
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/comerc/unused-interface-methods/pkg/config"
+	"github.com/comerc/unused-interface-methods/pkg/linter"
+	"github.com/comerc/unused-interface-methods/pkg/results"
+)
+
+// analyze запускает линтер на testdata
+func analyze(t *testing.T) (*results.Result, *Lookup) {
+	l := linter.New(config.DefaultConfig(), false)
+	l.SetLogOutput(io.Discard)
+	assert.NoError(t, l.LoadPackages("testdata"))
+	l.ExtractInterfaceMethods()
+	return l.FindUnusedMethods(), NewLookup(l.TypesPackages())
+}
+
+// byMethod возвращает находки по имени Interface.Method
+func byMethod(res *results.Result) map[string]results.Finding {
+	findings := make(map[string]results.Finding)
+	for _, f := range res.Findings {
+		findings[f.Interface+"."+f.Method] = f
+	}
+	return findings
+}
+
+func TestApply(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), ".unused-interface-methods.yml")
+	assert.NoError(t, os.WriteFile(configPath, []byte(`rules:
+  - implements: io.Closer
+    action: ignore
+  - name: public metrics API
+    interface: Metrics
+    action: api
+    reason: used by dashboards
+  - package: "**/rules/testdata"
+    method: Delete
+    action: warn
+  - generic: true
+    action: ignore
+`), 0o644))
+	cfg, err := config.LoadConfig(configPath)
+	assert.NoError(t, err)
+
+	res, lookup := analyze(t)
+	assert.Len(t, res.GenericWarnings, 1)
+
+	ignored := Apply(res, cfg, lookup)
+	findings := byMethod(res)
+
+	// Conn реализует io.Closer и не анализируется; убранные методы возвращаются с правилом
+	assert.NotContains(t, findings, "Conn.Close")
+	assert.NotContains(t, findings, "Conn.Flush")
+	for _, f := range ignored {
+		assert.Equal(t, "Conn", f.Interface)
+		assert.Equal(t, "ignore", f.Rule.Action)
+	}
+	assert.Len(t, ignored, 2)
+
+	inc := findings["Metrics.Inc"]
+	assert.Equal(t, results.VerdictUsed, inc.Verdict)
+	assert.Empty(t, inc.Fixes)
+	if assert.Len(t, inc.Evidence, 1) {
+		assert.Equal(t, results.EvidenceAPI, inc.Evidence[0].Kind)
+		assert.Equal(t, "public metrics API", inc.Evidence[0].Detail)
+	}
+	assert.Equal(t, &results.RuleMatch{
+		Rule:     "public metrics API",
+		Action:   "api",
+		Reason:   "used by dashboards",
+		Position: results.Position{File: configPath, Line: 4},
+	}, inc.Rule)
+
+	deleteMethod := findings["Repository.Delete"]
+	assert.Equal(t, results.VerdictUnused, deleteMethod.Verdict)
+	assert.True(t, deleteMethod.IsWarning())
+	assert.Equal(t, "package=**/rules/testdata, method=Delete", deleteMethod.Rule.Rule)
+
+	find := findings["Repository.Find"]
+	assert.Equal(t, results.VerdictUsed, find.Verdict)
+	assert.Nil(t, find.Rule)
+	assert.Empty(t, find.Severity)
+
+	assert.Empty(t, res.GenericWarnings)
+	assert.Equal(t, 1, res.Summary().Unused)
+	assert.Equal(t, 1, res.Summary().Warnings)
+}
+
+func TestLookup(t *testing.T) {
+	_, lookup := analyze(t)
+
+	pkgPath := "github.com/comerc/unused-interface-methods/pkg/rules/testdata"
+	assert.NotNil(t, lookup.Interface(pkgPath, "Conn"))
+	assert.NotNil(t, lookup.Interface("io", "Closer"))
+	assert.Nil(t, lookup.Interface(pkgPath, "find"))
+	assert.Nil(t, lookup.Interface("net/http", "Handler"))
+
+	implements := lookup.implements(pkgPath, "Conn")
+	assert.True(t, implements("io.Closer"))
+	assert.False(t, implements("io.Reader"))
+	assert.Nil(t, lookup.implements(pkgPath, "Missing"))
+}
+
+func TestApplySeverities(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), ".unused-interface-methods.yml")
+	assert.NoError(t, os.WriteFile(configPath, []byte(`severity:
+  unused-method: warning
+  generic-skipped: off
+  load-error: error
+`), 0o644))
+	cfg, err := config.LoadConfig(configPath)
+	assert.NoError(t, err)
+
+	res := &results.Result{
+		Findings: []results.Finding{
+			{Interface: "Repository", Method: "Save", Verdict: results.VerdictUnused},
+			{Interface: "Repository", Method: "Delete", Verdict: results.VerdictUnused, Severity: results.SeverityError},
+			{Interface: "Repository", Method: "Find", Verdict: results.VerdictUsed},
+		},
+		GenericWarnings: []results.GenericWarning{{Interface: "Store", MethodCount: 2}},
+		LoadErrors:      []results.LoadError{{PkgPath: "example.com/app", Message: "syntax error"}},
+	}
+	ApplySeverities(res, cfg)
+	findings := byMethod(res)
+
+	assert.Equal(t, results.SeverityWarning, findings["Repository.Save"].Level())
+	// Серьезность из правила error точнее и не переопределяется
+	assert.Equal(t, results.SeverityError, findings["Repository.Delete"].Level())
+	assert.Empty(t, findings["Repository.Find"].Severity)
+	assert.Empty(t, res.GenericWarnings)
+	assert.Equal(t, results.SeverityError, res.LoadErrors[0].Level())
+	assert.True(t, res.LoadFails(cfg.FailOnLevel()))
+}
@@ -0,0 +1,37 @@
+package rules_data
+
+import "io"
+
+// Кейс 1: Интерфейс реализует внешний io.Closer, Flush не используется
+type Conn interface {
+	Close() error
+	Flush() error // не используется
+}
+
+// Кейс 2: Публичный API для внешних потребителей, методы не используются в модуле
+type Metrics interface {
+	Inc(name string)
+	Observe(name string, value float64)
+}
+
+// Кейс 3: Внутренний интерфейс: Find используется, Delete нет
+type Repository interface {
+	Find(id string) (string, error) // используется
+	Delete(id string) error         // не используется
+}
+
+// Кейс 4: Дженерик-интерфейс
+type Pool[T any] interface {
+	Get() T
+}
+
+var repo Repository
+
+func find() (string, error) {
+	return repo.Find("id")
+}
+
+var (
+	_ io.Closer = Conn(nil)
+	_           = find
+)
@@ -1,16 +1,23 @@
 package stage0
 
 import (
+	"errors"
 	"fmt"
 	"go/ast"
 	"go/importer"
 	"go/parser"
+	"go/scanner"
 	"go/token"
 	"go/types"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+
+	"golang.org/x/mod/modfile"
 
 	"github.com/comerc/unused-interface-methods/pkg/config"
+	"github.com/comerc/unused-interface-methods/pkg/results"
 )
 
 // Package представляет пакет с его файлами и FileSet
@@ -18,68 +25,108 @@ type Package struct {
 	Fset  *token.FileSet
 	Files map[string]*ast.File
 	Info  *types.Info
+	Types *types.Package // nil, если проверка типов не удалась
+	// Ошибки разбора файлов пакета. Проверка типов здесь неполная: импортер не видит
+	// пакеты модуля, поэтому ее ошибки не считаются ошибками загрузки
+	Errors []results.LoadError
+}
+
+// newPackage создает пустой пакет с общим FileSet
+func newPackage(fset *token.FileSet) *Package {
+	return &Package{
+		Fset:  fset,
+		Files: make(map[string]*ast.File),
+		Info: &types.Info{
+			Types: make(map[ast.Expr]types.TypeAndValue),
+			Defs:  make(map[*ast.Ident]types.Object),
+			Uses:  make(map[*ast.Ident]types.Object),
+		},
+	}
 }
 
 // LoadProject загружает AST всего проекта в память
 func LoadProject(cfg *config.Config, verbose bool, pkgPath string) (map[string]*Package, error) {
+	return LoadProjectWithOverlay(cfg, verbose, pkgPath, nil)
+}
+
+// LoadProjectWithOverlay загружает проект, заменяя содержимое файлов из overlay
+// (абсолютный путь -> содержимое). Файлы overlay, которых нет на диске, добавляются
+func LoadProjectWithOverlay(cfg *config.Config, verbose bool, pkgPath string, overlay map[string][]byte) (map[string]*Package, error) {
 	pkgs := make(map[string]*Package)
 	fset := token.NewFileSet() // Один FileSet для всех файлов
 
-	// Обходим все файлы в проекте
-	err := filepath.WalkDir(pkgPath, func(path string, d os.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
+	// Модуль определяется для каждой директории: внутри могут быть вложенные модули
+	// монорепозитория, и пути их пакетов строятся от их go.mod
+	type module struct{ path, root string }
+	modules := make(map[string]module)
 
-		// Пропускаем файлы по конфигурации
-		if cfg.ShouldIgnore(path) {
+	// Файлы overlay, не найденные при обходе, добавляются после него
+	pending := make(map[string]bool, len(overlay))
+	for file := range overlay {
+		pending[file] = true
+	}
+
+	// addFile разбирает файл path и добавляет его в пакет своей директории
+	addFile := func(path string) {
+		// Получаем путь к пакету
+		dir := filepath.Dir(path)
+		mod, ok := modules[dir]
+		if !ok {
+			mod.path, mod.root = findModule(dir)
+			modules[dir] = mod
+		}
+		fullPkgPath, err := importPath(mod.path, mod.root, pkgPath, dir)
+		if err != nil {
 			if verbose {
-				fmt.Fprintf(os.Stderr, "DEBUG: пропускаем файл %s\n", path)
+				fmt.Fprintf(os.Stderr, "DEBUG: ошибка получения относительного пути: %v\n", err)
 			}
-			return nil
+			return
 		}
 
-		// Пропускаем директории и не-.go файлы
-		if d.IsDir() || filepath.Ext(path) != ".go" {
-			return nil
+		// Создаем пакет если его еще нет
+		if _, ok := pkgs[fullPkgPath]; !ok {
+			pkgs[fullPkgPath] = newPackage(fset)
 		}
 
-		// Парсим файл
-		file, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+		// Парсим файл; nil - чтение с диска
+		var src any
+		if abs, err := filepath.Abs(path); err == nil && overlay[abs] != nil {
+			src = overlay[abs]
+			delete(pending, abs)
+		}
+		file, err := parser.ParseFile(fset, path, src, parser.ParseComments)
 		if err != nil {
 			if verbose {
 				fmt.Fprintf(os.Stderr, "DEBUG: ошибка парсинга файла %s: %v\n", path, err)
 			}
-			return nil
+			pkgs[fullPkgPath].Errors = append(pkgs[fullPkgPath].Errors, parseError(fullPkgPath, err))
+			return
 		}
 
-		// Получаем путь к пакету
-		dir := filepath.Dir(path)
-		relDir, err := filepath.Rel(pkgPath, dir)
+		// Добавляем файл в пакет
+		pkgs[fullPkgPath].Files[path] = file
+	}
+
+	// Обходим все файлы в проекте
+	err := filepath.WalkDir(pkgPath, func(path string, d os.DirEntry, err error) error {
 		if err != nil {
+			return err
+		}
+
+		// Пропускаем файлы по конфигурации
+		if cfg.ShouldIgnore(path) {
 			if verbose {
-				fmt.Fprintf(os.Stderr, "DEBUG: ошибка получения относительного пути: %v\n", err)
+				fmt.Fprintf(os.Stderr, "DEBUG: пропускаем файл %s\n", path)
 			}
 			return nil
 		}
-		fullPkgPath := "github.com/comerc/unused-interface-methods/" + relDir
 
-		// Создаем пакет если его еще нет
-		if _, ok := pkgs[fullPkgPath]; !ok {
-			pkgs[fullPkgPath] = &Package{
-				Fset:  fset,
-				Files: make(map[string]*ast.File),
-				Info: &types.Info{
-					Types: make(map[ast.Expr]types.TypeAndValue),
-					Defs:  make(map[*ast.Ident]types.Object),
-					Uses:  make(map[*ast.Ident]types.Object),
-				},
-			}
+		// Пропускаем директории и не-.go файлы
+		if d.IsDir() || filepath.Ext(path) != ".go" {
+			return nil
 		}
 
-		// Добавляем файл в пакет
-		pkgs[fullPkgPath].Files[path] = file
-
+		addFile(path)
 		return nil
 	})
 
@@ -87,6 +134,23 @@ func LoadProject(cfg *config.Config, verbose bool, pkgPath string) (map[string]*
 		return nil, fmt.Errorf("ошибка обхода файлов: %v", err)
 	}
 
+	// Удаленные файлы есть только в overlay; пути строятся так же, как при обходе
+	root, err := filepath.Abs(pkgPath)
+	if err != nil {
+		return nil, err
+	}
+	for _, abs := range slices.Sorted(maps.Keys(pending)) {
+		rel, err := filepath.Rel(root, abs)
+		if err != nil || !filepath.IsLocal(rel) || filepath.Ext(abs) != ".go" {
+			continue
+		}
+		path := filepath.Join(pkgPath, rel)
+		if cfg.ShouldIgnore(path) {
+			continue
+		}
+		addFile(path)
+	}
+
 	// Анализируем типы для каждого пакета
 	for pkgPath, pkg := range pkgs {
 		// Собираем все файлы пакета в слайс для анализа
@@ -106,14 +170,76 @@ func LoadProject(cfg *config.Config, verbose bool, pkgPath string) (map[string]*
 		}
 
 		// Анализируем типы пакета
-		_, err := conf.Check(pkgPath, pkg.Fset, files, pkg.Info)
+		typesPkg, err := conf.Check(pkgPath, pkg.Fset, files, pkg.Info)
 		if err != nil {
 			if verbose {
 				fmt.Fprintf(os.Stderr, "DEBUG: ошибка проверки типов в пакете %s: %v\n", pkgPath, err)
 			}
 			continue // пропускаем пакет с ошибками
 		}
+		pkg.Types = typesPkg
 	}
 
 	return pkgs, nil
 }
+
+// parseError описывает ошибку разбора файла первой синтаксической ошибкой
+func parseError(pkgPath string, err error) results.LoadError {
+	loadErr := results.LoadError{PkgPath: pkgPath, Message: err.Error()}
+	var list scanner.ErrorList
+	if errors.As(err, &list) && len(list) > 0 {
+		loadErr.Message = list[0].Msg
+		loadErr.Position = results.NewPosition(list[0].Pos)
+	}
+	return loadErr
+}
+
+// findModule ищет go.mod вверх по дереву от dir и возвращает путь модуля и его корень.
+// Если go.mod не найден, возвращает пустые строки
+func findModule(dir string) (string, string) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", ""
+	}
+
+	for {
+		data, err := os.ReadFile(filepath.Join(absDir, "go.mod"))
+		if err == nil {
+			if modPath := modfile.ModulePath(data); modPath != "" {
+				return modPath, absDir
+			}
+			return "", ""
+		}
+
+		parent := filepath.Dir(absDir)
+		if parent == absDir {
+			return "", ""
+		}
+		absDir = parent
+	}
+}
+
+// importPath строит путь пакета для директории dir.
+// Без модуля путь считается относительно корня анализа root
+func importPath(modPath, modRoot, root, dir string) (string, error) {
+	if modPath == "" {
+		relDir, err := filepath.Rel(root, dir)
+		if err != nil {
+			return "", err
+		}
+		return filepath.ToSlash(relDir), nil
+	}
+
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	relDir, err := filepath.Rel(modRoot, absDir)
+	if err != nil {
+		return "", err
+	}
+	if relDir == "." {
+		return modPath, nil
+	}
+	return modPath + "/" + filepath.ToSlash(relDir), nil
+}
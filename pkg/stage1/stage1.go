@@ -1,13 +1,16 @@
 package stage1
 
 import (
+	"bytes"
 	"fmt"
 	"go/ast"
 	"go/importer"
+	"go/token"
 	"go/types"
 	"os"
 
 	"github.com/comerc/unused-interface-methods/pkg/config"
+	"github.com/comerc/unused-interface-methods/pkg/results"
 	"github.com/comerc/unused-interface-methods/pkg/stage0"
 )
 
@@ -17,6 +20,8 @@ type UsedMethod struct {
 	InterfaceName string           // имя интерфейса
 	MethodName    string           // имя метода
 	Signature     *types.Signature // сигнатура для точного определения
+	Method        *types.Func      // объявление метода в интерфейсе
+	CallPos       token.Pos        // позиция вызова
 }
 
 // checkMethodUsage проверяет использование метода интерфейса в вызове o.i.Method()
@@ -91,11 +96,17 @@ func checkMethodUsage(info *types.Info, n ast.Node, verbose bool) (*UsedMethod,
 		if method.Name() == sel.Sel.Name {
 			// Проверяем сигнатуру
 			if types.Identical(method.Type(), signature) {
-				return &UsedMethod{
+				usedMethod := &UsedMethod{
 					InterfaceName: interfaceName,
 					MethodName:    sel.Sel.Name,
 					Signature:     signature,
-				}, true
+					Method:        method,
+					CallPos:       call.Pos(),
+				}
+				if pkg := named.Obj().Pkg(); pkg != nil {
+					usedMethod.PkgPath = pkg.Path()
+				}
+				return usedMethod, true
 			}
 		}
 	}
@@ -104,8 +115,9 @@ func checkMethodUsage(info *types.Info, n ast.Node, verbose bool) (*UsedMethod,
 }
 
 // FindUsedMethods находит все точно используемые методы интерфейсов в пакете
-func FindUsedMethods(pkgs map[string]*stage0.Package, cfg *config.Config, verbose bool) (map[string][]*UsedMethod, error) {
-	usedMethods := make(map[string][]*UsedMethod) // pkgPath -> usedMethods
+func FindUsedMethods(pkgs map[string]*stage0.Package, cfg *config.Config, verbose bool) ([]results.Finding, error) {
+	var findings []results.Finding
+	index := make(map[string]int) // ID метода -> индекс в findings
 
 	for pkgPath, pkg := range pkgs {
 		if verbose {
@@ -164,19 +176,57 @@ func FindUsedMethods(pkgs map[string]*stage0.Package, cfg *config.Config, verbos
 					return true
 				}
 
-				method.PkgPath = pkgPath
+				if method.PkgPath == "" {
+					method.PkgPath = pkgPath
+				}
 
-				// Выводим результат в правильном формате
-				fmt.Printf("OK: github.com/comerc/unused-interface-methods/test/data.%s.%s\n",
-					method.InterfaceName,
-					method.MethodName,
-				)
+				evidence := results.Evidence{
+					Kind:     results.EvidenceCall,
+					Position: results.NewPosition(pkg.Fset.Position(method.CallPos)),
+				}
 
-				usedMethods[pkgPath] = append(usedMethods[pkgPath], method)
+				// Несколько вызовов одного метода объединяем в один результат
+				finding := newFinding(pkg.Fset, pkgPath, method)
+				if i, ok := index[finding.ID()]; ok {
+					findings[i].Evidence = append(findings[i].Evidence, evidence)
+					return true
+				}
+				finding.Evidence = []results.Evidence{evidence}
+				index[finding.ID()] = len(findings)
+				findings = append(findings, finding)
 				return true
 			})
 		}
 	}
 
-	return usedMethods, nil
+	return findings, nil
+}
+
+// newFinding преобразует используемый метод в результат анализа
+func newFinding(fset *token.FileSet, pkgPath string, method *UsedMethod) results.Finding {
+	finding := results.Finding{
+		PkgPath:   method.PkgPath,
+		Interface: method.InterfaceName,
+		Method:    method.MethodName,
+		Verdict:   results.VerdictUsed,
+		Engine:    results.EngineStage1,
+	}
+
+	qualifier := func(p *types.Package) string {
+		if p.Path() == method.PkgPath {
+			return ""
+		}
+		return p.Name()
+	}
+	var buf bytes.Buffer
+	types.WriteSignature(&buf, method.Signature, qualifier)
+	finding.Signature = buf.String()
+
+	// Позиции известны только для методов, объявленных в анализируемом пакете,
+	// у импортированных пакетов свой FileSet
+	if decl := method.Method; decl != nil && decl.Pkg() != nil && decl.Pkg().Path() == pkgPath {
+		finding.Range = results.NewRange(fset, decl.Pos(), decl.Pos()+token.Pos(len(decl.Name())))
+	}
+
+	return finding
 }
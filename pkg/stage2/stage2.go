@@ -1,23 +1,32 @@
 package stage2
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"go/ast"
 	"go/printer"
+	"go/token"
 	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
 
 	"github.com/comerc/unused-interface-methods/pkg/config"
+	"github.com/comerc/unused-interface-methods/pkg/results"
 	"github.com/comerc/unused-interface-methods/pkg/stage0"
-	"github.com/comerc/unused-interface-methods/pkg/stage1"
+	"github.com/comerc/unused-interface-methods/pkg/suppress"
 )
 
 // Method представляет метод интерфейса для проверки
 type Method struct {
-	InterfaceName string // имя интерфейса
-	MethodName    string // имя метода
+	InterfaceName string               // имя интерфейса
+	MethodName    string               // имя метода
+	Field         *ast.Field           // объявление метода в интерфейсе
+	Suppression   *results.Suppression // директива подавления, если она есть
 }
 
 // Interface представляет интерфейс с методами
@@ -47,7 +56,7 @@ func copyFile(src, dst string) error {
 	return nil
 }
 
-// copyProject копирует все .go файлы проекта во временную директорию
+// copyProject копирует .go файлы и файлы модулей проекта во временную директорию
 func copyProject(rootDir string, tmpDir string, cfg *config.Config) error {
 	return filepath.WalkDir(rootDir, func(path string, d os.DirEntry, err error) error {
 		if err != nil {
@@ -59,8 +68,8 @@ func copyProject(rootDir string, tmpDir string, cfg *config.Config) error {
 			return nil
 		}
 
-		// Пропускаем директории и не-.go файлы
-		if d.IsDir() || filepath.Ext(path) != ".go" {
+		// Пропускаем директории и не-.go файлы; go.mod и go.sum нужны staticcheck для сборки
+		if d.IsDir() || filepath.Ext(path) != ".go" && d.Name() != "go.mod" && d.Name() != "go.sum" {
 			return nil
 		}
 
@@ -85,11 +94,61 @@ func copyProject(rootDir string, tmpDir string, cfg *config.Config) error {
 	})
 }
 
+// writeOverlay записывает файлы overlay (абсолютный путь -> содержимое) поверх копии
+// проекта; файлы вне текущей директории не копируются
+func writeOverlay(tmpDir string, overlay map[string][]byte) error {
+	for file, data := range overlay {
+		rel := copyPath(file)
+		if !filepath.IsLocal(rel) {
+			continue
+		}
+		dst := filepath.Join(tmpDir, rel)
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			return fmt.Errorf("не удалось создать директорию: %v", err)
+		}
+		if err := os.WriteFile(dst, data, 0644); err != nil {
+			return fmt.Errorf("не удалось записать файл: %v", err)
+		}
+	}
+	return nil
+}
+
+// restoreFile возвращает в копию проекта исходное содержимое файла: из overlay или с диска
+func restoreFile(filePath, dst string, overlay map[string][]byte) error {
+	if abs, err := filepath.Abs(filePath); err == nil && overlay[abs] != nil {
+		return os.WriteFile(dst, overlay[abs], 0644)
+	}
+	return copyFile(filePath, dst)
+}
+
+// copyPath возвращает путь файла во временной копии проекта: относительно текущей
+// директории, которую копирует copyProject. Загрузчик пакетов выдает абсолютные пути
+func copyPath(filePath string) string {
+	if !filepath.IsAbs(filePath) {
+		return filePath
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return filePath
+	}
+	if rel, err := filepath.Rel(wd, filePath); err == nil {
+		return rel
+	}
+	return filePath
+}
+
 // findInterfaces находит все интерфейсы в файле
-func findInterfaces(file *ast.File) []*Interface {
+func findInterfaces(fset *token.FileSet, file *ast.File) []*Interface {
 	var interfaces []*Interface
 
+	fileSuppression := suppress.ForFile(fset, file)
+	var decl *ast.GenDecl
 	ast.Inspect(file, func(n ast.Node) bool {
+		if genDecl, ok := n.(*ast.GenDecl); ok {
+			decl = genDecl
+			return true
+		}
+
 		typeSpec, ok := n.(*ast.TypeSpec)
 		if !ok {
 			return true
@@ -103,6 +162,7 @@ func findInterfaces(file *ast.File) []*Interface {
 		iface := &Interface{
 			Name: typeSpec.Name.Name,
 		}
+		ifaceSuppression := suppress.Resolve(nil, suppress.ForInterface(fset, decl, typeSpec), fileSuppression)
 
 		// Собираем методы интерфейса
 		for _, field := range interfaceType.Methods.List {
@@ -113,6 +173,8 @@ func findInterfaces(file *ast.File) []*Interface {
 			method := &Method{
 				InterfaceName: iface.Name,
 				MethodName:    field.Names[0].Name,
+				Field:         field,
+				Suppression:   suppress.Resolve(suppress.ForMethod(fset, field), ifaceSuppression, nil),
 			}
 			iface.Methods = append(iface.Methods, method)
 		}
@@ -124,9 +186,23 @@ func findInterfaces(file *ast.File) []*Interface {
 	return interfaces
 }
 
-// removeMethod удаляет метод из интерфейса
-func removeMethod(file *ast.File, interfaceName, methodName string) bool {
-	var removed bool
+// CollectSuppressions собирает директивы подавления из всех проверяемых файлов
+func CollectSuppressions(pkgs map[string]*stage0.Package, cfg *config.Config) []results.Suppression {
+	var directives []results.Suppression
+	for _, pkg := range pkgs {
+		for filePath, file := range pkg.Files {
+			if cfg.ShouldIgnore(filePath) {
+				continue
+			}
+			directives = append(directives, suppress.Collect(pkg.Fset, file)...)
+		}
+	}
+	return directives
+}
+
+// removeMethod удаляет метод из интерфейса и возвращает функцию, которая возвращает его
+// на место: AST общий для всех проверок. nil - метод не найден
+func removeMethod(file *ast.File, interfaceName, methodName string) (restore func()) {
 	ast.Inspect(file, func(n ast.Node) bool {
 		// Ищем объявление интерфейса
 		typeSpec, ok := n.(*ast.TypeSpec)
@@ -140,27 +216,25 @@ func removeMethod(file *ast.File, interfaceName, methodName string) bool {
 		}
 
 		// Фильтруем методы, удаляя указанный
+		original := interfaceType.Methods.List
 		var methods []*ast.Field
-		for _, method := range interfaceType.Methods.List {
+		for _, method := range original {
 			// Пропускаем встроенные интерфейсы (без имен)
-			if len(method.Names) == 0 {
-				methods = append(methods, method)
-				continue
-			}
-			if method.Names[0].Name != methodName {
+			if len(method.Names) == 0 || method.Names[0].Name != methodName {
 				methods = append(methods, method)
-			} else {
-				removed = true
 			}
 		}
-		interfaceType.Methods.List = methods
+		if len(methods) < len(original) {
+			interfaceType.Methods.List = methods
+			restore = func() { interfaceType.Methods.List = original }
+		}
 		return false
 	})
-	return removed
+	return restore
 }
 
-// runStaticcheck запускает staticcheck на временной копии проекта
-func runStaticcheck(tmpDir string, verbose bool) error {
+// runStaticcheck запускает staticcheck на временной копии проекта и возвращает его вывод
+func runStaticcheck(tmpDir string, verbose bool) (string, error) {
 	cmd := exec.Command("staticcheck", "./...")
 	cmd.Dir = tmpDir
 	output, err := cmd.CombinedOutput()
@@ -168,20 +242,64 @@ func runStaticcheck(tmpDir string, verbose bool) error {
 		if verbose {
 			fmt.Fprintf(os.Stderr, "DEBUG: ошибка запуска staticcheck: %v\n%s\n", err, output)
 		}
-		return fmt.Errorf("ошибка staticcheck: %v", err)
+		return string(output), fmt.Errorf("ошибка staticcheck: %w", err)
 	}
-	return nil
+	return string(output), nil
+}
+
+// compileErrorPattern - ошибка сборки или проверки типов в выводе staticcheck
+var compileErrorPattern = regexp.MustCompile(`^(.+?):\d+:\d+: (.*) \(compile\)$`)
+
+// compileErrors возвращает ошибки сборки из вывода staticcheck по ключу без строки
+// и колонки (удаление метода сдвигает строки файла) вместе с исходными строками вывода
+func compileErrors(output string) map[string]string {
+	errs := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		if m := compileErrorPattern.FindStringSubmatch(line); m != nil {
+			errs[m[1]+": "+m[2]] = line
+		}
+	}
+	return errs
+}
+
+// newCompileErrors возвращает строки вывода с ошибками сборки, которых не было в known
+func newCompileErrors(output string, known map[string]string) []string {
+	var introduced []string
+	for key, line := range compileErrors(output) {
+		if _, ok := known[key]; !ok {
+			introduced = append(introduced, line)
+		}
+	}
+	sort.Strings(introduced)
+	return introduced
+}
+
+// methodSignature возвращает сигнатуру метода без ключевого слова func
+func methodSignature(fset *token.FileSet, field *ast.Field) string {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fset, field.Type); err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(buf.String(), "func")
 }
 
-// FindUnusedMethods проверяет методы интерфейсов через staticcheck
-func FindUnusedMethods(pkgs map[string]*stage0.Package, usedMethodsByPkg map[string][]*stage1.UsedMethod, cfg *config.Config, verbose bool) error {
+// FindUnusedMethods проверяет методы интерфейсов через staticcheck.
+// Возвращает методы, признанные неиспользуемыми, и методы, без которых проект
+// не собирается: новые ошибки сборки сохраняются в доказательстве
+func FindUnusedMethods(pkgs map[string]*stage0.Package, usedMethods []results.Finding, cfg *config.Config, verbose bool) ([]results.Finding, error) {
+	return FindUnusedMethodsWithOverlay(pkgs, usedMethods, cfg, verbose, nil)
+}
+
+// FindUnusedMethodsWithOverlay проверяет методы в копии проекта, где содержимое файлов
+// заменено файлами из overlay, как в stage0.LoadProjectWithOverlay
+func FindUnusedMethodsWithOverlay(pkgs map[string]*stage0.Package, usedMethods []results.Finding, cfg *config.Config, verbose bool, overlay map[string][]byte) ([]results.Finding, error) {
 	// Создаем временную директорию для проверки
 	tmpDir, err := os.MkdirTemp("", "interface-linter-*")
 	if err != nil {
 		if verbose {
 			fmt.Fprintf(os.Stderr, "DEBUG: не удалось создать временную директорию: %v\n", err)
 		}
-		return fmt.Errorf("не удалось создать временную директорию: %v", err)
+		return nil, fmt.Errorf("не удалось создать временную директорию: %v", err)
 	}
 	defer os.RemoveAll(tmpDir)
 
@@ -190,19 +308,26 @@ func FindUnusedMethods(pkgs map[string]*stage0.Package, usedMethodsByPkg map[str
 		if verbose {
 			fmt.Fprintf(os.Stderr, "DEBUG: не удалось скопировать проект: %v\n", err)
 		}
-		return fmt.Errorf("не удалось скопировать проект: %v", err)
+		return nil, fmt.Errorf("не удалось скопировать проект: %v", err)
+	}
+	if err := writeOverlay(tmpDir, overlay); err != nil {
+		return nil, err
+	}
+
+	// Проблемы, которые были в проекте до удаления методов, ничего не доказывают
+	baseline, _ := runStaticcheck(tmpDir, verbose)
+	known := compileErrors(baseline)
+
+	// Методы, использование которых уже доказано в stage1
+	usedMethodsMap := make(map[string]bool)
+	for _, m := range usedMethods {
+		usedMethodsMap[m.ID()] = true
 	}
 
+	var checked []results.Finding
+
 	// Для каждого пакета
 	for pkgPath, pkg := range pkgs {
-		// Получаем используемые методы пакета
-		usedMethods := usedMethodsByPkg[pkgPath]
-		usedMethodsMap := make(map[string]bool)
-		for _, m := range usedMethods {
-			key := fmt.Sprintf("%s.%s", m.InterfaceName, m.MethodName)
-			usedMethodsMap[key] = true
-		}
-
 		// Для каждого файла в пакете
 		for filePath, file := range pkg.Files {
 			// Пропускаем файлы по конфигурации
@@ -214,45 +339,73 @@ func FindUnusedMethods(pkgs map[string]*stage0.Package, usedMethodsByPkg map[str
 			}
 
 			// Находим все интерфейсы в файле
-			interfaces := findInterfaces(file)
+			interfaces := findInterfaces(pkg.Fset, file)
 
 			// Проверяем каждый метод каждого интерфейса
 			for _, iface := range interfaces {
 				for _, method := range iface.Methods {
-					key := fmt.Sprintf("%s.%s", method.InterfaceName, method.MethodName)
-					if !usedMethodsMap[key] {
+					finding := results.Finding{
+						PkgPath:     pkgPath,
+						Interface:   method.InterfaceName,
+						Method:      method.MethodName,
+						Signature:   methodSignature(pkg.Fset, method.Field),
+						Range:       results.NewRange(pkg.Fset, method.Field.Names[0].Pos(), method.Field.Names[0].End()),
+						Verdict:     results.VerdictUnused,
+						Engine:      results.EngineStage2,
+						Suppression: method.Suppression,
+					}
+					if !usedMethodsMap[finding.ID()] {
 						// Метод не найден в stage1, проверяем через staticcheck
 						// Удаляем метод из интерфейса во временной копии
-						tmpFilePath := filepath.Join(tmpDir, filePath)
-						if removeMethod(file, method.InterfaceName, method.MethodName) {
+						tmpFilePath := filepath.Join(tmpDir, copyPath(filePath))
+						if restore := removeMethod(file, method.InterfaceName, method.MethodName); restore != nil {
 							// Записываем измененный файл
 							f, err := os.Create(tmpFilePath)
 							if err != nil {
+								restore()
 								if verbose {
 									fmt.Fprintf(os.Stderr, "DEBUG: не удалось создать временный файл: %v\n", err)
 								}
 								continue
 							}
-							if err := printer.Fprint(f, pkg.Fset, file); err != nil {
-								f.Close()
+							err = printer.Fprint(f, pkg.Fset, file)
+							f.Close()
+							restore()
+							if err != nil {
 								if verbose {
 									fmt.Fprintf(os.Stderr, "DEBUG: не удалось записать AST: %v\n", err)
 								}
 								continue
 							}
-							f.Close()
 
 							// Запускаем staticcheck для проверки
-							if err := runStaticcheck(tmpDir, verbose); err == nil {
-								// Если staticcheck прошел без ошибок, значит метод действительно не используется
-								fmt.Printf("UNUSED: github.com/comerc/unused-interface-methods/test/data.%s.%s\n",
-									method.InterfaceName,
-									method.MethodName,
-								)
+							output, err := runStaticcheck(tmpDir, verbose)
+							var exitErr *exec.ExitError
+							introduced := newCompileErrors(output, known)
+							switch {
+							case err != nil && !errors.As(err, &exitErr):
+								// staticcheck не запустился: метод не проверен
+							case len(introduced) > 0:
+								// Ошибки сборки без метода доказывают, что он нужен
+								finding.Verdict = results.VerdictUsed
+								finding.Evidence = []results.Evidence{{
+									Kind:   results.EvidenceVerifier,
+									Detail: "build failed without the method:\n" + strings.Join(introduced, "\n"),
+								}}
+								checked = append(checked, finding)
+							default:
+								// Без новых ошибок сборки метод не используется; замечания staticcheck
+								// к остальному коду на вердикт не влияют
+								detail := "staticcheck passed without the method"
+								if err != nil {
+									detail = "no new build errors without the method"
+								}
+								finding.Evidence = []results.Evidence{{Kind: results.EvidenceVerifier, Detail: detail}}
+								checked = append(checked, finding)
 							}
 
 							// Восстанавливаем файл
-							if err := copyFile(filePath, tmpFilePath); err != nil {
+							if err := restoreFile(filePath, tmpFilePath, overlay); err != nil {
 								if verbose {
 									fmt.Fprintf(os.Stderr, "DEBUG: не удалось восстановить файл: %v\n", err)
 								}
@@ -265,5 +418,5 @@ func FindUnusedMethods(pkgs map[string]*stage0.Package, usedMethodsByPkg map[str
 		}
 	}
 
-	return nil
+	return checked, nil
 }
@@ -1 +1,54 @@
 package stage2
+
+import (
+	"bytes"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemoveMethod(t *testing.T) {
+	const src = `package app
+
+type Plugin interface {
+	Name() string
+	Stop()
+}
+`
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "plugin.go", src, parser.ParseComments)
+	assert.NoError(t, err)
+	print := func() string {
+		var buf bytes.Buffer
+		assert.NoError(t, printer.Fprint(&buf, fset, file))
+		return buf.String()
+	}
+
+	assert.Nil(t, removeMethod(file, "Plugin", "Start"))
+	restore := removeMethod(file, "Plugin", "Stop")
+	if assert.NotNil(t, restore) {
+		assert.NotContains(t, print(), "Stop()")
+		// AST общий для всех проверок: следующий метод удаляется из исходного интерфейса
+		restore()
+		assert.Equal(t, src, print())
+	}
+}
+
+func TestNewCompileErrors(t *testing.T) {
+	// До удаления: замечание staticcheck и ошибка сборки, которая была в проекте всегда
+	known := compileErrors("-: # example.com/app\n" +
+		"./old.go:7:2: undefined: missing (compile)\n" +
+		"a.go:3:6: func unused is unused (U1000)\n")
+	// После удаления строки сдвинулись, замечание осталось, появилась новая ошибка
+	output := "-: # example.com/app\n" +
+		"./old.go:6:2: undefined: missing (compile)\n" +
+		"./a.go:5:29: p.Start undefined (type P has no field or method Start) (compile)\n" +
+		"a.go:3:6: func unused is unused (U1000)\n"
+
+	assert.Equal(t, []string{"./a.go:5:29: p.Start undefined (type P has no field or method Start) (compile)"},
+		newCompileErrors(output, known))
+	assert.Empty(t, newCompileErrors("a.go:3:6: func unused is unused (U1000)\n", known), "замечания staticcheck не доказывают использование")
+}
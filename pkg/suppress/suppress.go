@@ -0,0 +1,234 @@
+package suppress
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strings"
+	"time"
+
+	"github.com/comerc/unused-interface-methods/pkg/config"
+	"github.com/comerc/unused-interface-methods/pkg/results"
+)
+
+const (
+	// IgnoreDirective - собственная директива линтера: //unused-interface-methods:ignore <reason>
+	IgnoreDirective = "unused-interface-methods:ignore"
+	// NolintName - имя линтера в директивах //nolint для совместимости с golangci-lint
+	NolintName = "unusedinterfacemethods"
+)
+
+// ForFile возвращает директиву уровня файла: она должна стоять до строки package
+func ForFile(fset *token.FileSet, file *ast.File) *results.Suppression {
+	for _, group := range file.Comments {
+		if group.Pos() >= file.Package {
+			break
+		}
+		if s := find(fset, results.ScopeFile, group); s != nil {
+			return s
+		}
+	}
+	return nil
+}
+
+// ForInterface возвращает директиву на объявлении интерфейса.
+// Учитываются комментарии type-декларации и самой спецификации типа
+func ForInterface(fset *token.FileSet, decl *ast.GenDecl, spec *ast.TypeSpec) *results.Suppression {
+	var groups []*ast.CommentGroup
+	if decl != nil {
+		groups = append(groups, decl.Doc)
+	}
+	groups = append(groups, spec.Doc, spec.Comment)
+	return find(fset, results.ScopeInterface, groups...)
+}
+
+// ForMethod возвращает директиву на методе интерфейса: над ним или в конце строки
+func ForMethod(fset *token.FileSet, field *ast.Field) *results.Suppression {
+	return find(fset, results.ScopeMethod, field.Doc, field.Comment)
+}
+
+// Resolve выбирает наиболее точную директиву: метод, затем интерфейс, затем файл
+func Resolve(method, iface, file *results.Suppression) *results.Suppression {
+	switch {
+	case method != nil:
+		return method
+	case iface != nil:
+		return iface
+	default:
+		return file
+	}
+}
+
+// Collect возвращает все директивы линтера в файле, включая не привязанные к интерфейсам.
+// Общие //nolint и //nolint:all относятся и к другим линтерам, поэтому не собираются
+func Collect(fset *token.FileSet, file *ast.File) []results.Suppression {
+	var directives []results.Suppression
+	for _, group := range file.Comments {
+		for _, c := range group.List {
+			directive, reason, ok := Parse(c.Text)
+			if !ok || (directive != IgnoreDirective && directive != "nolint:"+NolintName) {
+				continue
+			}
+			scope := results.SuppressionScope("")
+			if c.Slash < file.Package {
+				scope = results.ScopeFile
+			}
+			directives = append(directives, results.Suppression{
+				Scope:     scope,
+				Directive: directive,
+				Reason:    reason,
+				Position:  results.NewPosition(fset.Position(c.Slash)),
+			})
+		}
+	}
+	return directives
+}
+
+// Stale возвращает директивы, которые не подавили ни одного неиспользуемого метода
+func Stale(directives []results.Suppression, findings []results.Finding) []results.StaleSuppression {
+	used := make(map[results.Position]bool)
+	for _, f := range findings {
+		if f.Verdict == results.VerdictUnused && f.Suppression != nil {
+			used[f.Suppression.Position] = true
+		}
+	}
+
+	var stale []results.StaleSuppression
+	for _, d := range directives {
+		if used[d.Position] {
+			continue
+		}
+		used[d.Position] = true // директива из нескольких конфигураций сборки выводится один раз
+		stale = append(stale, results.StaleSuppression{
+			Kind:     results.StaleDirective,
+			Rule:     d.Directive,
+			Position: d.Position,
+		})
+	}
+	return stale
+}
+
+// StaleIgnoreRules преобразует несработавшие паттерны ignore в устаревшие подавления
+func StaleIgnoreRules(rules []config.IgnoreRule) []results.StaleSuppression {
+	var stale []results.StaleSuppression
+	for _, rule := range rules {
+		stale = append(stale, results.StaleSuppression{
+			Kind:     results.StaleIgnoreRule,
+			Rule:     rule.Pattern,
+			Position: results.Position{File: rule.File, Line: rule.Line},
+		})
+	}
+	return stale
+}
+
+// ApplyExcludes подавляет неиспользуемые методы записями exclude из конфигурации.
+// К находке применяются записи файлов конфигурации, действующих на ее файл.
+// Директивы в коде точнее, поэтому уже подавленные находки не затрагиваются.
+// Записи, не подавившие ни одного метода, возвращаются как устаревшие
+func ApplyExcludes(res *results.Result, cfg *config.Config, now time.Time) []results.StaleSuppression {
+	matched := make(map[*config.ExcludeRule]bool)
+	for i := range res.Findings {
+		f := &res.Findings[i]
+		if f.Verdict != results.VerdictUnused || f.Suppression != nil {
+			continue
+		}
+		for _, rule := range cfg.ExcludesFor(f.Range.Start.File, now) {
+			if !rule.Matches(f.PkgPath, f.Interface, f.Method) {
+				continue
+			}
+			matched[rule] = true
+			f.Suppression = &results.Suppression{
+				Scope:     results.ScopeConfig,
+				Directive: rule.String(),
+				Reason:    excludeReason(rule),
+				Position:  results.Position{File: rule.File, Line: rule.Line},
+			}
+			break
+		}
+	}
+
+	var stale []results.StaleSuppression
+	for _, rule := range cfg.ActiveExcludes(now) {
+		if matched[rule] {
+			continue
+		}
+		stale = append(stale, results.StaleSuppression{
+			Kind:     results.StaleExcludeRule,
+			Rule:     rule.String(),
+			Position: results.Position{File: rule.File, Line: rule.Line},
+		})
+	}
+	return stale
+}
+
+// excludeReason объединяет причину и владельца записи exclude
+func excludeReason(rule *config.ExcludeRule) string {
+	switch {
+	case rule.Owner == "":
+		return rule.Reason
+	case rule.Reason == "":
+		return "owner: " + rule.Owner
+	default:
+		return fmt.Sprintf("%s (owner: %s)", rule.Reason, rule.Owner)
+	}
+}
+
+// find ищет первую директиву подавления в группах комментариев
+func find(fset *token.FileSet, scope results.SuppressionScope, groups ...*ast.CommentGroup) *results.Suppression {
+	for _, group := range groups {
+		if group == nil {
+			continue
+		}
+		for _, c := range group.List {
+			directive, reason, ok := Parse(c.Text)
+			if !ok {
+				continue
+			}
+			return &results.Suppression{
+				Scope:     scope,
+				Directive: directive,
+				Reason:    reason,
+				Position:  results.NewPosition(fset.Position(c.Slash)),
+			}
+		}
+	}
+	return nil
+}
+
+// Parse разбирает текст одного комментария и возвращает директиву и причину.
+// Как и другие директивы Go, комментарий должен начинаться с "//" без пробела
+func Parse(text string) (directive, reason string, ok bool) {
+	if !strings.HasPrefix(text, "//") {
+		return "", "", false
+	}
+	text = text[2:]
+
+	if rest, found := strings.CutPrefix(text, IgnoreDirective); found {
+		if rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+			return "", "", false
+		}
+		return IgnoreDirective, strings.TrimSpace(rest), true
+	}
+
+	if rest, found := strings.CutPrefix(text, "nolint"); found {
+		// Пояснение golangci-lint пишется после вложенного "//"
+		if i := strings.Index(rest, "//"); i >= 0 {
+			reason = strings.TrimSpace(rest[i+2:])
+			rest = rest[:i]
+		}
+		rest = strings.TrimRight(rest, " \t")
+		switch {
+		case rest == "":
+			return "nolint", reason, true
+		case rest[0] == ':':
+			for _, name := range strings.Split(rest[1:], ",") {
+				name = strings.TrimSpace(name)
+				if name == NolintName || name == "all" {
+					return "nolint:" + name, reason, true
+				}
+			}
+		}
+	}
+
+	return "", "", false
+}
@@ -0,0 +1,210 @@
+package suppress
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/comerc/unused-interface-methods/pkg/config"
+	"github.com/comerc/unused-interface-methods/pkg/results"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		text      string
+		directive string
+		reason    string
+		ok        bool
+	}{
+		{"//unused-interface-methods:ignore", IgnoreDirective, "", true},
+		{"//unused-interface-methods:ignore used via reflection", IgnoreDirective, "used via reflection", true},
+		{"//unused-interface-methods:ignored", "", "", false},
+		{"// unused-interface-methods:ignore", "", "", false},
+		{"//nolint", "nolint", "", true},
+		{"//nolint:unusedinterfacemethods", "nolint:unusedinterfacemethods", "", true},
+		{"//nolint:errcheck,unusedinterfacemethods // public API", "nolint:unusedinterfacemethods", "public API", true},
+		{"//nolint:all", "nolint:all", "", true},
+		{"//nolint:errcheck", "", "", false},
+		{"/* nolint */", "", "", false},
+		{"// обычный комментарий", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			directive, reason, ok := Parse(tt.text)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.directive, directive)
+			assert.Equal(t, tt.reason, reason)
+		})
+	}
+}
+
+// interfaces возвращает объявления интерфейсов файла вместе с их type-декларациями
+func interfaces(file *ast.File) (map[string]*ast.TypeSpec, map[string]*ast.GenDecl) {
+	specs := make(map[string]*ast.TypeSpec)
+	decls := make(map[string]*ast.GenDecl)
+	for _, d := range file.Decls {
+		decl, ok := d.(*ast.GenDecl)
+		if !ok {
+			continue
+		}
+		for _, s := range decl.Specs {
+			if spec, ok := s.(*ast.TypeSpec); ok {
+				specs[spec.Name.Name] = spec
+				decls[spec.Name.Name] = decl
+			}
+		}
+	}
+	return specs, decls
+}
+
+// methods возвращает поля методов интерфейса по именам
+func methods(spec *ast.TypeSpec) map[string]*ast.Field {
+	fields := make(map[string]*ast.Field)
+	for _, field := range spec.Type.(*ast.InterfaceType).Methods.List {
+		fields[field.Names[0].Name] = field
+	}
+	return fields
+}
+
+func TestDirectives(t *testing.T) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "testdata/suppress.go", nil, parser.ParseComments)
+	assert.NoError(t, err)
+	assert.Nil(t, ForFile(fset, file))
+
+	specs, decls := interfaces(file)
+
+	storage := methods(specs["Storage"])
+	store := ForMethod(fset, storage["Store"])
+	if assert.NotNil(t, store) {
+		assert.Equal(t, results.ScopeMethod, store.Scope)
+		assert.Equal(t, IgnoreDirective, store.Directive)
+		assert.Equal(t, "used by plugins via reflection", store.Reason)
+		assert.Equal(t, 6, store.Position.Line)
+	}
+	purge := ForMethod(fset, storage["Purge"])
+	if assert.NotNil(t, purge) {
+		assert.Equal(t, "nolint:unusedinterfacemethods", purge.Directive)
+		assert.Equal(t, "kept for API compatibility", purge.Reason)
+	}
+	assert.Nil(t, ForMethod(fset, storage["Compact"]))
+	assert.Nil(t, ForInterface(fset, decls["Storage"], specs["Storage"]))
+
+	notifier := ForInterface(fset, decls["Notifier"], specs["Notifier"])
+	if assert.NotNil(t, notifier) {
+		assert.Equal(t, results.ScopeInterface, notifier.Scope)
+		assert.Equal(t, "nolint:unusedinterfacemethods", notifier.Directive)
+	}
+
+	cache := methods(specs["Cache"])
+	assert.Nil(t, ForMethod(fset, cache["Set"]))
+	assert.Nil(t, ForMethod(fset, cache["Delete"]))
+}
+
+func TestForFile(t *testing.T) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "testdata/legacy.go", nil, parser.ParseComments)
+	assert.NoError(t, err)
+
+	s := ForFile(fset, file)
+	if assert.NotNil(t, s) {
+		assert.Equal(t, results.ScopeFile, s.Scope)
+		assert.Equal(t, "legacy API, cleanup planned", s.Reason)
+		assert.Equal(t, 1, s.Position.Line)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	method := &results.Suppression{Scope: results.ScopeMethod}
+	iface := &results.Suppression{Scope: results.ScopeInterface}
+	file := &results.Suppression{Scope: results.ScopeFile}
+
+	assert.Same(t, method, Resolve(method, iface, file))
+	assert.Same(t, iface, Resolve(nil, iface, file))
+	assert.Same(t, file, Resolve(nil, nil, file))
+	assert.Nil(t, Resolve(nil, nil, nil))
+}
+
+func TestCollectAndStale(t *testing.T) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "testdata/stale.go", nil, parser.ParseComments)
+	assert.NoError(t, err)
+
+	directives := Collect(fset, file)
+	var lines []int
+	for _, d := range directives {
+		lines = append(lines, d.Position.Line)
+	}
+	assert.Equal(t, []int{5, 15, 20}, lines)
+
+	// Директива, подавившая неиспользуемый метод, не устарела
+	findings := []results.Finding{
+		{Interface: "Queue", Method: "Push", Verdict: results.VerdictUnused, Suppression: &directives[2]},
+		{Interface: "Publisher", Method: "Publish", Verdict: results.VerdictUsed, Suppression: &directives[0]},
+	}
+	stale := Stale(directives, findings)
+	if assert.Len(t, stale, 2) {
+		assert.Equal(t, results.StaleSuppression{
+			Kind:     results.StaleDirective,
+			Rule:     IgnoreDirective,
+			Position: directives[0].Position,
+		}, stale[0])
+		assert.Equal(t, "nolint:unusedinterfacemethods", stale[1].Rule)
+	}
+}
+
+func TestStaleIgnoreRules(t *testing.T) {
+	stale := StaleIgnoreRules([]config.IgnoreRule{{Pattern: "vendor/**", File: "/repo/.unused-interface-methods.yml", Line: 2}})
+	assert.Equal(t, []results.StaleSuppression{{
+		Kind:     results.StaleIgnoreRule,
+		Rule:     "vendor/**",
+		Position: results.Position{File: "/repo/.unused-interface-methods.yml", Line: 2},
+	}}, stale)
+}
+
+func TestApplyExcludes(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), ".unused-interface-methods.yml")
+	assert.NoError(t, os.WriteFile(configPath, []byte(`exclude:
+  - interface: Storage
+    method: Compact
+    reason: removed in v2
+    owner: "@storage-team"
+  - package: "example.com/**"
+    reason: nothing matches
+`), 0o644))
+	cfg, err := config.LoadConfig(configPath)
+	assert.NoError(t, err)
+
+	directive := &results.Suppression{Scope: results.ScopeMethod, Directive: IgnoreDirective}
+	res := &results.Result{Findings: []results.Finding{
+		{PkgPath: "suppress_data", Interface: "Storage", Method: "Compact", Verdict: results.VerdictUnused},
+		{PkgPath: "suppress_data", Interface: "Storage", Method: "Store", Verdict: results.VerdictUnused, Suppression: directive},
+		{PkgPath: "suppress_data", Interface: "Cache", Method: "Set", Verdict: results.VerdictUnused},
+	}}
+
+	stale := ApplyExcludes(res, cfg, time.Now())
+
+	compact := res.Findings[0].Suppression
+	if assert.NotNil(t, compact) {
+		assert.Equal(t, results.ScopeConfig, compact.Scope)
+		assert.Equal(t, "interface=Storage, method=Compact", compact.Directive)
+		assert.Equal(t, "removed in v2 (owner: @storage-team)", compact.Reason)
+		assert.Equal(t, results.Position{File: configPath, Line: 2}, compact.Position)
+	}
+	// Директива в коде точнее записи exclude
+	assert.Same(t, directive, res.Findings[1].Suppression)
+	assert.Nil(t, res.Findings[2].Suppression)
+
+	assert.Equal(t, []results.StaleSuppression{{
+		Kind:     results.StaleExcludeRule,
+		Rule:     "package=example.com/**",
+		Position: results.Position{File: configPath, Line: 6},
+	}}, stale)
+}
@@ -0,0 +1,9 @@
+//unused-interface-methods:ignore legacy API, cleanup planned
+
+package suppress_data
+
+// Кейс 4: Директива уровня файла
+type LegacyClient interface {
+	Connect(addr string) error
+	Disconnect() error
+}
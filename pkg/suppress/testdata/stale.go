@@ -0,0 +1,27 @@
+package suppress_data
+
+// Кейс 5: Директива на используемом методе устарела
+type Publisher interface {
+	//unused-interface-methods:ignore no longer needed
+	Publish(topic string) error // используется
+}
+
+func publish(p Publisher) error {
+	return p.Publish("topic")
+}
+
+// Кейс 6: Директива вне интерфейса ничего не подавляет
+//
+//nolint:unusedinterfacemethods
+func helper() {}
+
+// Кейс 7: Директивы на дженерик-интерфейсах не проверяются
+type Queue[T any] interface {
+	//unused-interface-methods:ignore generic interfaces are not analyzed
+	Push(item T)
+}
+
+var (
+	_ = publish
+	_ = helper
+)
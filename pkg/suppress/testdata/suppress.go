@@ -0,0 +1,25 @@
+// Пакет с директивами подавления находок
+package suppress_data
+
+// Кейс 1: Директивы на методах
+type Storage interface {
+	//unused-interface-methods:ignore used by plugins via reflection
+	Store(key string, value []byte) error
+	Purge() error   //nolint:unusedinterfacemethods // kept for API compatibility
+	Compact() error // не используется
+}
+
+// Кейс 2: Директива на интерфейсе целиком
+//
+//nolint:unusedinterfacemethods,errcheck
+type Notifier interface {
+	Notify(msg string) error
+	Close() error
+}
+
+// Кейс 3: Директивы других линтеров и комментарии с пробелом не подавляют находки
+type Cache interface {
+	Set(key string, value []byte) //nolint:errcheck
+	// unused-interface-methods:ignore с пробелом не является директивой
+	Delete(key string)
+}
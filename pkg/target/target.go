@@ -0,0 +1,272 @@
+package target
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+
+	"github.com/comerc/unused-interface-methods/pkg/config"
+	"github.com/comerc/unused-interface-methods/pkg/results"
+)
+
+// Target - выбранный код: интерфейсы проверяются только в нем,
+// а использования ищутся во всем модуле
+type Target struct {
+	Root  string          // корень модуля, код которого загружается для поиска использований
+	dirs  []dir           // выбранные директории
+	files map[string]bool // выбранные файлы по абсолютному пути
+	first string          // директория первого выбранного пакета или файла: по ней выбирается Root
+}
+
+// dir - директория пакета или, для паттерна "/...", поддерево
+type dir struct {
+	path      string
+	recursive bool
+}
+
+// Select выбирает код по аргументам командной строки или, если задан filesFrom,
+// по списку файлов из файла или стандартного ввода ("-")
+func Select(args []string, filesFrom string, stdin io.Reader) (*Target, error) {
+	if filesFrom == "" {
+		return FromArgs(args)
+	}
+	if len(args) > 0 {
+		return nil, errors.New("-files-from and package patterns are mutually exclusive")
+	}
+	if filesFrom == "-" {
+		return FromFiles(stdin)
+	}
+	f, err := os.Open(filesFrom)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return FromFiles(f)
+}
+
+// FromArgs выбирает код по аргументам командной строки: паттернам пакетов
+// (./pkg/..., ./cmd/api, example.com/app/...) и Go-файлам. Без аргументов и для
+// единственной директории выбирается все ее дерево, как в прежних версиях; среди
+// нескольких аргументов директория - один пакет, как в go. Пути импорта и Root
+// отсчитываются от модуля первого аргумента-пути, а без таких аргументов - от текущей директории
+func FromArgs(args []string) (*Target, error) {
+	if len(args) == 0 {
+		args = []string{"."}
+	}
+
+	anchor := "."
+	for _, arg := range args {
+		if strings.HasSuffix(arg, ".go") && !isDir(arg) {
+			anchor = filepath.Dir(arg)
+			break
+		}
+		if base := strings.TrimSuffix(arg, "/..."); isLocal(base) || len(args) == 1 && isDir(base) {
+			anchor = base
+			break
+		}
+	}
+	modRoot := config.ModuleRoot(anchor)
+
+	t := &Target{files: make(map[string]bool)}
+	for _, arg := range args {
+		if strings.HasSuffix(arg, ".go") && !isDir(arg) {
+			t.addFile(arg)
+			continue
+		}
+		if len(args) == 1 && !strings.Contains(arg, "...") && isDir(arg) {
+			arg = absPath(arg) + "/..." // путь без "./" - тоже директория, а не путь импорта
+		}
+		d, err := resolve(arg, modRoot)
+		if err != nil {
+			return nil, err
+		}
+		t.addDir(d)
+	}
+	t.Root = t.moduleRoot()
+	return t, nil
+}
+
+// FromFiles выбирает файлы из списка: по одному пути на строку (например, вывод
+// git diff --name-only) или поток пакетов go list -json. Файлы не из Go пропускаются
+func FromFiles(r io.Reader) (*Target, error) {
+	t := &Target{files: make(map[string]bool)}
+	br := bufio.NewReader(r)
+	if isJSON(br) {
+		decoder := json.NewDecoder(br)
+		for {
+			var pkg struct {
+				Dir                                          string
+				GoFiles, CgoFiles, TestGoFiles, XTestGoFiles []string
+			}
+			if err := decoder.Decode(&pkg); err == io.EOF {
+				break
+			} else if err != nil {
+				return nil, fmt.Errorf("parse go list -json: %w", err)
+			}
+			for _, files := range [][]string{pkg.GoFiles, pkg.CgoFiles, pkg.TestGoFiles, pkg.XTestGoFiles} {
+				for _, file := range files {
+					t.addFile(filepath.Join(pkg.Dir, file))
+				}
+			}
+		}
+	} else {
+		scanner := bufio.NewScanner(br)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if !strings.HasSuffix(line, ".go") {
+				continue
+			}
+			t.addFile(line)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	}
+	t.Root = t.moduleRoot()
+	return t, nil
+}
+
+// Contains сообщает, выбран ли файл
+func (t *Target) Contains(file string) bool {
+	absFile := absPath(file)
+	if t.files[absFile] {
+		return true
+	}
+	for _, d := range t.dirs {
+		if filepath.Dir(absFile) == d.path || d.recursive && within(d.path, absFile) {
+			return true
+		}
+	}
+	return false
+}
+
+// Filter убирает из результатов находки, предупреждения и устаревшие директивы
+// из невыбранных файлов. Устаревшие записи конфигурации и ошибки загрузки остаются
+func (t *Target) Filter(res *results.Result) {
+	findings := res.Findings[:0]
+	for _, f := range res.Findings {
+		if t.Contains(f.Range.Start.File) {
+			findings = append(findings, f)
+		}
+	}
+	res.Findings = findings
+
+	warnings := res.GenericWarnings[:0]
+	for _, w := range res.GenericWarnings {
+		if t.Contains(w.Position.File) {
+			warnings = append(warnings, w)
+		}
+	}
+	res.GenericWarnings = warnings
+
+	stale := res.Stale[:0]
+	for _, s := range res.Stale {
+		if s.Kind != results.StaleDirective || t.Contains(s.Position.File) {
+			stale = append(stale, s)
+		}
+	}
+	res.Stale = stale
+}
+
+// addFile добавляет файл по пути относительно текущей директории
+func (t *Target) addFile(file string) {
+	file = absPath(file)
+	t.files[file] = true
+	if t.first == "" {
+		t.first = filepath.Dir(file)
+	}
+}
+
+// addDir добавляет директорию
+func (t *Target) addDir(d dir) {
+	t.dirs = append(t.dirs, d)
+	if t.first == "" {
+		t.first = d.path
+	}
+}
+
+// moduleRoot возвращает корень модуля первой выбранной директории или файла в порядке
+// аргументов; если ничего не выбрано - модуля текущей директории
+func (t *Target) moduleRoot() string {
+	if t.first != "" {
+		return config.ModuleRoot(t.first)
+	}
+	return config.ModuleRoot(".")
+}
+
+// resolve превращает паттерн пакета в директорию: путь файловой системы
+// или путь импорта внутри модуля с корнем modRoot
+func resolve(pattern, modRoot string) (dir, error) {
+	base, recursive := strings.CutSuffix(pattern, "/...")
+	if pattern == "..." {
+		base, recursive = ".", true
+	}
+	if strings.Contains(base, "...") {
+		return dir{}, fmt.Errorf("unsupported package pattern %q: \"...\" is allowed only at the end", pattern)
+	}
+
+	path := base
+	if !isLocal(base) {
+		data, err := os.ReadFile(filepath.Join(modRoot, "go.mod"))
+		if err != nil {
+			return dir{}, fmt.Errorf("package pattern %q: %w", pattern, err)
+		}
+		modPath := modfile.ModulePath(data)
+		rel, ok := strings.CutPrefix(base, modPath)
+		if !ok || rel != "" && !strings.HasPrefix(rel, "/") {
+			return dir{}, fmt.Errorf("package pattern %q is outside module %s", pattern, modPath)
+		}
+		path = filepath.Join(modRoot, filepath.FromSlash(rel))
+	}
+
+	if !isDir(path) {
+		return dir{}, fmt.Errorf("package pattern %q: directory %s not found", pattern, path)
+	}
+	return dir{absPath(path), recursive}, nil
+}
+
+// absPath возвращает абсолютный путь; ошибка возможна только без текущей директории
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// isLocal сообщает, что паттерн - путь файловой системы, а не путь импорта
+func isLocal(pattern string) bool {
+	return pattern == "." || pattern == ".." || filepath.IsAbs(pattern) ||
+		strings.HasPrefix(pattern, "./") || strings.HasPrefix(pattern, "../")
+}
+
+// isJSON сообщает, начинается ли поток с объекта JSON
+func isJSON(br *bufio.Reader) bool {
+	for i := 1; ; i++ {
+		peek, err := br.Peek(i)
+		if err != nil {
+			return false
+		}
+		if c := peek[i-1]; c != ' ' && c != '\t' && c != '\r' && c != '\n' {
+			return c == '{'
+		}
+	}
+}
+
+// isDir сообщает, что путь - существующая директория
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// within сообщает, находится ли путь внутри директории
+func within(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,144 @@
+package target
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/comerc/unused-interface-methods/pkg/results"
+)
+
+// module создает модуль example.com/sel и делает его текущей директорией
+func module(t *testing.T) string {
+	root := t.TempDir()
+	for _, dir := range []string{"store", "cmd/api", "cmd/worker/jobs"} {
+		assert.NoError(t, os.MkdirAll(filepath.Join(root, filepath.FromSlash(dir)), 0o755))
+	}
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/sel\n"), 0o644))
+	t.Chdir(root)
+	return root
+}
+
+func TestFromArgs(t *testing.T) {
+	root := module(t)
+	file := func(rel string) string { return filepath.Join(root, filepath.FromSlash(rel)) }
+
+	tests := []struct {
+		name     string
+		args     []string
+		selected []string
+		skipped  []string
+	}{
+		{
+			name:     "single directory selects its tree",
+			args:     []string{"cmd"},
+			selected: []string{"cmd/api/main.go", "cmd/worker/jobs/jobs.go"},
+			skipped:  []string{"store/store.go"},
+		},
+		{
+			name:     "package patterns",
+			args:     []string{"./cmd/worker/...", "./store"},
+			selected: []string{"store/store.go", "cmd/worker/main.go", "cmd/worker/jobs/jobs.go"},
+			skipped:  []string{"cmd/api/main.go"},
+		},
+		{
+			name:     "import paths and files",
+			args:     []string{"example.com/sel/cmd/api", "store/store.go"},
+			selected: []string{"cmd/api/main.go", "store/store.go"},
+			skipped:  []string{"store/other.go", "cmd/api/v2/main.go"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tgt, err := FromArgs(tt.args)
+			assert.NoError(t, err)
+			assert.Equal(t, root, tgt.Root) // использования ищутся во всем модуле
+			for _, rel := range tt.selected {
+				assert.True(t, tgt.Contains(file(rel)), rel)
+			}
+			for _, rel := range tt.skipped {
+				assert.False(t, tgt.Contains(file(rel)), rel)
+			}
+		})
+	}
+
+	_, err := FromArgs([]string{"example.com/other/..."})
+	assert.EqualError(t, err, `package pattern "example.com/other/..." is outside module example.com/sel`)
+	_, err = FromArgs([]string{"./cmd/.../jobs"})
+	assert.Error(t, err)
+	_, err = FromArgs([]string{"./missing", "./store"})
+	assert.Error(t, err)
+
+	// Пути импорта и корень берутся из модуля первого аргумента-пути, а не текущей директории
+	nested := file("tools")
+	for _, dir := range []string{"lint", "gen"} {
+		assert.NoError(t, os.MkdirAll(filepath.Join(nested, dir), 0o755))
+	}
+	assert.NoError(t, os.WriteFile(filepath.Join(nested, "go.mod"), []byte("module example.com/tools\n"), 0o644))
+	tgt, err := FromArgs([]string{"./tools/lint", "example.com/tools/gen"})
+	assert.NoError(t, err)
+	assert.Equal(t, nested, tgt.Root)
+	assert.True(t, tgt.Contains(filepath.Join(nested, "gen", "gen.go")))
+
+	// Корень выбирается по первому файлу в порядке аргументов
+	tgt, err = FromArgs([]string{"tools/gen/gen.go", "store/store.go"})
+	assert.NoError(t, err)
+	assert.Equal(t, nested, tgt.Root)
+}
+
+func TestFromFiles(t *testing.T) {
+	root := module(t)
+
+	// Вывод git diff --name-only: файлы не из Go пропускаются
+	tgt, err := FromFiles(strings.NewReader("store/store.go\nREADME.md\n\ncmd/api/main.go\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, root, tgt.Root)
+	assert.True(t, tgt.Contains(filepath.Join(root, "store", "store.go")))
+	assert.False(t, tgt.Contains(filepath.Join(root, "README.md")))
+	assert.False(t, tgt.Contains(filepath.Join(root, "cmd", "worker", "main.go")))
+
+	// Поток go list -json
+	tgt, err = FromFiles(strings.NewReader(`
+{"Dir": "` + filepath.ToSlash(filepath.Join(root, "store")) + `", "GoFiles": ["store.go"], "TestGoFiles": ["store_test.go"]}
+{"Dir": "` + filepath.ToSlash(filepath.Join(root, "cmd", "api")) + `", "GoFiles": ["main.go"]}
+`))
+	assert.NoError(t, err)
+	assert.True(t, tgt.Contains(filepath.Join(root, "store", "store_test.go")))
+	assert.True(t, tgt.Contains(filepath.Join(root, "cmd", "api", "main.go")))
+	assert.False(t, tgt.Contains(filepath.Join(root, "store", "other.go")))
+
+	_, err = Select([]string{"./store"}, "-", strings.NewReader(""))
+	assert.Error(t, err)
+}
+
+func TestFilter(t *testing.T) {
+	root := module(t)
+	tgt, err := FromArgs([]string{"./store", "./cmd/api"})
+	assert.NoError(t, err)
+
+	at := func(rel string) results.Position {
+		return results.Position{File: filepath.Join(root, filepath.FromSlash(rel)), Line: 1}
+	}
+	res := &results.Result{
+		Findings: []results.Finding{
+			{Interface: "Store", Method: "Put", Range: results.Range{Start: at("store/store.go")}},
+			{Interface: "Job", Method: "Run", Range: results.Range{Start: at("cmd/worker/main.go")}},
+		},
+		GenericWarnings: []results.GenericWarning{{Interface: "Pool", Position: at("cmd/worker/pool.go")}},
+		Stale: []results.StaleSuppression{
+			{Kind: results.StaleDirective, Position: at("cmd/worker/main.go")},
+			{Kind: results.StaleIgnoreRule, Position: at(".unused-interface-methods.yml")},
+		},
+	}
+	tgt.Filter(res)
+
+	assert.Len(t, res.Findings, 1)
+	assert.Equal(t, "Store.Put", res.Findings[0].ID())
+	assert.Empty(t, res.GenericWarnings)
+	if assert.Len(t, res.Stale, 1) {
+		assert.Equal(t, results.StaleIgnoreRule, res.Stale[0].Kind)
+	}
+}
@@ -0,0 +1,344 @@
+package watch
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/comerc/unused-interface-methods/pkg/config"
+)
+
+const (
+	// DefaultDebounce - пауза без изменений, после которой пачка изменений считается законченной:
+	// сохранение из редактора или git checkout меняют много файлов подряд
+	DefaultDebounce = 200 * time.Millisecond
+	// DefaultPoll - интервал опроса, если уведомления файловой системы недоступны
+	DefaultPoll = time.Second
+)
+
+// Options - настройки наблюдения
+type Options struct {
+	Poll     time.Duration // интервал опроса дерева; 0 - уведомления файловой системы
+	Debounce time.Duration // 0 - DefaultDebounce
+	Files    []string      // файлы вне дерева, изменения которых тоже важны, например -config
+}
+
+// Watcher сообщает об изменениях Go-файлов, go.mod, go.work и файлов конфигурации
+// в дереве. Изменения приходят пачками: путь входит в пачку один раз
+type Watcher struct {
+	root     string
+	opts     Options
+	files    map[string]bool
+	notifier *fsnotify.Watcher // nil - дерево опрашивается
+
+	changes chan []string
+	errors  chan error
+	stop    chan struct{}
+	wg      sync.WaitGroup
+	once    sync.Once
+}
+
+// New начинает наблюдение за деревом root. Если уведомления файловой системы недоступны
+// (например, исчерпан лимит inotify), дерево опрашивается с интервалом DefaultPoll
+func New(root string, opts Options) (*Watcher, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Debounce == 0 {
+		opts.Debounce = DefaultDebounce
+	}
+	w := &Watcher{
+		root:    root,
+		opts:    opts,
+		files:   make(map[string]bool),
+		changes: make(chan []string),
+		errors:  make(chan error, 1),
+		stop:    make(chan struct{}),
+	}
+	for _, file := range opts.Files {
+		if abs, err := filepath.Abs(file); err == nil {
+			w.files[abs] = true
+		}
+	}
+
+	raw := make(chan string)
+	if opts.Poll == 0 {
+		if err := w.notify(); err != nil {
+			w.opts.Poll = DefaultPoll
+		}
+	}
+	w.wg.Add(2)
+	if w.notifier != nil {
+		go w.notifyLoop(raw)
+	} else {
+		// Начальный снимок снимается до возврата: изменения сразу после New не теряются
+		go w.pollLoop(raw, w.scan())
+	}
+	go w.debounce(raw)
+	return w, nil
+}
+
+// Changes возвращает канал пачек измененных путей; канал закрывается после Close
+func (w *Watcher) Changes() <-chan []string {
+	return w.changes
+}
+
+// Errors возвращает канал ошибок наблюдения; ошибки не останавливают наблюдение
+func (w *Watcher) Errors() <-chan error {
+	return w.errors
+}
+
+// Polling сообщает, что дерево опрашивается, а не наблюдается через уведомления
+func (w *Watcher) Polling() bool {
+	return w.notifier == nil
+}
+
+// Close останавливает наблюдение
+func (w *Watcher) Close() error {
+	var err error
+	w.once.Do(func() {
+		close(w.stop)
+		if w.notifier != nil {
+			err = w.notifier.Close()
+		}
+		w.wg.Wait()
+	})
+	return err
+}
+
+// notify подписывается на уведомления для всех директорий дерева и директорий файлов вне его
+func (w *Watcher) notify() error {
+	n, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	if err := w.addTree(n, w.root, nil); err != nil {
+		n.Close()
+		return err
+	}
+	for file := range w.files {
+		if err := n.Add(filepath.Dir(file)); err != nil {
+			n.Close()
+			return err
+		}
+	}
+	w.notifier = n
+	return nil
+}
+
+// addTree подписывается на директорию dir и ее поддиректории. found получает важные файлы
+// дерева: файлы новой директории могли появиться раньше подписки
+func (w *Watcher) addTree(n *fsnotify.Watcher, dir string, found func(string)) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir {
+				return err
+			}
+			return nil // директория могла исчезнуть во время обхода
+		}
+		if d.IsDir() {
+			if path != w.root && skipDir(d.Name()) {
+				return filepath.SkipDir
+			}
+			return n.Add(path)
+		}
+		if found != nil && w.relevant(path) {
+			found(path)
+		}
+		return nil
+	})
+}
+
+// notifyLoop переводит уведомления файловой системы в измененные пути
+func (w *Watcher) notifyLoop(raw chan<- string) {
+	defer w.wg.Done()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case event, ok := <-w.notifier.Events:
+			if !ok {
+				return
+			}
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					if !skipDir(info.Name()) && w.within(event.Name) {
+						var found []string
+						err := w.addTree(w.notifier, event.Name, func(path string) { found = append(found, path) })
+						if err != nil {
+							w.report(err)
+						}
+						for _, path := range found {
+							if !w.send(raw, path) {
+								return
+							}
+						}
+					}
+					continue
+				}
+			}
+			if event.Op == fsnotify.Chmod || !w.relevant(event.Name) {
+				continue
+			}
+			if !w.send(raw, event.Name) {
+				return
+			}
+		case err, ok := <-w.notifier.Errors:
+			if !ok {
+				return
+			}
+			w.report(err)
+		}
+	}
+}
+
+// fileState - признаки изменения файла при опросе
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+// scan снимает состояние важных файлов дерева и файлов вне его
+func (w *Watcher) scan() map[string]fileState {
+	states := make(map[string]fileState)
+	add := func(path string) {
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			states[path] = fileState{info.Size(), info.ModTime()}
+		}
+	}
+	filepath.WalkDir(w.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if path != w.root && skipDir(d.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if w.relevant(path) {
+			add(path)
+		}
+		return nil
+	})
+	for file := range w.files {
+		add(file)
+	}
+	return states
+}
+
+// pollLoop периодически сравнивает состояние файлов с предыдущим снимком
+func (w *Watcher) pollLoop(raw chan<- string, prev map[string]fileState) {
+	defer w.wg.Done()
+	ticker := time.NewTicker(w.opts.Poll)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+		}
+		next := w.scan()
+		for path, state := range next {
+			if old, ok := prev[path]; !ok || old.size != state.size || !old.modTime.Equal(state.modTime) {
+				if !w.send(raw, path) {
+					return
+				}
+			}
+		}
+		for path := range prev {
+			if _, ok := next[path]; !ok {
+				if !w.send(raw, path) {
+					return
+				}
+			}
+		}
+		prev = next
+	}
+}
+
+// debounce собирает пути в пачку, пока они продолжают приходить. Пути, пришедшие, пока
+// пачку никто не забрал, добавляются к ней
+func (w *Watcher) debounce(raw <-chan string) {
+	defer w.wg.Done()
+	defer close(w.changes)
+	pending := make(map[string]bool)
+	var (
+		batch []string
+		fire  <-chan time.Time
+		out   chan<- []string
+	)
+	for {
+		select {
+		case <-w.stop:
+			return
+		case path := <-raw:
+			pending[path] = true
+			fire = time.After(w.opts.Debounce)
+			out = nil
+		case <-fire:
+			fire = nil
+			batch = make([]string, 0, len(pending))
+			for path := range pending {
+				batch = append(batch, path)
+			}
+			sort.Strings(batch)
+			out = w.changes
+		case out <- batch:
+			pending = make(map[string]bool)
+			batch, out = nil, nil
+		}
+	}
+}
+
+// send передает путь в пачку; false - наблюдение остановлено
+func (w *Watcher) send(raw chan<- string, path string) bool {
+	select {
+	case raw <- path:
+		return true
+	case <-w.stop:
+		return false
+	}
+}
+
+// report передает ошибку, если предыдущую уже забрали: ошибки не должны блокировать наблюдение
+func (w *Watcher) report(err error) {
+	select {
+	case w.errors <- err:
+	default:
+	}
+}
+
+// relevant сообщает, влияет ли файл на анализ
+func (w *Watcher) relevant(path string) bool {
+	if w.files[path] {
+		return true
+	}
+	if !w.within(path) {
+		return false
+	}
+	name := filepath.Base(path)
+	return strings.HasSuffix(name, ".go") || name == "go.mod" || name == "go.work" || config.IsConfigFile(path)
+}
+
+// within сообщает, что путь находится внутри дерева
+func (w *Watcher) within(path string) bool {
+	rel, err := filepath.Rel(w.root, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// skipDir сообщает, что директорию не нужно просматривать: go tool так же пропускает
+// скрытые директории, testdata и vendor. .config просматривается ради файлов конфигурации
+func skipDir(name string) bool {
+	if name == ".config" {
+		return false
+	}
+	return strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || name == "testdata" || name == "vendor"
+}
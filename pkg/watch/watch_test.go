@@ -0,0 +1,71 @@
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// next ждет пачку изменений
+func next(t *testing.T, w *Watcher) []string {
+	t.Helper()
+	select {
+	case batch := <-w.Changes():
+		return batch
+	case <-time.After(5 * time.Second):
+		t.Fatal("нет изменений")
+		return nil
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	for name, opts := range map[string]Options{
+		"notify": {Debounce: 50 * time.Millisecond},
+		"poll":   {Poll: 20 * time.Millisecond, Debounce: 50 * time.Millisecond},
+	} {
+		t.Run(name, func(t *testing.T) {
+			root := t.TempDir()
+			write := func(name, content string) string {
+				path := filepath.Join(root, filepath.FromSlash(name))
+				assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+				assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+				return path
+			}
+			write("go.mod", "module example.com/watched\n")
+			write(".git/HEAD", "ref: refs/heads/main\n")
+
+			external := filepath.Join(t.TempDir(), "lint.yml")
+			assert.NoError(t, os.WriteFile(external, []byte("ignore: []\n"), 0644))
+
+			opts.Files = []string{external}
+			w, err := New(root, opts)
+			assert.NoError(t, err)
+			defer w.Close()
+			assert.Equal(t, name == "poll", w.Polling())
+
+			// Файлы, не влияющие на анализ, и скрытые директории не учитываются;
+			// несколько изменений подряд приходят одной пачкой
+			write("README.md", "# watched\n")
+			write(".git/index", "changed")
+			a := write("a.go", "package watched\n")
+			cfg := write(".unused-interface-methods.yml", "ignore: []\n")
+			assert.Equal(t, []string{cfg, a}, next(t, w))
+
+			// Файлы новой директории и файлы вне дерева
+			sub := write("sub/b.go", "package sub\n")
+			assert.Equal(t, []string{sub}, next(t, w))
+			assert.NoError(t, os.WriteFile(external, []byte("ignore: [\"x\"]\n"), 0644))
+			assert.Equal(t, []string{external}, next(t, w))
+
+			assert.NoError(t, os.Remove(a))
+			assert.Equal(t, []string{a}, next(t, w))
+
+			assert.NoError(t, w.Close())
+			_, ok := <-w.Changes()
+			assert.False(t, ok, "после Close канал изменений закрывается")
+		})
+	}
+}
@@ -0,0 +1,202 @@
+package workspace
+
+import (
+	"bytes"
+	"fmt"
+	"go/version"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+)
+
+// Режимы поиска модулей (флаг -modules)
+const (
+	ModeWork = "work" // модули из go.work, если он есть; иначе один модуль
+	ModeAuto = "auto" // модули из go.work, а без него - все go.mod внутри директории анализа
+	ModeOff  = "off"  // только модуль директории анализа, go.work не используется
+)
+
+// Modes - допустимые значения флага -modules
+var Modes = []string{ModeWork, ModeAuto, ModeOff}
+
+// Workspace описывает модули, которые загружаются вместе, чтобы вызовы между ними
+// учитывались как использования
+type Workspace struct {
+	Root    string   // директория go.work или директория анализа
+	GoWork  string   // значение GOWORK для go list; пустое значение - по умолчанию
+	Modules []string // абсолютные пути корней модулей; пусто - один модуль
+	temp    string   // временная директория со сгенерированным go.work
+}
+
+// Find ищет модули для анализа директории dir. Без go.work и в режиме off
+// возвращает пустой Workspace: загружается только модуль директории
+func Find(dir, mode string) (*Workspace, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	switch mode {
+	case ModeOff:
+		return &Workspace{Root: absDir, GoWork: "off"}, nil
+	case ModeWork, ModeAuto:
+	default:
+		return nil, fmt.Errorf("unknown modules mode %q (supported: %s)", mode, strings.Join(Modes, ", "))
+	}
+
+	goWork, err := findGoWork(absDir)
+	if err != nil {
+		return nil, err
+	}
+	if goWork != "" {
+		return fromGoWork(goWork)
+	}
+	if mode == ModeAuto {
+		return discover(absDir)
+	}
+	return &Workspace{Root: absDir}, nil
+}
+
+// Close удаляет сгенерированный go.work
+func (w *Workspace) Close() error {
+	if w.temp == "" {
+		return nil
+	}
+	return os.RemoveAll(w.temp)
+}
+
+// findGoWork спрашивает у go, какой go.work действует в директории: так учитываются
+// и поиск вверх по дереву, и переменная GOWORK
+func findGoWork(dir string) (string, error) {
+	cmd := exec.Command("go", "env", "GOWORK")
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("go env GOWORK: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	goWork := strings.TrimSpace(string(out))
+	if goWork == "off" {
+		return "", nil
+	}
+	return goWork, nil
+}
+
+// fromGoWork читает модули из директив use файла go.work
+func fromGoWork(goWork string) (*Workspace, error) {
+	data, err := os.ReadFile(goWork)
+	if err != nil {
+		return nil, err
+	}
+	wf, err := modfile.ParseWork(goWork, data, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	root := filepath.Dir(goWork)
+	w := &Workspace{Root: root, GoWork: goWork}
+	for _, use := range wf.Use {
+		dir := filepath.FromSlash(use.Path)
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(root, dir)
+		}
+		w.Modules = append(w.Modules, filepath.Clean(dir))
+	}
+	return w, nil
+}
+
+// discover ищет все go.mod внутри dir и в ее родителях до ближайшего модуля
+// и объединяет их во временный go.work
+func discover(dir string) (*Workspace, error) {
+	var modules []string
+	if parent := enclosingModule(dir); parent != "" && parent != dir {
+		modules = append(modules, parent)
+	}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && path != dir && skipDir(d.Name()) {
+			return filepath.SkipDir
+		}
+		if !d.IsDir() && d.Name() == "go.mod" {
+			modules = append(modules, filepath.Dir(path))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(modules) < 2 {
+		return &Workspace{Root: dir}, nil
+	}
+
+	goWork, temp, err := writeGoWork(modules)
+	if err != nil {
+		return nil, err
+	}
+	return &Workspace{Root: dir, GoWork: goWork, Modules: modules, temp: temp}, nil
+}
+
+// enclosingModule возвращает корень модуля, которому принадлежит dir, или пустую строку
+func enclosingModule(dir string) string {
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(filepath.Join(d, "go.mod")); err == nil {
+			return d
+		}
+		if filepath.Dir(d) == d {
+			return ""
+		}
+	}
+}
+
+// skipDir сообщает, что директорию не нужно просматривать: go tool так же
+// пропускает скрытые директории, testdata и vendor
+func skipDir(name string) bool {
+	return strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || name == "testdata" || name == "vendor"
+}
+
+// writeGoWork записывает go.work с модулями во временную директорию. Версия go
+// берется наибольшая среди модулей, иначе go отказался бы загружать новые модули
+func writeGoWork(modules []string) (goWork, temp string, err error) {
+	goVersion := "1.18" // первая версия с go.work
+	for _, dir := range modules {
+		data, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+		if err != nil {
+			return "", "", err
+		}
+		mf, err := modfile.ParseLax(filepath.Join(dir, "go.mod"), data, nil)
+		if err != nil {
+			return "", "", err
+		}
+		if mf.Go != nil && version.Compare("go"+mf.Go.Version, "go"+goVersion) > 0 {
+			goVersion = mf.Go.Version
+		}
+	}
+
+	wf := &modfile.WorkFile{Syntax: &modfile.FileSyntax{}}
+	if err := wf.AddGoStmt(goVersion); err != nil {
+		return "", "", err
+	}
+	for _, dir := range modules {
+		if err := wf.AddUse(filepath.ToSlash(dir), ""); err != nil {
+			return "", "", err
+		}
+	}
+
+	temp, err = os.MkdirTemp("", "unused-interface-methods-")
+	if err != nil {
+		return "", "", err
+	}
+	goWork = filepath.Join(temp, "go.work")
+	if err := os.WriteFile(goWork, modfile.Format(wf.Syntax), 0644); err != nil {
+		os.RemoveAll(temp)
+		return "", "", err
+	}
+	return goWork, temp, nil
+}
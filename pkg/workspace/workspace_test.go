@@ -0,0 +1,70 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeFiles создает файлы с содержимым в директории root
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+}
+
+func TestFind(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"api/go.mod":              "module example.com/api\n\ngo 1.21\n",
+		"app/go.mod":              "module example.com/app\n\ngo 1.23.1\n",
+		"app/testdata/mod/go.mod": "module example.com/fixture\n",
+		".tools/go.mod":           "module example.com/tools\n",
+	})
+
+	t.Run("auto", func(t *testing.T) {
+		w, err := Find(root, ModeAuto)
+		assert.NoError(t, err)
+		defer w.Close()
+
+		// testdata и скрытые директории не просматриваются, как у go tool
+		assert.Equal(t, []string{filepath.Join(root, "api"), filepath.Join(root, "app")}, w.Modules)
+		data, err := os.ReadFile(w.GoWork)
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), "go 1.23.1")
+
+		assert.NoError(t, w.Close())
+		assert.NoFileExists(t, w.GoWork)
+	})
+
+	t.Run("work without go.work", func(t *testing.T) {
+		w, err := Find(root, ModeWork)
+		assert.NoError(t, err)
+		assert.Empty(t, w.Modules)
+		assert.Equal(t, root, w.Root)
+	})
+
+	t.Run("go.work", func(t *testing.T) {
+		writeFiles(t, root, map[string]string{"go.work": "go 1.23.1\n\nuse (\n\t./api\n\t./app\n)\n"})
+		defer os.Remove(filepath.Join(root, "go.work"))
+
+		w, err := Find(filepath.Join(root, "api"), ModeWork)
+		assert.NoError(t, err)
+		assert.Equal(t, root, w.Root)
+		assert.Equal(t, filepath.Join(root, "go.work"), w.GoWork)
+		assert.Equal(t, []string{filepath.Join(root, "api"), filepath.Join(root, "app")}, w.Modules)
+
+		w, err = Find(root, ModeOff)
+		assert.NoError(t, err)
+		assert.Equal(t, "off", w.GoWork)
+		assert.Empty(t, w.Modules)
+	})
+
+	_, err := Find(root, "all")
+	assert.EqualError(t, err, `unknown modules mode "all" (supported: work, auto, off)`)
+}
@@ -3,13 +3,14 @@ package unused
 import (
 	"bytes"
 	"fmt"
-	"io"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/comerc/unused-interface-methods/pkg/config"
+	"github.com/comerc/unused-interface-methods/pkg/report"
+	"github.com/comerc/unused-interface-methods/pkg/results"
 	"github.com/comerc/unused-interface-methods/pkg/stage0"
 	"github.com/comerc/unused-interface-methods/pkg/stage1"
 	"github.com/comerc/unused-interface-methods/pkg/stage2"
@@ -25,11 +26,6 @@ func TestMain(m *testing.M) {
 	}
 	cfg.Ignore = nil
 
-	// Перехватываем stdout для сбора вывода
-	oldStdout := os.Stdout
-	r, w, _ := os.Pipe()
-	os.Stdout = w
-
 	// Получаем путь к тестовым данным
 	wd, err := os.Getwd()
 	if err != nil {
@@ -43,21 +39,23 @@ func TestMain(m *testing.M) {
 		panic(fmt.Sprintf("Error loading project: %v\n", err))
 	}
 
-	usedMethodsByPkg, err := stage1.FindUsedMethods(pkgs, cfg, true)
+	usedMethods, err := stage1.FindUsedMethods(pkgs, cfg, true)
 	if err != nil {
 		panic(fmt.Sprintf("Error finding used methods: %v\n", err))
 	}
 
-	err = stage2.FindUnusedMethods(pkgs, usedMethodsByPkg, cfg, true)
+	unusedMethods, err := stage2.FindUnusedMethods(pkgs, usedMethods, cfg, true)
 	if err != nil {
 		panic(fmt.Sprintf("Error finding unused methods: %v\n", err))
 	}
 
-	// Закрываем pipe и читаем вывод
-	w.Close()
+	// Выводим результаты так же, как main.go
+	res := &results.Result{Findings: append(usedMethods, unusedMethods...)}
+	res.Sort()
 	var out bytes.Buffer
-	io.Copy(&out, r)
-	os.Stdout = oldStdout
+	if err := (report.Text{Verbose: true}).Report(&out, res); err != nil {
+		panic(fmt.Sprintf("Error writing report: %v\n", err))
+	}
 
 	// Фильтруем отладочные сообщения
 	lines := strings.Split(out.String(), "\n")